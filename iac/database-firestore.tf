@@ -1,16 +1,19 @@
-# Firestore Database: API Keys storage for MCP server authentication
-# This file contains the Firestore database configuration for API key management
+# Firestore Database: OAuth state of the MCP server
+# This file contains the Firestore database keeping the MCP server OAuth
+# clients, authorization codes and tokens (OAUTH_STORE=firestore), shared by
+# all Cloud Run instances and kept across deployments
 #
 # Resources:
 # - Firestore database in Native mode
-# - Composite indexes for efficient queries
+# - TTL policies expiring the OAuth entries
 #
 # Collection structure (documented, not created by Terraform):
-# api_keys/{api_key_uuid}
-#   ├── refresh_token: string     # OAuth refresh token for Google API access
-#   ├── user_email: string        # Email from OAuth flow
-#   ├── created_at: string        # ISO 8601 timestamp
-#   └── description: string       # Optional key description
+# oauth_<kind>/{sha256 of the key}   kind: clients, states, codes, grants,
+#   │                                      access_tokens, refresh_tokens
+#   ├── value: string            # JSON entry (issued tokens are only stored as
+#   │                            # hashes, Google tokens encrypted with the
+#   │                            # oauth-store-key secret, see secrets.tf)
+#   └── expires_at: timestamp    # TTL field
 
 # ============================================
 # LOCALS
@@ -21,6 +24,16 @@ locals {
   firestore_config   = lookup(local.gcp_resources, "firestore", {})
   firestore_database = lookup(local.firestore_config, "database_id", "(default)")
   firestore_location = lookup(local.firestore_config, "location_id", "eur3")
+
+  # Collections of the MCP server OAuth store
+  oauth_collections = [
+    "oauth_clients",
+    "oauth_states",
+    "oauth_codes",
+    "oauth_grants",
+    "oauth_access_tokens",
+    "oauth_refresh_tokens",
+  ]
 }
 
 # ============================================
@@ -51,32 +64,24 @@ resource "google_firestore_database" "main" {
 }
 
 # ============================================
-# FIRESTORE INDEXES
+# TTL POLICIES
 # ============================================
 
-# Note: Single-field indexes (like created_at DESC) are automatically created by Firestore.
-# Only composite indexes need to be defined explicitly.
+# Firestore deletes the expired entries (within about a day: the server also
+# checks expires_at on read). The single-field index on expires_at is not
+# needed, entries are only read by key.
+
+resource "google_firestore_field" "oauth_ttl" {
+  for_each = toset(local.oauth_collections)
 
-# Index for finding keys by user email (for listing user's keys)
-resource "google_firestore_index" "api_keys_user_email" {
   project    = local.project_id
   database   = google_firestore_database.main.name
-  collection = "api_keys"
-
-  fields {
-    field_path = "user_email"
-    order      = "ASCENDING"
-  }
-
-  fields {
-    field_path = "created_at"
-    order      = "DESCENDING"
-  }
-
-  fields {
-    field_path = "__name__"
-    order      = "DESCENDING"
-  }
+  collection = each.value
+  field      = "expires_at"
+
+  ttl_config {}
+
+  index_config {}
 
   depends_on = [google_firestore_database.main]
 }
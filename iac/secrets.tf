@@ -3,6 +3,8 @@
 #
 # Resources:
 # - Secret Manager secret for OAuth credentials
+# - Secret Manager secret for the OAuth store key (encrypts the Google tokens
+#   kept in Firestore)
 #
 # Note: The secret version (actual credentials) should be created manually
 # using gcloud to avoid storing sensitive data in Terraform state.
@@ -15,6 +17,7 @@ locals {
   # Secrets configuration from config.yaml
   secrets_config         = lookup(local.config, "secrets", {})
   oauth_credentials_name = lookup(local.secrets_config, "oauth_credentials", "oauth-credentials")
+  oauth_store_key_name   = lookup(local.secrets_config, "oauth_store_key", "oauth-store-key")
 }
 
 # ============================================
@@ -36,6 +39,21 @@ resource "google_secret_manager_secret" "oauth_credentials" {
   }
 }
 
+# Secret to hold the OAuth store key (32 random bytes, base64 encoded)
+resource "google_secret_manager_secret" "oauth_store_key" {
+  secret_id = local.oauth_store_key_name
+
+  replication {
+    auto {}
+  }
+
+  labels = {
+    environment = local.env
+    managed_by  = "terraform"
+    purpose     = "oauth-store-key"
+  }
+}
+
 # ============================================
 # OUTPUTS
 # ============================================
@@ -69,6 +87,13 @@ output "oauth_secret_id" {
 #     gcloud secrets versions add scm-pwd-oauth-creds --data-file=- \
 #     --project=scmgcontacts-mcp-prd
 #
+# The OAuth store key is generated once, and never changed: a new key makes
+# every MCP client authorize again:
+#
+#   head -c 32 /dev/urandom | base64 | \
+#     gcloud secrets versions add scm-pwd-oauth-store-key --data-file=- \
+#     --project=scmgcontacts-mcp-prd
+#
 # To verify the secret version:
 #
 #   gcloud secrets versions list scm-pwd-oauth-creds \
@@ -82,6 +82,18 @@ resource "google_cloud_run_v2_service" "mcp" {
       max_instance_count = local.mcp_max_instances
     }
 
+    # OAuth store key, read from OAUTH_KEY_FILE
+    volumes {
+      name = "oauth-store-key"
+      secret {
+        secret = google_secret_manager_secret.oauth_store_key.secret_id
+        items {
+          version = "latest"
+          path    = "key"
+        }
+      }
+    }
+
     containers {
       image = local.mcp_image
 
@@ -125,6 +137,28 @@ resource "google_cloud_run_v2_service" "mcp" {
         value = local.project_id
       }
 
+      # OAuth clients and tokens survive restarts and are shared by instances
+      env {
+        name  = "OAUTH_STORE"
+        value = "firestore"
+      }
+
+      env {
+        name  = "FIRESTORE_DATABASE"
+        value = google_firestore_database.main.name
+      }
+
+      # Google tokens are encrypted in Firestore with this key
+      env {
+        name  = "OAUTH_KEY_FILE"
+        value = "/secrets/oauth-store-key/key"
+      }
+
+      volume_mounts {
+        name       = "oauth-store-key"
+        mount_path = "/secrets/oauth-store-key"
+      }
+
       env {
         name  = "DEPLOY_TIMESTAMP"
         value = "2026-01-15T15:45:00Z"
@@ -141,6 +175,7 @@ resource "google_cloud_run_v2_service" "mcp" {
   depends_on = [
     google_artifact_registry_repository.mcp,
     docker_registry_image.mcp,
+    google_firestore_field.oauth_ttl,
   ]
 }
 
@@ -148,8 +183,12 @@ resource "google_cloud_run_v2_service" "mcp" {
 # SERVICE ACCOUNT PERMISSIONS
 # ============================================
 
-# Note: Firestore permission removed - OAuth2 flow no longer needs Firestore.
-# Clients register dynamically and tokens are managed in-memory.
+# Grant Firestore access to Cloud Run service account (OAuth store)
+resource "google_project_iam_member" "mcp_firestore" {
+  project = local.project_id
+  role    = "roles/datastore.user"
+  member  = "serviceAccount:${local.mcp_service_account}"
+}
 
 # Grant Secret Manager access to Cloud Run service account
 resource "google_project_iam_member" "mcp_secretmanager" {
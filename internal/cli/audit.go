@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"google-contacts/internal/contacts"
+	mcpserver "google-contacts/internal/mcp"
+)
+
+// Audit command flags
+var (
+	auditRules   []string
+	auditJSON    bool
+	auditGroupBy string
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Report contact data-quality problems",
+	Long: `Scan all contacts and report data-quality problems, each with a suggested fix.
+
+Rules:
+  phone-not-e164       Phone number not in international E.164 format
+  email-malformed      Email address that is not local@domain.tld
+  duplicate-email      Email address listed twice on a contact (Gmail dots, +alias)
+  address-incomplete   Address without a recognizable city or postal code
+  missing-name         Contact with data but no first or last name
+  empty-contact        Contact with no name and no data at all
+  shared-phone         Phone number used by several contacts
+  suspicious-birthday  Impossible, future, placeholder or implausibly old birthday
+
+The audit is read-only: no contact is modified.`,
+	Example: `  # Run all rules, grouped by rule
+  google-contacts audit
+
+  # Only phone and email checks, grouped by contact
+  google-contacts audit --rule phone-not-e164 --rule email-malformed --group-by contact
+
+  # Export findings as JSON
+  google-contacts audit --json > audit.json`,
+	RunE: runAudit,
+}
+
+func runAudit(cmd *cobra.Command, args []string) error {
+	if auditGroupBy != "rule" && auditGroupBy != "contact" {
+		return fmt.Errorf("invalid --group-by '%s', valid values: rule, contact", auditGroupBy)
+	}
+
+	var rules []contacts.AuditRule
+	for _, name := range auditRules {
+		rule, err := contacts.ParseAuditRule(name)
+		if err != nil {
+			return err
+		}
+		rules = append(rules, rule)
+	}
+
+	ctx := context.Background()
+
+	// Get People API service
+	srv, err := contacts.GetPeopleService(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize service: %w", err)
+	}
+
+	// Fetch all contacts
+	list, err := srv.ListContacts(ctx)
+	if err != nil {
+		return err
+	}
+
+	findings := contacts.AuditContacts(list, contacts.AuditOptions{Rules: rules})
+
+	format := outputFormat
+	if auditJSON {
+		format = outputJSON
+	}
+
+	output := mcpserver.NewAuditOutput(findings, len(list))
+	return commandOutput{
+		table: func() { displayAuditFindings(findings, len(list)) },
+		data:  output,
+		csv: func() ([]string, [][]string) {
+			header := []string{"rule", "resourceName", "displayName", "field", "value", "message", "suggestion"}
+			var rows [][]string
+			for _, f := range output.Findings {
+				rows = append(rows, []string{f.Rule, f.ResourceName, f.DisplayName, f.Field, f.Value, f.Message, f.Suggestion})
+			}
+			return header, rows
+		},
+	}.writeTo(os.Stdout, format)
+}
+
+// displayAuditFindings prints findings grouped by rule or by contact.
+func displayAuditFindings(findings []contacts.AuditFinding, scanned int) {
+	cyan := color.New(color.FgCyan).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	if len(findings) == 0 {
+		fmt.Printf("%s No problems found in %d contacts\n", green("✓"), scanned)
+		return
+	}
+
+	// Group findings while preserving their order
+	var keys []string
+	groups := make(map[string][]contacts.AuditFinding)
+	for _, f := range findings {
+		key := string(f.Rule)
+		if auditGroupBy == "contact" {
+			key = f.ResourceName
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], f)
+	}
+
+	for _, key := range keys {
+		group := groups[key]
+		title := key
+		if auditGroupBy == "contact" {
+			title = fmt.Sprintf("%s (%s)", displayNameOrPlaceholder(group[0].DisplayName), extractID(key))
+		}
+		fmt.Printf("%s %s\n", yellow(title), fmt.Sprintf("(%d)", len(group)))
+
+		for _, f := range group {
+			if auditGroupBy == "contact" {
+				fmt.Printf("  [%s] %s\n", cyan(f.Rule), f.Message)
+			} else {
+				fmt.Printf("  %s (%s): %s\n", cyan(displayNameOrPlaceholder(f.DisplayName)), extractID(f.ResourceName), f.Message)
+			}
+			fmt.Printf("    → %s\n", f.Suggestion)
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("%d findings in %d contacts scanned\n", len(findings), scanned)
+}
+
+// displayNameOrPlaceholder returns the name, or a placeholder for unnamed contacts.
+func displayNameOrPlaceholder(name string) string {
+	if name == "" {
+		return "(no name)"
+	}
+	return name
+}
@@ -0,0 +1,517 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
+	"golang.org/x/term"
+
+	"google-contacts/internal/contacts"
+	"google-contacts/pkg/auth"
+)
+
+// Auth command flags
+var (
+	authLoginDevice    bool
+	authLoginNoBrowser bool
+	authLoginScopes    string
+	authRevokeForce    bool
+	authEncryptAll     bool
+	authEncryptDefault bool
+)
+
+// envTokenPassphrase holds the passphrase of the encrypted token store.
+const envTokenPassphrase = "GOOGLE_CONTACTS_TOKEN_PASSPHRASE"
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage Google accounts and profiles",
+	Long: `Each profile is a Google account with its own token, contact cache,
+operation journal and trash. The default profile uses the token in the
+credentials directory (~/.credentials/google_token.json); other profiles use
+~/.credentials/profiles/<name>/, with their own google_credentials.json if
+present and the shared one otherwise.
+
+Select a profile with --profile, GOOGLE_CONTACTS_PROFILE or the 'profile' key
+of the configuration file. A new profile is created by authenticating with
+it, e.g. 'google-contacts search --profile work john'.`,
+}
+
+var authLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Authenticate a profile with Google",
+	Long: `Authenticate the current profile (see --profile) and save its token,
+replacing the previous one.
+
+By default the browser is opened on the Google consent page and the answer is
+received on a random local port. On a machine without a browser (SSH):
+
+  --no-browser  Print the consent URL to open on any machine. The code is
+                received locally if the browser runs on this machine;
+                otherwise paste the URL the browser was redirected to.
+  --device      Use the device authorization flow: enter a short code on
+                another device. Requires an OAuth client of type "TVs and
+                Limited Input devices", which Google restricts to a few
+                scopes (contacts are allowed, Gmail is not: use
+                --scopes contacts).
+
+The browser flows use PKCE and a random state.
+
+The requested scopes are selected with --scopes, GOOGLE_CONTACTS_SCOPES or
+the 'scopes' key of the configuration file:
+
+  contacts-readonly  Read contacts only
+  contacts           Read and write contacts
+  unified            Contacts and Gmail (default), so that the token can be
+                     shared with email-manager
+
+Scopes granted earlier are kept: to narrow the access of a profile, run
+'auth revoke' first. A command that needs more than the token was granted
+(e.g. an update with a contacts-readonly token) asks for the missing scopes
+and continues.`,
+	Example: `  google-contacts auth login
+  google-contacts auth login --profile work --no-browser
+  google-contacts auth login --device --scopes contacts
+  google-contacts auth login --scopes contacts-readonly`,
+	Args: cobra.NoArgs,
+	RunE: runAuthLogin,
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the account, scopes and token of the current profile",
+	Long: `Show the authentication state of the current profile (see --profile):
+the account email, the scopes granted to the token, the access token expiry
+and the token file. An expired access token is refreshed (and saved) first,
+so a revoked grant is reported as an error.`,
+	Example: `  google-contacts auth status
+  google-contacts auth status --profile work -o json`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{outputFormatsAnnotation: "json,yaml"},
+	RunE:        runAuthStatus,
+}
+
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Delete the local token of the current profile",
+	Long: `Delete the token file and the contact cache of the current profile.
+The access granted to the application stays valid at Google: use
+'auth revoke' to cancel it.`,
+	Args: cobra.NoArgs,
+	RunE: runAuthLogout,
+}
+
+var authRevokeCmd = &cobra.Command{
+	Use:   "revoke",
+	Short: "Revoke the access granted to the current profile",
+	Long: `Revoke the grant of the current profile at Google, which invalidates its
+refresh and access tokens on every machine, then delete the local token and
+contact cache. The next command asks for consent again.`,
+	Example: `  google-contacts auth revoke
+  google-contacts auth revoke --profile work --force`,
+	Args: cobra.NoArgs,
+	RunE: runAuthRevoke,
+}
+
+var authEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt the plaintext token of the current profile",
+	Long: `Move the plaintext token (google_token.json) of the current profile, or of
+every profile with --all, to an encrypted file (google_token.enc) in the same
+directory, then delete the plaintext file.
+
+Tokens are encrypted with AES-256-GCM, using the key file named by
+tokenKeyFile (GOOGLE_CONTACTS_TOKEN_KEY_FILE), created if missing, or a key
+derived with scrypt from a passphrase (GOOGLE_CONTACTS_TOKEN_PASSPHRASE, or
+asked on the terminal). Then set 'tokenStore: encrypted' in the
+configuration file (or GOOGLE_CONTACTS_TOKEN_STORE=encrypted) so that the
+encrypted tokens are used.
+
+The token of the default profile is shared with email-manager, which only
+reads plaintext tokens: it is skipped unless --include-default is given.`,
+	Example: `  google-contacts auth encrypt --profile work
+  google-contacts auth encrypt --include-default
+  GOOGLE_CONTACTS_TOKEN_KEY_FILE=~/.credentials/token.key google-contacts auth encrypt --all`,
+	Args: cobra.NoArgs,
+	RunE: runAuthEncrypt,
+}
+
+var authProfilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "List the profiles",
+	Example: `  google-contacts auth profiles
+  google-contacts auth profiles -o json`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{outputFormatsAnnotation: "json,yaml,csv"},
+	RunE:        runAuthProfiles,
+}
+
+func runAuthLogin(cmd *cobra.Command, args []string) error {
+	profile := auth.CurrentProfile()
+	config, err := auth.LoadOAuthConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	mode := auth.LoginBrowser
+	switch {
+	case authLoginDevice:
+		mode = auth.LoginDevice
+	case authLoginNoBrowser:
+		mode = auth.LoginNoBrowser
+	}
+
+	ctx := context.Background()
+	token, err := auth.Login(ctx, config, auth.LoginOptions{Mode: mode, In: os.Stdin, Out: os.Stdout})
+	if err != nil {
+		return err
+	}
+	if err := auth.SaveLoginToken(profile, token); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	invalidateContactCache()
+
+	// Show which account the profile now uses
+	green := color.New(color.FgGreen).SprintFunc()
+	account := "unknown account"
+	if srv, err := contacts.GetPeopleService(auth.WithNonInteractive(ctx)); err == nil {
+		if email, err := srv.AccountEmail(ctx); err == nil {
+			account = email
+		}
+	}
+	fmt.Printf("%s Profile '%s' logged in as %s\n", green("✓"), profile, account)
+	return nil
+}
+
+// authStatus is the auth status output.
+type authStatus struct {
+	Profile        string   `json:"profile"`
+	TokenPath      string   `json:"tokenPath,omitempty"`
+	ServiceAccount string   `json:"serviceAccount,omitempty"`
+	Impersonate    string   `json:"impersonate,omitempty"`
+	LoggedIn       bool     `json:"loggedIn"`
+	Account        string   `json:"account,omitempty"`
+	Scopes         []string `json:"scopes,omitempty"`
+	Expiry         string   `json:"expiry,omitempty"`
+	RefreshToken   bool     `json:"refreshToken"`
+	Error          string   `json:"error,omitempty"`
+}
+
+func runAuthStatus(cmd *cobra.Command, args []string) error {
+	profile := auth.CurrentProfile()
+	status := authStatus{Profile: profile, TokenPath: auth.TokenLocation(profile)}
+
+	token, err := auth.LoadToken(profile)
+	switch {
+	case auth.CurrentSubject() != "":
+		if err := inspectImpersonation(context.Background(), &status); err != nil {
+			status.Error = err.Error()
+		}
+	case errors.Is(err, auth.ErrNotLoggedIn):
+	case err != nil:
+		return err
+	default:
+		status.LoggedIn = true
+		status.RefreshToken = token.RefreshToken != ""
+		if err := inspectToken(context.Background(), profile, token, &status); err != nil {
+			status.Error = err.Error()
+		}
+	}
+
+	return commandOutput{
+		table: func() { displayAuthStatus(status) },
+		data:  status,
+	}.write()
+}
+
+// inspectToken refreshes the token if needed and fills the status with the
+// account, scopes and expiry.
+func inspectToken(ctx context.Context, profile string, token *oauth2.Token, status *authStatus) error {
+	config, err := auth.LoadOAuthConfig(profile)
+	if err != nil {
+		return err
+	}
+	fresh, err := config.TokenSource(ctx, token).Token()
+	if err != nil {
+		return fmt.Errorf("token refresh failed (run 'auth login'): %w", err)
+	}
+	if fresh.AccessToken != token.AccessToken {
+		if err := auth.SaveToken(profile, fresh); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: unable to save token: %v\n", err)
+		}
+	}
+	if !fresh.Expiry.IsZero() {
+		status.Expiry = fresh.Expiry.Format(time.RFC3339)
+	}
+
+	info, err := auth.FetchTokenInfo(ctx, http.DefaultClient, auth.GoogleTokenInfoURL, fresh.AccessToken)
+	if err != nil {
+		return err
+	}
+	status.Scopes = info.Scopes
+
+	srv, err := contacts.GetPeopleService(auth.WithNonInteractive(auth.WithProfile(ctx, profile)))
+	if err != nil {
+		return err
+	}
+	status.Account, err = srv.AccountEmail(ctx)
+	return err
+}
+
+// inspectImpersonation fills the status of the service account acting as
+// the impersonated user, checking that the delegation works.
+func inspectImpersonation(ctx context.Context, status *authStatus) error {
+	sa := auth.CurrentServiceAccount()
+	status.TokenPath = ""
+	status.ServiceAccount = sa.Email
+	status.Impersonate = auth.CurrentSubject()
+	status.LoggedIn = true
+	scopes, err := sa.Scopes()
+	if err != nil {
+		return err
+	}
+	status.Scopes = scopes
+
+	srv, err := contacts.GetPeopleService(ctx)
+	if err != nil {
+		return err
+	}
+	if status.Account, err = srv.AccountEmail(ctx); err != nil {
+		return fmt.Errorf("delegation failed (is the client ID %s authorized for these scopes in the admin console?): %w", sa.ClientID, err)
+	}
+	return nil
+}
+
+// displayAuthStatus prints the authentication state of a profile.
+func displayAuthStatus(s authStatus) {
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+
+	fmt.Printf("%s: %s\n", cyan("Profile"), s.Profile)
+	if s.ServiceAccount != "" {
+		fmt.Printf("%s: %s\n", cyan("Service account"), s.ServiceAccount)
+		fmt.Printf("%s: %s\n", cyan("Acting as"), s.Impersonate)
+	} else {
+		fmt.Printf("%s: %s\n", cyan("Token"), s.TokenPath)
+	}
+	if !s.LoggedIn {
+		fmt.Printf("%s: %s (run 'google-contacts auth login')\n", cyan("Status"), yellow("not logged in"))
+		return
+	}
+	if s.Error != "" {
+		fmt.Printf("%s: %s\n", cyan("Status"), red(s.Error))
+	} else {
+		fmt.Printf("%s: %s\n", cyan("Status"), green("logged in"))
+	}
+	if s.Account != "" {
+		fmt.Printf("%s: %s\n", cyan("Account"), s.Account)
+	}
+	if s.Expiry != "" {
+		fmt.Printf("%s: %s\n", cyan("Expires"), s.Expiry)
+	}
+	if !s.RefreshToken && s.ServiceAccount == "" {
+		fmt.Printf("%s: %s\n", cyan("Refresh"), yellow("no refresh token, log in again when the access token expires"))
+	}
+	if len(s.Scopes) > 0 {
+		fmt.Printf("%s:\n", cyan("Scopes"))
+		for _, scope := range s.Scopes {
+			fmt.Printf("  - %s\n", scope)
+		}
+	}
+}
+
+func runAuthLogout(cmd *cobra.Command, args []string) error {
+	profile := auth.CurrentProfile()
+	if err := auth.Logout(profile); err != nil {
+		return err
+	}
+	invalidateContactCache()
+
+	green := color.New(color.FgGreen).SprintFunc()
+	fmt.Printf("%s Profile '%s' logged out\n", green("✓"), profile)
+	return nil
+}
+
+func runAuthRevoke(cmd *cobra.Command, args []string) error {
+	profile := auth.CurrentProfile()
+	token, err := auth.LoadToken(profile)
+	if err != nil {
+		return err
+	}
+	if !authRevokeForce && !confirm(fmt.Sprintf("Revoke the access granted to profile '%s'? (y/N): ", profile), false) {
+		fmt.Println("Revoke cancelled.")
+		return nil
+	}
+
+	if err := auth.RevokeToken(context.Background(), http.DefaultClient, auth.GoogleRevokeURL, token); err != nil {
+		return fmt.Errorf("%w (use 'auth logout' to delete the local token anyway)", err)
+	}
+	if err := auth.Logout(profile); err != nil {
+		return err
+	}
+	invalidateContactCache()
+
+	green := color.New(color.FgGreen).SprintFunc()
+	fmt.Printf("%s Access revoked and profile '%s' logged out\n", green("✓"), profile)
+	return nil
+}
+
+func runAuthEncrypt(cmd *cobra.Command, args []string) error {
+	profiles := []string{auth.CurrentProfile()}
+	if authEncryptAll {
+		var err error
+		if profiles, err = auth.ListProfiles(); err != nil {
+			return err
+		}
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	store := auth.NewPassphraseStore(tokenPassphrase(true))
+	if tokenKeyFile != "" {
+		if _, err := os.Stat(tokenKeyFile); errors.Is(err, os.ErrNotExist) {
+			if err := auth.GenerateKeyFile(tokenKeyFile); err != nil {
+				return err
+			}
+			fmt.Printf("%s Created token key file %s (keep a copy: tokens cannot be read without it)\n", green("✓"), tokenKeyFile)
+		}
+		store = auth.NewKeyFileStore(tokenKeyFile)
+	}
+
+	plain := auth.FileStore{}
+	encrypted := 0
+	for _, name := range profiles {
+		// Deleting the shared plaintext token would log email-manager out
+		if name == auth.DefaultProfile && !authEncryptDefault {
+			fmt.Printf("%s Profile '%s': skipped, its token is shared with email-manager (use --include-default to encrypt it anyway)\n",
+				yellow("!"), name)
+			continue
+		}
+		token, err := plain.Load(name)
+		if errors.Is(err, auth.ErrNotLoggedIn) {
+			fmt.Printf("Profile '%s': no plaintext token\n", name)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := store.Save(name, token); err != nil {
+			return fmt.Errorf("failed to encrypt token of profile %s: %w", name, err)
+		}
+		// The plaintext token is only deleted once the encrypted one reads back
+		saved, err := store.Load(name)
+		if err != nil || saved.RefreshToken != token.RefreshToken {
+			return fmt.Errorf("failed to verify the encrypted token of profile %s: %v", name, err)
+		}
+		if err := plain.Delete(name); err != nil {
+			return err
+		}
+		fmt.Printf("%s Profile '%s': token encrypted to %s\n", green("✓"), name, store.Location(name))
+		if name == auth.DefaultProfile {
+			fmt.Printf("%s email-manager cannot read the encrypted token and must log in again\n", yellow("Warning:"))
+		}
+		encrypted++
+	}
+
+	if _, ok := auth.CurrentTokenStore().(*auth.EncryptedStore); encrypted > 0 && !ok {
+		fmt.Printf("%s set 'tokenStore: encrypted' in %s (or GOOGLE_CONTACTS_TOKEN_STORE=encrypted) to use the encrypted tokens\n",
+			yellow("Next:"), userConfigPath)
+	}
+	return nil
+}
+
+// newTokenStore returns the token store of a kind (auth.Store*).
+func newTokenStore(kind, keyFile string) auth.TokenStore {
+	switch kind {
+	case auth.StoreEncrypted:
+		if keyFile != "" {
+			return auth.NewKeyFileStore(keyFile)
+		}
+		return auth.NewPassphraseStore(tokenPassphrase(false))
+	case auth.StoreEnv:
+		return auth.NewEnvStore()
+	}
+	return auth.FileStore{}
+}
+
+// tokenPassphrase returns the passphrase source of the encrypted token
+// store: GOOGLE_CONTACTS_TOKEN_PASSPHRASE, or a prompt on the terminal,
+// asked twice for a new passphrase.
+func tokenPassphrase(isNew bool) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		if passphrase, ok := os.LookupEnv(envTokenPassphrase); ok {
+			return []byte(passphrase), nil
+		}
+		fd := int(os.Stdin.Fd())
+		if !term.IsTerminal(fd) {
+			return nil, fmt.Errorf("token passphrase required: set %s", envTokenPassphrase)
+		}
+		fmt.Fprint(os.Stderr, "Token passphrase: ")
+		passphrase, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		if isNew {
+			fmt.Fprint(os.Stderr, "Repeat passphrase: ")
+			again, err := term.ReadPassword(fd)
+			fmt.Fprintln(os.Stderr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read passphrase: %w", err)
+			}
+			if string(again) != string(passphrase) {
+				return nil, fmt.Errorf("passphrases do not match")
+			}
+		}
+		return passphrase, nil
+	}
+}
+
+func runAuthProfiles(cmd *cobra.Command, args []string) error {
+	entries, err := listProfiles()
+	if err != nil {
+		return err
+	}
+
+	return commandOutput{
+		table: func() { displayProfiles(entries) },
+		data:  entries,
+		csv: func() ([]string, [][]string) {
+			var rows [][]string
+			for _, e := range entries {
+				rows = append(rows, []string{e.Name, strconv.FormatBool(e.Current), strconv.FormatBool(e.Authenticated), e.TokenPath})
+			}
+			return []string{"name", "current", "authenticated", "tokenPath"}, rows
+		},
+	}.write()
+}
+
+// displayProfiles prints the profiles, marking the current one.
+func displayProfiles(entries []profileEntry) {
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	faint := color.New(color.Faint).SprintFunc()
+
+	for _, e := range entries {
+		marker := " "
+		if e.Current {
+			marker = green("*")
+		}
+		status := green("authenticated")
+		if !e.Authenticated {
+			status = yellow("not authenticated")
+		}
+		fmt.Printf("%s %-15s %s  %s\n", marker, e.Name, status, faint(e.TokenPath))
+	}
+}
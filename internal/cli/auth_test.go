@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/oauth2"
+
+	"google-contacts/pkg/auth"
+)
+
+func TestRunAuthEncrypt(t *testing.T) {
+	dir := t.TempDir()
+	auth.SetCredentialsPath(dir)
+	defer auth.SetCredentialsPath("")
+	tokenKeyFile = filepath.Join(dir, "token.key")
+	defer func() { tokenKeyFile = "" }()
+	authEncryptAll = true
+	defer func() { authEncryptAll = false }()
+
+	token := &oauth2.Token{RefreshToken: "rt"}
+	for _, p := range []string{auth.DefaultProfile, "work"} {
+		if err := (auth.FileStore{}).Save(p, token); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A profile without token is skipped
+	if err := os.MkdirAll(auth.ProfileDir("personal"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runAuthEncrypt(authEncryptCmd, nil); err != nil {
+		t.Fatalf("runAuthEncrypt() error = %v", err)
+	}
+
+	store := auth.NewKeyFileStore(tokenKeyFile)
+	if (auth.FileStore{}).Exists("work") {
+		t.Error("profile work: plaintext token not deleted")
+	}
+	if got, err := store.Load("work"); err != nil || got.RefreshToken != "rt" {
+		t.Errorf("profile work: encrypted token = %+v, %v", got, err)
+	}
+	if store.Exists("personal") {
+		t.Error("profile personal: unexpected encrypted token")
+	}
+
+	// The default profile token, shared with email-manager, is kept
+	if !(auth.FileStore{}).Exists(auth.DefaultProfile) || store.Exists(auth.DefaultProfile) {
+		t.Error("default profile: token encrypted without --include-default")
+	}
+	authEncryptDefault = true
+	defer func() { authEncryptDefault = false }()
+	if err := runAuthEncrypt(authEncryptCmd, nil); err != nil {
+		t.Fatalf("runAuthEncrypt() --include-default error = %v", err)
+	}
+	if (auth.FileStore{}).Exists(auth.DefaultProfile) {
+		t.Error("default profile: plaintext token not deleted with --include-default")
+	}
+	if got, err := store.Load(auth.DefaultProfile); err != nil || got.RefreshToken != "rt" {
+		t.Errorf("default profile: encrypted token = %+v, %v", got, err)
+	}
+}
+
+func TestNewTokenStore(t *testing.T) {
+	if _, ok := newTokenStore(auth.StoreFile, "").(auth.FileStore); !ok {
+		t.Error("file: not a FileStore")
+	}
+	if s, ok := newTokenStore(auth.StoreEncrypted, "/k").(*auth.EncryptedStore); !ok || s.KeyFile() != "/k" {
+		t.Error("encrypted: not a key file EncryptedStore")
+	}
+	if _, ok := newTokenStore(auth.StoreEnv, "").(*auth.EnvStore); !ok {
+		t.Error("env: not an EnvStore")
+	}
+}
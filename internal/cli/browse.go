@@ -0,0 +1,660 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/spf13/cobra"
+
+	"google-contacts/internal/contacts"
+	"google-contacts/internal/journal"
+)
+
+// Browse command flags
+var (
+	browseRefresh bool
+	browseGroup   string
+)
+
+var browseCmd = &cobra.Command{
+	Use:   "browse",
+	Short: "Browse contacts in an interactive terminal UI",
+	Long: `Open a full-screen terminal browser over all contacts.
+
+Typing in the search box filters the list as you type (accent- and
+typo-tolerant, like search --fuzzy). The detail pane shows the selected
+contact. Contacts are read from the local cache (refreshed every 10
+minutes), so scrolling and filtering never call the API.
+
+Keys (contact list):
+  /          Focus the search box (Enter or ↓ returns to the list)
+  e          Edit the selected contact
+  d          Delete the selected contact (asks for confirmation)
+  p / m      Copy the first phone / email to the clipboard
+  [ / ]      Previous / next contact group
+  g          Pick a contact group
+  r          Reload contacts from Google
+  q, Esc     Quit
+
+Clipboard support uses pbcopy (macOS), clip (Windows), or wl-copy,
+xclip or xsel (Linux).`,
+	Example: `  # Browse all contacts
+  google-contacts browse
+
+  # Ignore the local cache
+  google-contacts browse --refresh
+
+  # Start in a contact group
+  google-contacts browse --group Family`,
+	Args: cobra.NoArgs,
+	RunE: runBrowse,
+}
+
+func runBrowse(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	// Get People API service
+	srv, err := contacts.GetPeopleService(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize service: %w", err)
+	}
+
+	cache, err := contactCache()
+	if err != nil {
+		return err
+	}
+	if browseRefresh {
+		if err := cache.Invalidate(); err != nil {
+			return err
+		}
+	}
+
+	list, err := srv.ListContactsCached(ctx, cache)
+	if err != nil {
+		return err
+	}
+
+	// Groups are optional: browsing still works without them
+	groups, err := srv.ListContactGroupsCached(ctx, cache)
+	if err != nil {
+		if browseGroup != "" {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	groups = browsableGroups(groups)
+
+	group := -1
+	if browseGroup != "" {
+		if group = findGroup(groups, browseGroup); group < 0 {
+			return fmt.Errorf("contact group '%s' not found", browseGroup)
+		}
+	}
+
+	b := newBrowser(ctx, srv, cache, list, groups)
+	b.selectGroup(group)
+	return b.app.Run()
+}
+
+// findGroup returns the index of the group with the given name (ignoring
+// case and accents), or -1.
+func findGroup(groups []contacts.ContactGroup, name string) int {
+	for i, g := range groups {
+		if contacts.FoldText(g.Name) == contacts.FoldText(name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// browseKeyHints is the key summary shown in the status bar.
+const browseKeyHints = "/ search  e edit  d delete  p/m copy  [/] group  g groups  r reload  q quit"
+
+// browser is the state of the browse terminal UI.
+type browser struct {
+	ctx   context.Context
+	srv   *contacts.Service
+	cache *contacts.ContactCache
+
+	all     []contacts.ContactDetails
+	groups  []contacts.ContactGroup
+	group   int // Index in groups, -1 for all contacts
+	visible []contacts.ContactDetails
+
+	app    *tview.Application
+	pages  *tview.Pages
+	search *tview.InputField
+	list   *tview.List
+	detail *tview.TextView
+	status *tview.TextView
+}
+
+// newBrowser builds the browse UI over the given contacts.
+func newBrowser(ctx context.Context, srv *contacts.Service, cache *contacts.ContactCache, list []contacts.ContactDetails, groups []contacts.ContactGroup) *browser {
+	b := &browser{
+		ctx:    ctx,
+		srv:    srv,
+		cache:  cache,
+		all:    list,
+		groups: groups,
+		group:  -1,
+		app:    tview.NewApplication(),
+		pages:  tview.NewPages(),
+		search: tview.NewInputField(),
+		list:   tview.NewList(),
+		detail: tview.NewTextView(),
+		status: tview.NewTextView(),
+	}
+
+	b.search.SetLabel("Search: ").
+		SetFieldBackgroundColor(tcell.ColorDefault).
+		SetChangedFunc(func(string) { b.refresh() }).
+		SetDoneFunc(func(key tcell.Key) {
+			if key == tcell.KeyEscape {
+				b.search.SetText("")
+			}
+			b.app.SetFocus(b.list)
+		})
+	b.search.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyDown {
+			b.app.SetFocus(b.list)
+			return nil
+		}
+		return event
+	})
+
+	b.list.ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetChangedFunc(func(index int, _, _ string, _ rune) { b.showDetail(index) })
+	b.list.SetBorder(true).SetTitle(" Contacts ")
+	b.list.SetInputCapture(b.handleListKey)
+
+	b.detail.SetDynamicColors(true).SetWordWrap(true)
+	b.detail.SetBorder(true).SetTitle(" Details ")
+
+	b.status.SetDynamicColors(true)
+
+	body := tview.NewFlex().
+		AddItem(b.list, 0, 2, true).
+		AddItem(b.detail, 0, 3, false)
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(b.search, 1, 0, false).
+		AddItem(body, 0, 1, true).
+		AddItem(b.status, 1, 0, false)
+
+	b.pages.AddPage("main", layout, true, true)
+	b.app.SetRoot(b.pages, true).SetFocus(b.list)
+	b.refresh()
+	return b
+}
+
+// handleListKey implements the contact list keybindings.
+func (b *browser) handleListKey(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() == tcell.KeyEscape {
+		b.app.Stop()
+		return nil
+	}
+	if event.Key() != tcell.KeyRune {
+		return event
+	}
+
+	switch event.Rune() {
+	case 'q':
+		b.app.Stop()
+	case '/':
+		b.app.SetFocus(b.search)
+	case 'e':
+		if c := b.selected(); c != nil {
+			b.showEditForm(c)
+		}
+	case 'd':
+		if c := b.selected(); c != nil {
+			b.confirmDelete(c)
+		}
+	case 'p':
+		if c := b.selected(); c != nil && len(c.Phones) > 0 {
+			b.copy("phone", c.Phones[0].Value)
+		} else {
+			b.setStatus("[yellow]No phone to copy")
+		}
+	case 'm':
+		if c := b.selected(); c != nil && len(c.Emails) > 0 {
+			b.copy("email", c.Emails[0].Value)
+		} else {
+			b.setStatus("[yellow]No email to copy")
+		}
+	case '[':
+		b.selectGroup((b.group+len(b.groups)+1)%(len(b.groups)+1) - 1)
+	case ']':
+		b.selectGroup((b.group+2)%(len(b.groups)+1) - 1)
+	case 'g':
+		b.showGroupPicker()
+	case 'r':
+		b.reload()
+	default:
+		return event
+	}
+	return nil
+}
+
+// refresh recomputes the visible contacts from the search box and group,
+// keeping the current contact selected when it is still visible.
+func (b *browser) refresh() {
+	var current string
+	if c := b.selected(); c != nil {
+		current = c.ResourceName
+	}
+
+	groupName := ""
+	if b.group >= 0 {
+		groupName = b.groups[b.group].ResourceName
+	}
+	b.visible = browseFilter(b.all, b.search.GetText(), groupName)
+
+	b.list.Clear()
+	selected := 0
+	for i, c := range b.visible {
+		b.list.AddItem(tview.Escape(displayNameOrPlaceholder(c.DisplayName)), "", 0, nil)
+		if c.ResourceName == current {
+			selected = i
+		}
+	}
+	if len(b.visible) > 0 {
+		b.list.SetCurrentItem(selected)
+	}
+	b.showDetail(b.list.GetCurrentItem())
+	b.setStatus("")
+}
+
+// selected returns the highlighted contact, or nil when the list is empty.
+func (b *browser) selected() *contacts.ContactDetails {
+	index := b.list.GetCurrentItem()
+	if index < 0 || index >= len(b.visible) {
+		return nil
+	}
+	return &b.visible[index]
+}
+
+// showDetail renders the contact at index in the detail pane.
+func (b *browser) showDetail(index int) {
+	if index < 0 || index >= len(b.visible) {
+		b.detail.SetText("No contact selected")
+		return
+	}
+	c := &b.visible[index]
+
+	var buf bytes.Buffer
+	writeFullContactDetails(&buf, c)
+	if names := b.groupNames(c); len(names) > 0 {
+		fmt.Fprintf(&buf, "\n  Groups: %s\n", strings.Join(names, ", "))
+	}
+	b.detail.SetText(tview.TranslateANSI(tview.Escape(buf.String()))).ScrollToBeginning()
+}
+
+// groupNames returns the names of the listed groups the contact belongs to.
+func (b *browser) groupNames(c *contacts.ContactDetails) []string {
+	var names []string
+	for _, g := range b.groups {
+		if c.InGroup(g.ResourceName) {
+			names = append(names, g.Name)
+		}
+	}
+	return names
+}
+
+// setStatus shows a message followed by the current group and counts.
+func (b *browser) setStatus(message string) {
+	group := "All contacts"
+	if b.group >= 0 {
+		group = b.groups[b.group].Name
+	}
+	text := fmt.Sprintf("[::b]%s[::-] %d/%d  [gray]%s[-]",
+		tview.Escape(group), len(b.visible), len(b.all), tview.Escape(browseKeyHints))
+	if message != "" {
+		text = message + "[-]  " + text
+	}
+	b.status.SetText(text)
+}
+
+// selectGroup switches to the group at index (-1 for all contacts).
+func (b *browser) selectGroup(index int) {
+	b.group = index
+	b.refresh()
+}
+
+// showGroupPicker opens a list of groups to jump to.
+func (b *browser) showGroupPicker() {
+	picker := tview.NewList().ShowSecondaryText(false)
+	picker.SetBorder(true).SetTitle(" Groups ")
+	picker.AddItem("All contacts", "", 0, nil)
+	for _, g := range b.groups {
+		picker.AddItem(tview.Escape(fmt.Sprintf("%s (%d)", g.Name, g.MemberCount)), "", 0, nil)
+	}
+	picker.SetCurrentItem(b.group + 1)
+
+	closePicker := func() {
+		b.pages.RemovePage("groups")
+		b.app.SetFocus(b.list)
+	}
+	picker.SetSelectedFunc(func(index int, _, _ string, _ rune) {
+		closePicker()
+		b.selectGroup(index - 1)
+	})
+	picker.SetDoneFunc(closePicker)
+
+	b.pages.AddPage("groups", centered(picker, 40, len(b.groups)+3), true, true)
+	b.app.SetFocus(picker)
+}
+
+// confirmDelete asks for confirmation, then deletes the contact.
+func (b *browser) confirmDelete(c *contacts.ContactDetails) {
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Delete '%s'?\nIt is kept in 'google-contacts trash'.", displayNameOrPlaceholder(c.DisplayName))).
+		AddButtons([]string{"Cancel", "Delete"})
+	modal.SetDoneFunc(func(_ int, label string) {
+		b.pages.RemovePage("confirm")
+		b.app.SetFocus(b.list)
+		if label != "Delete" {
+			return
+		}
+
+		if _, err := trashContact(b.ctx, b.srv, c.ResourceName); err != nil {
+			b.setStatus("[red]" + tview.Escape(err.Error()))
+			return
+		}
+		before := *c
+		b.replaceContact(c.ResourceName, nil)
+		b.setStatus(b.recordStatus(fmt.Sprintf("[green]Deleted '%s'", tview.Escape(before.DisplayName)), journal.OpDelete, &before, nil))
+	})
+
+	b.pages.AddPage("confirm", modal, true, true)
+	b.app.SetFocus(modal)
+}
+
+// showEditForm opens a form to edit the main fields of a contact.
+// Phones and emails are edited as comma-separated 'type:value' lists.
+func (b *browser) showEditForm(c *contacts.ContactDetails) {
+	original := *c
+	phones := formatTypedValues(contacts.PhoneValues(c.Phones))
+	emails := formatTypedValues(contacts.EmailValues(c.Emails))
+
+	form := tview.NewForm().
+		AddInputField("First name", c.FirstName, 40, nil, nil).
+		AddInputField("Last name", c.LastName, 40, nil, nil).
+		AddInputField("Phones", phones, 60, nil, nil).
+		AddInputField("Emails", emails, 60, nil, nil).
+		AddInputField("Company", c.Company, 40, nil, nil).
+		AddInputField("Position", c.Position, 40, nil, nil).
+		AddInputField("Birthday", c.Birthday, 12, nil, nil).
+		AddTextArea("Notes", c.Notes, 60, 4, 0, nil)
+	form.SetBorder(true).SetTitle(" Edit " + tview.Escape(displayNameOrPlaceholder(c.DisplayName)) + " ")
+
+	closeForm := func() {
+		b.pages.RemovePage("edit")
+		b.app.SetFocus(b.list)
+	}
+	text := func(label string) string {
+		switch item := form.GetFormItemByLabel(label).(type) {
+		case *tview.InputField:
+			return strings.TrimSpace(item.GetText())
+		case *tview.TextArea:
+			return strings.TrimSpace(item.GetText())
+		}
+		return ""
+	}
+
+	form.AddButton("Save", func() {
+		input, err := browseUpdateInput(&original, map[string]string{
+			"firstName": text("First name"),
+			"lastName":  text("Last name"),
+			"phones":    text("Phones"),
+			"emails":    text("Emails"),
+			"company":   text("Company"),
+			"position":  text("Position"),
+			"birthday":  text("Birthday"),
+			"notes":     text("Notes"),
+		}, phones, emails)
+		if err != nil {
+			b.setStatus("[red]" + tview.Escape(err.Error()))
+			return
+		}
+		if input == nil {
+			closeForm()
+			b.setStatus("No changes")
+			return
+		}
+
+		updated, err := b.srv.UpdateContact(b.ctx, original.ResourceName, *input)
+		if err != nil {
+			b.setStatus("[red]" + tview.Escape(err.Error()))
+			return
+		}
+		closeForm()
+		b.replaceContact(original.ResourceName, updated)
+		b.setStatus(b.recordStatus(fmt.Sprintf("[green]Updated '%s'", tview.Escape(updated.DisplayName)), journal.OpUpdate, &original, updated))
+	})
+	form.AddButton("Cancel", closeForm)
+	form.SetCancelFunc(closeForm)
+
+	b.pages.AddPage("edit", centered(form, 80, 22), true, true)
+	b.app.SetFocus(form)
+}
+
+// browseUpdateInput builds the update for the edit form values, comparing
+// them with the original contact. Returns nil when nothing changed.
+func browseUpdateInput(original *contacts.ContactDetails, values map[string]string, phones, emails string) (*contacts.UpdateInput, error) {
+	input := &contacts.UpdateInput{}
+	changed := false
+
+	setString := func(value, current string, field **string) {
+		if value != current {
+			v := value
+			*field = &v
+			changed = true
+		}
+	}
+	setString(values["firstName"], original.FirstName, &input.FirstName)
+	setString(values["lastName"], original.LastName, &input.LastName)
+	setString(values["company"], original.Company, &input.Company)
+	setString(values["position"], original.Position, &input.Position)
+	setString(values["notes"], original.Notes, &input.Notes)
+
+	if values["birthday"] != original.Birthday {
+		if err := contacts.ValidateBirthday(values["birthday"]); err != nil {
+			return nil, err
+		}
+		if values["birthday"] == "" {
+			input.ClearBirthday = true
+		} else {
+			setString(values["birthday"], original.Birthday, &input.Birthday)
+		}
+		changed = true
+	}
+
+	if values["phones"] != phones {
+		list, err := parsePhones(splitTypedValues(values["phones"]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid phones: %w", err)
+		}
+		input.Phones = list
+		changed = true
+	}
+	if values["emails"] != emails {
+		list, err := parseEditedEmails(splitTypedValues(values["emails"]), original.Emails)
+		if err != nil {
+			return nil, fmt.Errorf("invalid emails: %w", err)
+		}
+		input.Emails = list
+		changed = true
+	}
+
+	if !changed {
+		return nil, nil
+	}
+	return input, nil
+}
+
+// replaceContact updates the local contact list after an edit (updated != nil)
+// or a deletion (updated == nil), and invalidates the disk cache.
+func (b *browser) replaceContact(resourceName string, updated *contacts.ContactDetails) {
+	for i := range b.all {
+		if b.all[i].ResourceName != resourceName {
+			continue
+		}
+		if updated != nil {
+			b.all[i] = *updated
+		} else {
+			b.all = append(b.all[:i], b.all[i+1:]...)
+		}
+		break
+	}
+	b.refresh()
+	if err := b.cache.Invalidate(); err != nil {
+		b.setStatus("[red]" + tview.Escape(err.Error()))
+	}
+}
+
+// recordStatus records a modification in the journal and returns the status
+// message, with the journal error appended on failure: warnings on stderr
+// would garble the screen.
+func (b *browser) recordStatus(status string, op journal.Operation, before, after *contacts.ContactDetails) string {
+	if _, err := appendOperation(b.ctx, b.srv, journal.NewEntry(op, journal.SourceCLI, "browse", before, after)); err != nil {
+		return status + " [red](failed to record operation: " + tview.Escape(err.Error()) + ")"
+	}
+	return status
+}
+
+// reload fetches the contacts again from Google.
+func (b *browser) reload() {
+	if err := b.cache.Invalidate(); err != nil {
+		b.setStatus("[red]" + tview.Escape(err.Error()))
+		return
+	}
+	list, err := b.srv.ListContactsCached(b.ctx, b.cache)
+	if err != nil {
+		b.setStatus("[red]" + tview.Escape(err.Error()))
+		return
+	}
+	b.all = list
+	b.refresh()
+	b.setStatus("[green]Contacts reloaded")
+}
+
+// copy puts a value on the clipboard and reports it in the status bar.
+func (b *browser) copy(what, value string) {
+	if err := copyToClipboard(value); err != nil {
+		b.setStatus("[red]" + tview.Escape(err.Error()))
+		return
+	}
+	b.setStatus(fmt.Sprintf("[green]Copied %s %s", what, tview.Escape(value)))
+}
+
+// centered wraps a primitive in a fixed-size box centered on the screen.
+func centered(p tview.Primitive, width, height int) tview.Primitive {
+	return tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(p, height, 1, true).
+			AddItem(nil, 0, 1, false), width, 1, true).
+		AddItem(nil, 0, 1, false)
+}
+
+// browseFilter returns the contacts of a group (all contacts when group is
+// empty) matching the search text: fuzzy-ranked when text is set, sorted
+// by name otherwise.
+func browseFilter(list []contacts.ContactDetails, text, group string) []contacts.ContactDetails {
+	var members []contacts.ContactDetails
+	for _, c := range list {
+		if group == "" || c.InGroup(group) {
+			members = append(members, c)
+		}
+	}
+
+	if strings.TrimSpace(text) == "" {
+		sort.SliceStable(members, func(i, j int) bool {
+			return contacts.FoldText(members[i].DisplayName) < contacts.FoldText(members[j].DisplayName)
+		})
+		return members
+	}
+
+	var matched []contacts.ContactDetails
+	for _, match := range contacts.FuzzySearch(members, text, 0) {
+		matched = append(matched, match.Contact)
+	}
+	return matched
+}
+
+// browsableGroups keeps the groups worth jumping to: non-empty groups,
+// except "all" which is the same as not filtering.
+func browsableGroups(groups []contacts.ContactGroup) []contacts.ContactGroup {
+	var result []contacts.ContactGroup
+	for _, g := range groups {
+		if g.MemberCount > 0 && g.ResourceName != "contactGroups/all" {
+			result = append(result, g)
+		}
+	}
+	return result
+}
+
+// formatTypedValues joins 'type:value' strings for a single-line form field.
+func formatTypedValues(values []string) string {
+	return strings.Join(values, ", ")
+}
+
+// splitTypedValues splits a form field back into 'type:value' strings.
+func splitTypedValues(text string) []string {
+	var values []string
+	for _, v := range strings.Split(text, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// clipboardCommand returns the command that copies its stdin to the system clipboard.
+func clipboardCommand() ([]string, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"pbcopy"}, nil
+	case "windows":
+		return []string{"clip"}, nil
+	}
+
+	candidates := [][]string{
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+	}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		candidates = append([][]string{{"wl-copy"}}, candidates...)
+	}
+	for _, c := range candidates {
+		if _, err := exec.LookPath(c[0]); err == nil {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("no clipboard tool found (install wl-copy, xclip or xsel)")
+}
+
+// copyToClipboard copies text to the system clipboard.
+func copyToClipboard(text string) error {
+	command, err := clipboardCommand()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to copy to clipboard: %w", err)
+	}
+	return nil
+}
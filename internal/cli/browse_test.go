@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"testing"
+
+	"google-contacts/internal/contacts"
+)
+
+func TestBrowseFilter(t *testing.T) {
+	list := []contacts.ContactDetails{
+		{ResourceName: "people/c1", DisplayName: "Zoé Martin", Groups: []string{"contactGroups/work"}},
+		{ResourceName: "people/c2", DisplayName: "Alice Durand"},
+		{ResourceName: "people/c3", DisplayName: "Émile Zola", Groups: []string{"contactGroups/work"}},
+	}
+
+	tests := []struct {
+		name     string
+		text     string
+		group    string
+		expected []string
+	}{
+		{name: "all sorted by folded name", expected: []string{"people/c2", "people/c3", "people/c1"}},
+		{name: "group only", group: "contactGroups/work", expected: []string{"people/c3", "people/c1"}},
+		{name: "accent-insensitive search", text: "zoe", expected: []string{"people/c1"}},
+		{name: "search within group", text: "alice", group: "contactGroups/work", expected: nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := browseFilter(list, tc.text, tc.group)
+			if len(got) != len(tc.expected) {
+				t.Fatalf("browseFilter() returned %d contacts, want %d", len(got), len(tc.expected))
+			}
+			for i, want := range tc.expected {
+				if got[i].ResourceName != want {
+					t.Errorf("browseFilter()[%d] = %q, want %q", i, got[i].ResourceName, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBrowseUpdateInput(t *testing.T) {
+	original := &contacts.ContactDetails{
+		FirstName: "John",
+		LastName:  "DOE",
+		Phones:    []contacts.PhoneEntry{{Value: "+33612345678", Type: "mobile"}},
+		Company:   "Acme",
+		Birthday:  "1985-03-15",
+	}
+	phones := formatTypedValues(contacts.PhoneValues(original.Phones))
+	unchanged := map[string]string{
+		"firstName": "John",
+		"lastName":  "DOE",
+		"phones":    phones,
+		"emails":    "",
+		"company":   "Acme",
+		"birthday":  "1985-03-15",
+	}
+
+	input, err := browseUpdateInput(original, unchanged, phones, "")
+	if err != nil || input != nil {
+		t.Errorf("browseUpdateInput() unchanged = %+v, %v; want nil, nil", input, err)
+	}
+
+	values := map[string]string{}
+	for k, v := range unchanged {
+		values[k] = v
+	}
+	values["company"] = "New Corp"
+	values["birthday"] = ""
+	values["phones"] = "mobile:+33612345678, work:+33100000000"
+	input, err = browseUpdateInput(original, values, phones, "")
+	if err != nil {
+		t.Fatalf("browseUpdateInput() error: %v", err)
+	}
+	if input.Company == nil || *input.Company != "New Corp" {
+		t.Errorf("Company = %v, want New Corp", input.Company)
+	}
+	if input.FirstName != nil {
+		t.Errorf("FirstName should not be updated")
+	}
+	if !input.ClearBirthday {
+		t.Error("ClearBirthday = false, want true")
+	}
+	if len(input.Phones) != 2 || input.Phones[1].Type != "work" {
+		t.Errorf("Phones = %+v, want 2 phones", input.Phones)
+	}
+
+	values["birthday"] = "1985-13-45"
+	if _, err := browseUpdateInput(original, values, phones, ""); err == nil {
+		t.Error("browseUpdateInput() expected error for invalid birthday")
+	}
+	values["birthday"] = "--03-15"
+	if input, err := browseUpdateInput(original, values, phones, ""); err != nil || input.Birthday == nil || *input.Birthday != "--03-15" {
+		t.Errorf("browseUpdateInput() yearless birthday = %+v, %v", input, err)
+	}
+
+	values["emails"] = "home:not-an-email"
+	if _, err := browseUpdateInput(original, values, phones, ""); err == nil {
+		t.Error("browseUpdateInput() expected error for invalid email")
+	}
+
+	// Malformed emails already on the contact can be kept
+	original.Emails = []contacts.EmailEntry{{Value: "not-an-email", Type: "home"}}
+	if _, err := browseUpdateInput(original, values, phones, ""); err != nil {
+		t.Errorf("browseUpdateInput() with an existing malformed email error = %v", err)
+	}
+}
+
+func TestBrowsableGroups(t *testing.T) {
+	groups := browsableGroups([]contacts.ContactGroup{
+		{ResourceName: "contactGroups/abc", MemberCount: 2},
+		{ResourceName: "contactGroups/empty"},
+		{ResourceName: "contactGroups/all", MemberCount: 10, System: true},
+		{ResourceName: "contactGroups/starred", MemberCount: 1, System: true},
+	})
+	if len(groups) != 2 || groups[0].ResourceName != "contactGroups/abc" || groups[1].ResourceName != "contactGroups/starred" {
+		t.Errorf("browsableGroups() = %+v", groups)
+	}
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"google-contacts/internal/contacts"
+	"google-contacts/pkg/auth"
+)
+
+// contactCache returns the local contact cache of the current profile.
+func contactCache() (*contacts.ContactCache, error) {
+	return profileContactCache(auth.CurrentProfile())
+}
+
+// profileContactCache returns the local contact cache of a profile.
+func profileContactCache(profile string) (*contacts.ContactCache, error) {
+	path, err := contacts.DefaultCachePath()
+	if err != nil {
+		return nil, err
+	}
+	return &contacts.ContactCache{Path: profilePath(path, profile), TTL: contacts.DefaultCacheTTL}, nil
+}
+
+// invalidateContactCache drops the local contact cache after a modification,
+// so that the next local search sees the change.
+func invalidateContactCache() {
+	cache, err := contactCache()
+	if err == nil {
+		err = cache.Invalidate()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// localSearch searches the cached contact list of a profile: contacts are
+// first filtered with the --where query (if any), then matched against the
+// text query (fuzzy-ranked with --fuzzy, plain substring otherwise).
+func localSearch(ctx context.Context, srv *contacts.Service, profile, query string, where *contacts.Query) ([]contacts.SearchResult, error) {
+	cache, err := profileContactCache(profile)
+	if err != nil {
+		return nil, err
+	}
+	if searchRefresh {
+		if err := cache.Invalidate(); err != nil {
+			return nil, err
+		}
+	}
+
+	list, err := srv.ListContactsCached(ctx, cache)
+	if err != nil {
+		return nil, err
+	}
+
+	if where != nil {
+		list = contacts.FilterContacts(list, where)
+	}
+
+	var matched []contacts.ContactDetails
+	switch {
+	case query == "":
+		matched = list
+	case searchFuzzy:
+		for _, match := range contacts.FuzzySearch(list, query, 0) {
+			matched = append(matched, match.Contact)
+		}
+	default:
+		matched = contacts.FilterContacts(list, contacts.NewTextQuery(query))
+	}
+
+	if searchLimit > 0 && len(matched) > searchLimit {
+		matched = matched[:searchLimit]
+	}
+
+	var results []contacts.SearchResult
+	for _, c := range matched {
+		results = append(results, contacts.SearchResultFromDetails(c))
+	}
+	return results, nil
+}
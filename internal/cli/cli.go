@@ -4,16 +4,20 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
-	"strconv"
+	"slices"
 	"strings"
 	"text/tabwriter"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
+	"google-contacts/internal/config"
 	"google-contacts/internal/contacts"
+	"google-contacts/internal/journal"
 	mcpserver "google-contacts/internal/mcp"
+	"google-contacts/pkg/auth"
 )
 
 // Version information
@@ -39,6 +43,15 @@ var (
 	createBirthday  string // Format: YYYY-MM-DD or --MM-DD
 )
 
+// Search command flags
+var (
+	searchFuzzy    bool
+	searchWhere    string
+	searchRefresh  bool
+	searchLimit    int
+	searchProfiles []string
+)
+
 // Delete command flags
 var (
 	deleteForce bool
@@ -74,6 +87,14 @@ var (
 	mcpSecretName     string
 	mcpSecretProject  string
 	mcpCredentialFile string
+	mcpCalendarFeed   bool
+	mcpCalendarToken  string
+	mcpCalendarAlarms []int
+	mcpServiceAccount string
+	mcpImpersonateDom string
+	mcpOAuthStore     string
+	mcpOAuthStorePath string
+	mcpOAuthKeyFile   string
 )
 
 // Command definitions
@@ -96,6 +117,12 @@ Required fields:
   --lastname, -l:  Last name
   --phone, -p:     Phone number (can be repeated for multiple phones)
 
+Interactive mode:
+  Run on a terminal without --firstname or --lastname to be asked for each
+  field, with a preview of the normalized phone numbers, parsed addresses
+  and birthday, and a final confirmation. Flags given on the command line
+  are used as defaults.
+
 Phone number format:
   - Simple: +33612345678 (defaults to "mobile" type)
   - With type: mobile:+33612345678
@@ -117,6 +144,10 @@ Address format:
 
 Address types: home (default), work, other
 
+The default types, the phone region used to convert national numbers
+(France by default) and the last name casing can be changed in the
+configuration file (see 'config show').
+
 Birthday format:
   - Full date: YYYY-MM-DD (e.g., "1985-03-15")
   - Month/day only: --MM-DD (e.g., "--03-15" when year is unknown)
@@ -159,11 +190,12 @@ Optional fields:
 
   # Create contact with all fields
   google-contacts create -f John -l Doe -p +33612345678 -c "Acme Inc" -r "CTO" -e john@acme.com -a "work:50 Avenue Business, Paris" -b 1985-03-15 -n "Met at conference"`,
-		RunE: runCreate,
+		Annotations: map[string]string{outputFormatsAnnotation: "json,yaml,csv"},
+		RunE:        runCreate,
 	}
 
 	searchCmd = &cobra.Command{
-		Use:   "search <query>",
+		Use:   "search [query]",
 		Short: "Search contacts",
 		Long: `Search for contacts matching the given query.
 
@@ -173,6 +205,34 @@ The query matches on:
   - Phone numbers
   - Company names
 
+Fuzzy mode (--fuzzy):
+  Searches locally over the full contact list instead of using Google's
+  prefix search. Matching ignores accents and case ("francois" finds
+  "François"), tolerates typos ("Jonh") and phonetic variants ("Smyth"),
+  and ranks results by relevance. Digit-only queries match phone numbers.
+  The contact list is cached locally for 10 minutes (--refresh to bypass).
+
+Filtering (--where):
+  Filters the local contact list with a structured query. Terms are
+  combined with AND, OR separates alternatives, '-' negates a term and
+  values with spaces are quoted (company:"acme corp").
+
+  Fields:
+    name: first: last: company: position: email: notes:  Text contains
+    phone:                                               Phone digits contain
+    address: city: postal: country:                      Address parts
+    has:name|phone|email|address|company|position|notes|birthday|event
+    birthday:MM  birthday:MM-DD  birthday:YYYY  birthday:>YYYY[-MM-DD]
+    updated:YYYY[-MM[-DD]] with optional >, >=, <, <=
+
+  Words without a field match names, company and emails. The query
+  argument is optional with --where and further narrows the results.
+
+Several accounts (--profiles):
+  Searches the given profiles (see 'auth profiles'), or every
+  authenticated profile with --profiles all, and adds a profile column.
+  Profiles that are not authenticated are skipped with a warning.
+
 Output behavior:
   - Multiple results: Shows a summary table
   - Single result: Shows full contact details`,
@@ -186,19 +246,32 @@ Output behavior:
   google-contacts search "Acme"
 
   # Search by phone (partial)
-  google-contacts search "0612"`,
-		Args: cobra.ExactArgs(1),
-		RunE: runSearch,
+  google-contacts search "0612"
+
+  # Accent- and typo-tolerant search
+  google-contacts search --fuzzy "francois dupond"
+
+  # Structured filter
+  google-contacts search --where 'company:acme has:phone -has:email birthday:10 city:Paris updated:>2025-01-01'
+
+  # Search the work and personal accounts
+  google-contacts search --profiles work,personal "John"`,
+		Args:        cobra.MaximumNArgs(1),
+		Annotations: map[string]string{outputFormatsAnnotation: "json,yaml,csv,vcard"},
+		RunE:        runSearch,
 	}
 
 	showCmd = &cobra.Command{
-		Use:   "show <contact-id>",
+		Use:   "show <contact>",
 		Short: "Show contact details",
 		Long: `Display full information for a contact.
 
-The contact ID can be:
+The contact can be:
   - Full resource name: people/c123456789
   - Just the ID: c123456789
+  - A name, email or phone number: "Jane Doe", jane@example.com, 0612345678
+    (when several contacts match, you are asked to choose one; without a
+    terminal the command fails and lists the candidates)
 
 Displays:
   - Name (first and last)
@@ -212,44 +285,59 @@ Displays:
   google-contacts show people/c123456789
 
   # Show by ID only
-  google-contacts show c123456789`,
-		Args: cobra.ExactArgs(1),
-		RunE: runShow,
+  google-contacts show c123456789
+
+  # Show by name, email or phone
+  google-contacts show "Jane Doe"
+  google-contacts show jane@example.com`,
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{outputFormatsAnnotation: "json,yaml,csv,vcard"},
+		RunE:        runShow,
 	}
 
 	deleteCmd = &cobra.Command{
-		Use:   "delete <contact-id>",
+		Use:   "delete <contact>",
 		Short: "Delete a contact",
 		Long: `Delete a contact from Google Contacts.
 
-The contact ID can be:
+The contact can be:
   - Full resource name: people/c123456789
   - Just the ID: c123456789
+  - A name, email or phone number: "Jane Doe", jane@example.com, 0612345678
+    (when several contacts match, you are asked to choose one; without a
+    terminal the command fails and lists the candidates)
 
 Safety:
   - By default, displays contact summary and prompts for confirmation
   - Use --force to skip confirmation
 
-Note: Deletion is permanent and cannot be undone.`,
+The contact is archived in the local trash before deletion (all fields,
+groups and photo): 'google-contacts trash restore' recreates it with a new ID.`,
 		Example: `  # Delete with confirmation prompt
   google-contacts delete c123456789
 
   # Delete without confirmation (use with caution)
   google-contacts delete c123456789 --force`,
-		Args: cobra.ExactArgs(1),
-		RunE: runDelete,
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{outputFormatsAnnotation: "json,yaml,csv"},
+		RunE:        runDelete,
 	}
 
 	updateCmd = &cobra.Command{
-		Use:   "update <contact-id>",
+		Use:   "update <contact>",
 		Short: "Update a contact",
 		Long: `Update an existing contact in Google Contacts.
 
-The contact ID can be:
+The contact can be:
   - Full resource name: people/c123456789
   - Just the ID: c123456789
+  - A name, email or phone number: "Jane Doe", jane@example.com, 0612345678
+    (when several contacts match, you are asked to choose one; without a
+    terminal the command fails and lists the candidates)
 
 Only the specified fields will be updated. Unspecified fields remain unchanged.
+Run on a terminal without any field flag to be asked for each field
+(Enter keeps the current value, '-' clears it).
 
 Phone management options:
   --phone, -p:       Update primary phone (replaces first phone)
@@ -334,8 +422,9 @@ Other fields:
 
   # Remove birthday
   google-contacts update c123456789 --clear-birthday`,
-		Args: cobra.ExactArgs(1),
-		RunE: runUpdate,
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{outputFormatsAnnotation: "json,yaml,csv,vcard"},
+		RunE:        runUpdate,
 	}
 
 	mcpCmd = &cobra.Command{
@@ -353,6 +442,7 @@ Available tools:
   - contacts_show: Get contact details by ID
   - contacts_update: Update an existing contact
   - contacts_delete: Delete a contact
+  - contacts_audit: Report contact data-quality problems
 
 Authentication:
   The server implements OAuth 2.1 with Dynamic Client Registration
@@ -366,6 +456,20 @@ Authentication:
   - /oauth/authorize - Authorization endpoint (redirects to Google)
   - /oauth/token - Token endpoint
 
+Service-account mode:
+  With --service-account, callers only authorize their identity (openid
+  email) and the server accesses their contacts with a Workspace service
+  account acting as them (domain-wide delegation). The caller's token must
+  have been issued to the server's OAuth client and carry a verified email;
+  --impersonate-domain restricts the server to the users of one domain.
+
+Calendar feed:
+  With --calendar-feed, the server also publishes an iCalendar feed of
+  contact birthdays and events at /calendar/<token>.ics, suitable for
+  calendar subscriptions. The feed uses the local CLI credentials
+  (~/.credentials/google_token.json). --calendar-token (CALENDAR_TOKEN)
+  is required, so that the feed URL stays the same across restarts.
+
 The server listens on the specified host and port, serving the MCP
 protocol via streamable HTTP transport with OAuth2 authentication.`,
 		Example: `  # Start MCP server on default port (8080)
@@ -378,36 +482,34 @@ protocol via streamable HTTP transport with OAuth2 authentication.`,
   google-contacts mcp --secret-project "my-gcp-project" --secret-name "oauth-credentials"
 
   # Start on all interfaces (for remote access)
-  google-contacts mcp --host 0.0.0.0 --port 8080`,
+  google-contacts mcp --host 0.0.0.0 --port 8080
+
+  # Serve the users of a Workspace domain with a service account
+  google-contacts mcp --service-account sa.json --impersonate-domain example.com
+
+  # Publish the birthday calendar feed with a fixed token
+  google-contacts mcp --calendar-feed --calendar-token "my-secret-token" --calendar-alarm-days 1`,
 		RunE: runMCP,
 	}
 )
 
 // parsePhones parses phone strings in format "type:number" or just "number".
-// Valid types: mobile (default), work, home, main, other
+// Valid types: mobile (default, see the configuration), work, home, main, other
 func parsePhones(phoneStrs []string) ([]contacts.PhoneEntry, error) {
-	validTypes := map[string]bool{
-		"mobile": true,
-		"work":   true,
-		"home":   true,
-		"main":   true,
-		"other":  true,
-	}
-
 	var phones []contacts.PhoneEntry
 	for _, ps := range phoneStrs {
 		var entry contacts.PhoneEntry
 		if idx := strings.Index(ps, ":"); idx > 0 {
 			// Format: type:number
 			phoneType := strings.ToLower(ps[:idx])
-			if !validTypes[phoneType] {
-				return nil, fmt.Errorf("invalid phone type '%s', valid types: mobile, work, home, main, other", phoneType)
+			if !slices.Contains(contacts.PhoneTypes, phoneType) {
+				return nil, fmt.Errorf("invalid phone type '%s', valid types: %s", phoneType, strings.Join(contacts.PhoneTypes, ", "))
 			}
 			entry.Type = phoneType
 			entry.Value = ps[idx+1:]
 		} else {
-			// Format: just number (defaults to mobile)
-			entry.Type = "mobile"
+			// Format: just number (default type)
+			entry.Type = contacts.DefaultPhoneType
 			entry.Value = ps
 		}
 		if entry.Value == "" {
@@ -419,65 +521,63 @@ func parsePhones(phoneStrs []string) ([]contacts.PhoneEntry, error) {
 }
 
 // parseEmails parses email strings in format "type:email" or just "email".
-// Valid types: work (default), home, other
+// Valid types: work (default, see the configuration), home, other
 func parseEmails(emailStrs []string) ([]contacts.EmailEntry, error) {
-	validTypes := map[string]bool{
-		"work":  true,
-		"home":  true,
-		"other": true,
-	}
+	return parseEditedEmails(emailStrs, nil)
+}
 
+// parseEditedEmails is parseEmails for an edited list: the addresses of
+// existing, already stored on the contact, are not validated again.
+func parseEditedEmails(emailStrs []string, existing []contacts.EmailEntry) ([]contacts.EmailEntry, error) {
 	var emails []contacts.EmailEntry
 	for _, es := range emailStrs {
 		var entry contacts.EmailEntry
 		if idx := strings.Index(es, ":"); idx > 0 {
 			// Format: type:email
 			emailType := strings.ToLower(es[:idx])
-			if !validTypes[emailType] {
-				return nil, fmt.Errorf("invalid email type '%s', valid types: work, home, other", emailType)
+			if !slices.Contains(contacts.EmailTypes, emailType) {
+				return nil, fmt.Errorf("invalid email type '%s', valid types: %s", emailType, strings.Join(contacts.EmailTypes, ", "))
 			}
 			entry.Type = emailType
 			entry.Value = es[idx+1:]
 		} else {
-			// Format: just email (defaults to work)
-			entry.Type = "work"
+			// Format: just email (default type)
+			entry.Type = contacts.DefaultEmailType
 			entry.Value = es
 		}
-		if entry.Value == "" {
-			return nil, fmt.Errorf("email address cannot be empty")
-		}
 		emails = append(emails, entry)
 	}
+	var kept []string
+	for _, e := range existing {
+		kept = append(kept, e.Value)
+	}
+	if err := contacts.ValidateNewEmails(emails, kept); err != nil {
+		return nil, err
+	}
 	return emails, nil
 }
 
 // parseAddresses parses address strings in format "type:address" or just "address".
-// Valid types: home (default), work, other
+// Valid types: home (default, see the configuration), work, other
 func parseAddresses(addrStrs []string) ([]contacts.AddressEntry, error) {
-	validTypes := map[string]bool{
-		"home":  true,
-		"work":  true,
-		"other": true,
-	}
-
 	var addresses []contacts.AddressEntry
 	for _, as := range addrStrs {
 		var entry contacts.AddressEntry
 		if idx := strings.Index(as, ":"); idx > 0 {
 			// Check if this looks like a type prefix (short word before colon)
 			potentialType := strings.ToLower(as[:idx])
-			if validTypes[potentialType] {
+			if slices.Contains(contacts.AddressTypes, potentialType) {
 				// Format: type:address
 				entry.Type = potentialType
 				entry.Value = as[idx+1:]
 			} else {
-				// Not a valid type, treat whole string as address (defaults to home)
-				entry.Type = "home"
+				// Not a valid type, treat whole string as address (default type)
+				entry.Type = contacts.DefaultAddressType
 				entry.Value = as
 			}
 		} else {
-			// Format: just address (defaults to home)
-			entry.Type = "home"
+			// Format: just address (default type)
+			entry.Type = contacts.DefaultAddressType
 			entry.Value = as
 		}
 		if entry.Value == "" {
@@ -489,6 +589,15 @@ func parseAddresses(addrStrs []string) ([]contacts.AddressEntry, error) {
 }
 
 func runCreate(cmd *cobra.Command, args []string) error {
+	// Without names on a terminal, ask for each field instead
+	if (createFirstName == "" || createLastName == "") && isInteractive() {
+		defaults, err := createDefaults()
+		if err != nil {
+			return err
+		}
+		return runCreateWizard(defaults)
+	}
+
 	// Validate required fields
 	if createFirstName == "" {
 		return fmt.Errorf("first name is required (--firstname or -f)")
@@ -535,7 +644,7 @@ func runCreate(cmd *cobra.Command, args []string) error {
 	// Create the contact
 	input := contacts.ContactInput{
 		FirstName: createFirstName,
-		LastName:  createLastName,
+		LastName:  contacts.FormatLastName(createLastName, lastNameCase),
 		Phones:    phones,
 		Emails:    emails,
 		Addresses: addresses,
@@ -549,23 +658,53 @@ func runCreate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	invalidateContactCache()
+	recordOperation(ctx, srv, "create", journal.OpCreate, nil, &contacts.ContactDetails{ResourceName: created.ResourceName, DisplayName: created.DisplayName})
 
-	// Display success message
-	green := color.New(color.FgGreen).SprintFunc()
-	cyan := color.New(color.FgCyan).SprintFunc()
-
-	fmt.Println(green("Contact created successfully!"))
-	fmt.Println()
-	fmt.Printf("  %s: %s\n", cyan("Name"), created.DisplayName)
-	fmt.Printf("  %s: %s\n", cyan("ID"), created.ResourceName)
+	output := mcpserver.NewCreateOutput(created)
+	return commandOutput{
+		table: func() {
+			// Display success message
+			green := color.New(color.FgGreen).SprintFunc()
+			cyan := color.New(color.FgCyan).SprintFunc()
 
-	return nil
+			fmt.Println(green("Contact created successfully!"))
+			fmt.Println()
+			fmt.Printf("  %s: %s\n", cyan("Name"), created.DisplayName)
+			fmt.Printf("  %s: %s\n", cyan("ID"), created.ResourceName)
+		},
+		data: output,
+		csv: func() ([]string, [][]string) {
+			return []string{"resourceName", "displayName"}, [][]string{{output.ResourceName, output.DisplayName}}
+		},
+	}.write()
 }
 
 func runSearch(cmd *cobra.Command, args []string) error {
-	query := args[0]
+	var query string
+	if len(args) > 0 {
+		query = args[0]
+	}
+	if query == "" && searchWhere == "" {
+		return fmt.Errorf("a search query or --where filter is required")
+	}
+
+	// Validate the filter before contacting the API
+	var where *contacts.Query
+	if searchWhere != "" {
+		var err error
+		where, err = contacts.ParseQuery(searchWhere)
+		if err != nil {
+			return err
+		}
+	}
+
 	ctx := context.Background()
 
+	if len(searchProfiles) > 0 {
+		return runProfileSearch(ctx, query, where)
+	}
+
 	// Get People API service
 	srv, err := contacts.GetPeopleService(ctx)
 	if err != nil {
@@ -573,25 +712,34 @@ func runSearch(cmd *cobra.Command, args []string) error {
 	}
 
 	// Search for contacts
-	results, err := srv.SearchContacts(ctx, query)
+	var results []contacts.SearchResult
+	if searchFuzzy || where != nil {
+		results, err = localSearch(ctx, srv, auth.CurrentProfile(), query, where)
+	} else {
+		results, err = srv.SearchContacts(ctx, query)
+	}
 	if err != nil {
 		return err
 	}
 
-	if len(results) == 0 {
-		fmt.Printf("No contacts found matching \"%s\"\n", query)
-		return nil
-	}
-
-	// Single result: show full details
-	if len(results) == 1 {
-		displayContactDetails(&results[0])
-		return nil
-	}
-
-	// Multiple results: show summary table
-	displayContactTable(results)
-	return nil
+	output := mcpserver.NewSearchOutput(results)
+	return commandOutput{
+		table: func() {
+			switch len(results) {
+			case 0:
+				fmt.Printf("No contacts found matching \"%s\"\n", strings.TrimSpace(query+" "+searchWhere))
+			case 1:
+				// Single result: show full details
+				displayContactDetails(&results[0])
+			default:
+				// Multiple results: show summary table
+				displayContactTable(results)
+			}
+		},
+		data:  output,
+		csv:   searchCSV(output),
+		vcard: searchResultsAsDetails(results),
+	}.write()
 }
 
 func runShow(cmd *cobra.Command, args []string) error {
@@ -604,19 +752,34 @@ func runShow(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to initialize service: %w", err)
 	}
 
+	contactID, err = resolveContact(ctx, srv, contactID)
+	if err != nil {
+		return err
+	}
+
 	// Get contact details
 	details, err := srv.GetContactDetails(ctx, contactID)
 	if err != nil {
 		return err
 	}
 
-	// Display full contact details
-	displayFullContactDetails(details)
-	return nil
+	output := mcpserver.NewShowOutput(details)
+	return commandOutput{
+		table: func() { displayFullContactDetails(details) },
+		data:  output,
+		csv:   showCSV(output),
+		vcard: []contacts.ContactDetails{*details},
+	}.write()
 }
 
 func runDelete(cmd *cobra.Command, args []string) error {
 	contactID := args[0]
+
+	// Machine-readable output cannot be mixed with the confirmation prompt
+	if outputFormat != outputTable && !deleteForce {
+		return fmt.Errorf("--output %s requires --force", outputFormat)
+	}
+
 	ctx := context.Background()
 
 	// Get People API service
@@ -625,6 +788,11 @@ func runDelete(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to initialize service: %w", err)
 	}
 
+	contactID, err = resolveContact(ctx, srv, contactID)
+	if err != nil {
+		return err
+	}
+
 	// Get contact details first (for display and confirmation)
 	details, err := srv.GetContactDetails(ctx, contactID)
 	if err != nil {
@@ -632,7 +800,9 @@ func runDelete(cmd *cobra.Command, args []string) error {
 	}
 
 	// Display contact summary
-	displayDeleteSummary(details)
+	if outputFormat == outputTable {
+		displayDeleteSummary(details)
+	}
 
 	// If not forced, ask for confirmation
 	if !deleteForce {
@@ -647,18 +817,29 @@ func runDelete(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Delete the contact
-	err = srv.DeleteContact(ctx, contactID)
+	// Archive the contact in the trash, then delete it
+	item, err := trashContact(ctx, srv, contactID)
 	if err != nil {
 		return err
 	}
-
-	// Display success message
-	green := color.New(color.FgGreen).SprintFunc()
-	fmt.Println()
-	fmt.Printf("%s Contact '%s' has been deleted.\n", green("✓"), details.DisplayName)
-
-	return nil
+	invalidateContactCache()
+	recordOperation(ctx, srv, "delete", journal.OpDelete, details, nil)
+
+	output := mcpserver.NewDeleteOutput(details)
+	output.TrashID = item.ID
+	return commandOutput{
+		table: func() {
+			// Display success message
+			green := color.New(color.FgGreen).SprintFunc()
+			fmt.Println()
+			fmt.Printf("%s Contact '%s' has been deleted.\n", green("✓"), details.DisplayName)
+			fmt.Printf("  Restore it with: google-contacts trash restore %s\n", item.ID)
+		},
+		data: output,
+		csv: func() ([]string, [][]string) {
+			return []string{"deletedId", "displayName", "trashId"}, [][]string{{output.DeletedID, output.DisplayName, output.TrashID}}
+		},
+	}.write()
 }
 
 func runUpdate(cmd *cobra.Command, args []string) error {
@@ -675,7 +856,8 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 		hasUpdates = true
 	}
 	if cmd.Flags().Changed("lastname") {
-		input.LastName = &updateLastName
+		lastName := contacts.FormatLastName(updateLastName, lastNameCase)
+		input.LastName = &lastName
 		hasUpdates = true
 	}
 
@@ -787,6 +969,9 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 	}
 
 	// Check if any fields were provided
+	if !hasUpdates && isInteractive() {
+		return runUpdateWizard(contactID)
+	}
 	if !hasUpdates {
 		return fmt.Errorf("no fields specified to update. Use --help to see available flags")
 	}
@@ -797,6 +982,11 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to initialize service: %w", err)
 	}
 
+	contactID, err = resolveContact(ctx, srv, contactID)
+	if err != nil {
+		return err
+	}
+
 	// Get current contact details first (for before display)
 	beforeDetails, err := srv.GetContactDetails(ctx, contactID)
 	if err != nil {
@@ -808,61 +998,39 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-
-	// Display success message with before/after summary
-	displayUpdateSummary(beforeDetails, afterDetails)
-
-	return nil
+	invalidateContactCache()
+	recordOperation(ctx, srv, "update", journal.OpUpdate, beforeDetails, afterDetails)
+
+	output := mcpserver.NewUpdateOutput(afterDetails)
+	return commandOutput{
+		// Display success message with before/after summary
+		table: func() { displayUpdateSummary(beforeDetails, afterDetails) },
+		data:  output,
+		csv:   showCSV(output.ShowOutput),
+		vcard: []contacts.ContactDetails{*afterDetails},
+	}.write()
 }
 
 func runMCP(cmd *cobra.Command, args []string) error {
-	// Determine host: use flag value, then HOST env var, then default
-	host := mcpHost
-	if host == "localhost" {
-		// Check for HOST environment variable (Cloud Run compatible)
-		if envHost := os.Getenv("HOST"); envHost != "" {
-			host = envHost
-		}
-	}
-
-	// Determine port: use flag value, then PORT env var, then default
-	port := mcpPort
-	if envPort := os.Getenv("PORT"); envPort != "" {
-		if p, err := strconv.Atoi(envPort); err == nil {
-			port = p
-		}
-	}
-
-	// Get Secret Manager project from env if not set via flag
-	secretProject := mcpSecretProject
-	if secretProject == "" {
-		secretProject = os.Getenv("SECRET_PROJECT")
-		if secretProject == "" {
-			secretProject = os.Getenv("PROJECT_ID") // Fallback to PROJECT_ID
-		}
-	}
-
-	// Get Secret Manager secret name from env if not set via flag
-	secretName := mcpSecretName
-	if secretName == "" {
-		secretName = os.Getenv("SECRET_NAME")
+	// Resolve settings: flag, then environment (Cloud Run compatible), then config file
+	cfg, err := mcpConfig(config.NewResolver(cmd.Flags()), userConfig.MCP)
+	if err != nil {
+		return err
 	}
 
-	// Get base URL from env if not set via flag
-	baseURL := mcpBaseURL
-	if baseURL == "" {
-		baseURL = os.Getenv("BASE_URL")
+	// Record tool modifications in the local operation journal
+	j, err := operationJournal()
+	if err != nil {
+		return err
 	}
+	cfg.Journal = j
 
-	// Create MCP server configuration
-	cfg := &mcpserver.Config{
-		Host:           host,
-		Port:           port,
-		BaseURL:        baseURL,
-		SecretName:     secretName,
-		SecretProject:  secretProject,
-		CredentialFile: mcpCredentialFile,
+	// Archive deleted contacts for contacts_restore
+	store, err := contactTrash()
+	if err != nil {
+		return err
 	}
+	cfg.Trash = store
 
 	// Create and run the MCP server
 	server := mcpserver.NewServer(cfg)
@@ -989,33 +1157,38 @@ func displayContactDetails(contact *contacts.SearchResult) {
 
 // displayFullContactDetails shows complete information for a contact (from show command).
 func displayFullContactDetails(details *contacts.ContactDetails) {
+	writeFullContactDetails(os.Stdout, details)
+}
+
+// writeFullContactDetails writes the full contact view to w.
+func writeFullContactDetails(w io.Writer, details *contacts.ContactDetails) {
 	cyan := color.New(color.FgCyan).SprintFunc()
 	green := color.New(color.FgGreen).SprintFunc()
 	yellow := color.New(color.FgYellow).SprintFunc()
 
-	fmt.Println(green("Contact Details"))
-	fmt.Println(strings.Repeat("─", 40))
-	fmt.Println()
+	fmt.Fprintln(w, green("Contact Details"))
+	fmt.Fprintln(w, strings.Repeat("─", 40))
+	fmt.Fprintln(w)
 
 	// Name section
-	fmt.Printf("  %s: %s\n", cyan("Name"), details.DisplayName)
+	fmt.Fprintf(w, "  %s: %s\n", cyan("Name"), details.DisplayName)
 	if details.FirstName != "" || details.LastName != "" {
-		fmt.Printf("    %s: %s\n", yellow("First"), details.FirstName)
-		fmt.Printf("    %s: %s\n", yellow("Last"), details.LastName)
+		fmt.Fprintf(w, "    %s: %s\n", yellow("First"), details.FirstName)
+		fmt.Fprintf(w, "    %s: %s\n", yellow("Last"), details.LastName)
 	}
 
 	// Contact ID
-	fmt.Printf("  %s: %s\n", cyan("ID"), extractID(details.ResourceName))
-	fmt.Println()
+	fmt.Fprintf(w, "  %s: %s\n", cyan("ID"), extractID(details.ResourceName))
+	fmt.Fprintln(w)
 
 	// Phone numbers
 	if len(details.Phones) > 0 {
 		if len(details.Phones) == 1 {
-			fmt.Printf("  %s: %s (%s)\n", cyan("Phone"), details.Phones[0].Value, yellow(details.Phones[0].Type))
+			fmt.Fprintf(w, "  %s: %s (%s)\n", cyan("Phone"), details.Phones[0].Value, yellow(details.Phones[0].Type))
 		} else {
-			fmt.Printf("  %s:\n", cyan("Phones"))
+			fmt.Fprintf(w, "  %s:\n", cyan("Phones"))
 			for _, phone := range details.Phones {
-				fmt.Printf("    • %s (%s)\n", phone.Value, yellow(phone.Type))
+				fmt.Fprintf(w, "    • %s (%s)\n", phone.Value, yellow(phone.Type))
 			}
 		}
 	}
@@ -1023,59 +1196,59 @@ func displayFullContactDetails(details *contacts.ContactDetails) {
 	// Email addresses
 	if len(details.Emails) > 0 {
 		if len(details.Emails) == 1 {
-			fmt.Printf("  %s: %s (%s)\n", cyan("Email"), details.Emails[0].Value, yellow(details.Emails[0].Type))
+			fmt.Fprintf(w, "  %s: %s (%s)\n", cyan("Email"), details.Emails[0].Value, yellow(details.Emails[0].Type))
 		} else {
-			fmt.Printf("  %s:\n", cyan("Emails"))
+			fmt.Fprintf(w, "  %s:\n", cyan("Emails"))
 			for _, email := range details.Emails {
-				fmt.Printf("    • %s (%s)\n", email.Value, yellow(email.Type))
+				fmt.Fprintf(w, "    • %s (%s)\n", email.Value, yellow(email.Type))
 			}
 		}
 	}
 
 	// Addresses
 	if len(details.Addresses) > 0 {
-		fmt.Println()
+		fmt.Fprintln(w)
 		if len(details.Addresses) == 1 {
-			fmt.Printf("  %s: %s (%s)\n", cyan("Address"), details.Addresses[0].Value, yellow(details.Addresses[0].Type))
+			fmt.Fprintf(w, "  %s: %s (%s)\n", cyan("Address"), details.Addresses[0].Value, yellow(details.Addresses[0].Type))
 		} else {
-			fmt.Printf("  %s:\n", cyan("Addresses"))
+			fmt.Fprintf(w, "  %s:\n", cyan("Addresses"))
 			for _, addr := range details.Addresses {
-				fmt.Printf("    • %s (%s)\n", addr.Value, yellow(addr.Type))
+				fmt.Fprintf(w, "    • %s (%s)\n", addr.Value, yellow(addr.Type))
 			}
 		}
 	}
 
 	// Organization
 	if details.Company != "" || details.Position != "" {
-		fmt.Println()
+		fmt.Fprintln(w)
 		if details.Company != "" {
-			fmt.Printf("  %s: %s\n", cyan("Company"), details.Company)
+			fmt.Fprintf(w, "  %s: %s\n", cyan("Company"), details.Company)
 		}
 		if details.Position != "" {
-			fmt.Printf("  %s: %s\n", cyan("Position"), details.Position)
+			fmt.Fprintf(w, "  %s: %s\n", cyan("Position"), details.Position)
 		}
 	}
 
 	// Birthday
 	if details.Birthday != "" {
-		fmt.Println()
-		fmt.Printf("  %s: %s\n", cyan("Birthday"), formatBirthdayDisplay(details.Birthday))
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "  %s: %s\n", cyan("Birthday"), formatBirthdayDisplay(details.Birthday))
 	}
 
 	// Notes
 	if details.Notes != "" {
-		fmt.Println()
-		fmt.Printf("  %s:\n", cyan("Notes"))
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "  %s:\n", cyan("Notes"))
 		// Indent multiline notes
 		for _, line := range strings.Split(details.Notes, "\n") {
-			fmt.Printf("    %s\n", line)
+			fmt.Fprintf(w, "    %s\n", line)
 		}
 	}
 
 	// Metadata
 	if details.UpdatedAt != "" {
-		fmt.Println()
-		fmt.Printf("  %s: %s\n", cyan("Updated"), formatTime(details.UpdatedAt))
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "  %s: %s\n", cyan("Updated"), formatTime(details.UpdatedAt))
 	}
 }
 
@@ -1203,6 +1376,15 @@ func Init() {
 	RootCmd.Version = Version
 	RootCmd.SetVersionTemplate("google-contacts version {{.Version}}\n")
 
+	// Setup global output flag
+	RootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputTable, "Output format: table, json, yaml, csv or vcard")
+	RootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Configuration file (default <user config dir>/google-contacts/config.yaml)")
+	RootCmd.PersistentFlags().StringVar(&profileName, "profile", auth.DefaultProfile, "Google account profile (see 'auth profiles')")
+	RootCmd.PersistentFlags().StringVar(&phoneRegion, "phone-region", contacts.DefaultPhoneRegion, "Region of national phone numbers, e.g. FR or GB")
+	RootCmd.PersistentFlags().StringVar(&credentialsDir, "credentials-dir", "", "OAuth credentials directory (default ~/.credentials)")
+	RootCmd.PersistentFlags().StringVar(&impersonate, "impersonate", "", "Workspace user to act as with the service account (serviceAccount setting)")
+	RootCmd.PersistentPreRunE = applyConfig
+
 	// Setup create command flags
 	createCmd.Flags().StringVarP(&createFirstName, "firstname", "f", "", "First name (required)")
 	createCmd.Flags().StringVarP(&createLastName, "lastname", "l", "", "Last name (required)")
@@ -1214,6 +1396,13 @@ func Init() {
 	createCmd.Flags().StringVarP(&createNotes, "notes", "n", "", "Notes about the contact")
 	createCmd.Flags().StringVarP(&createBirthday, "birthday", "b", "", "Birthday (YYYY-MM-DD or --MM-DD)")
 
+	// Setup search command flags
+	searchCmd.Flags().BoolVar(&searchFuzzy, "fuzzy", false, "Accent- and typo-tolerant local search over all contacts")
+	searchCmd.Flags().StringVar(&searchWhere, "where", "", "Structured filter, e.g. 'company:acme has:phone -has:email'")
+	searchCmd.Flags().BoolVar(&searchRefresh, "refresh", false, "Refresh the local contact cache (with --fuzzy or --where)")
+	searchCmd.Flags().IntVar(&searchLimit, "limit", 20, "Maximum number of results (with --fuzzy or --where, 0 = no limit)")
+	searchCmd.Flags().StringSliceVar(&searchProfiles, "profiles", nil, "Search several profiles (comma-separated, or 'all')")
+
 	// Setup delete command flags
 	deleteCmd.Flags().BoolVarP(&deleteForce, "force", "f", false, "Skip confirmation prompt")
 
@@ -1244,6 +1433,60 @@ func Init() {
 	mcpCmd.Flags().StringVar(&mcpSecretName, "secret-name", "", "Secret Manager secret name for OAuth credentials")
 	mcpCmd.Flags().StringVar(&mcpSecretProject, "secret-project", "", "GCP project for Secret Manager")
 	mcpCmd.Flags().StringVar(&mcpCredentialFile, "credential-file", "", "Local OAuth credential file path (fallback)")
+	mcpCmd.Flags().BoolVar(&mcpCalendarFeed, "calendar-feed", false, "Publish the birthday calendar feed at /calendar/<token>.ics (needs --calendar-token)")
+	mcpCmd.Flags().StringVar(&mcpCalendarToken, "calendar-token", "", "Secret token for the calendar feed URL (implies --calendar-feed)")
+	mcpCmd.Flags().IntSliceVar(&mcpCalendarAlarms, "calendar-alarm-days", nil, "Calendar feed reminders in days before each event (can be repeated)")
+	mcpCmd.Flags().StringVar(&mcpServiceAccount, "service-account", "", "Service account key file: act as each caller with domain-wide delegation")
+	mcpCmd.Flags().StringVar(&mcpImpersonateDom, "impersonate-domain", "", "Only serve the callers of this Workspace domain (with --service-account)")
+	mcpCmd.Flags().StringVar(&mcpOAuthStore, "oauth-store", "file", "Where OAuth clients and tokens are kept: memory, file or firestore")
+	mcpCmd.Flags().StringVar(&mcpOAuthStorePath, "oauth-store-path", "", "OAuth store file (default: user config dir, with --oauth-store file)")
+	mcpCmd.Flags().StringVar(&mcpOAuthKeyFile, "oauth-key-file", "", "Key file encrypting the Google tokens in the OAuth store (default: created in the user config dir, with --oauth-store file)")
+
+	// Setup audit command flags
+	auditCmd.Flags().StringArrayVar(&auditRules, "rule", nil, "Rule to run (can be repeated, default: all rules)")
+	auditCmd.Flags().BoolVar(&auditJSON, "json", false, "Output findings as JSON (same as --output json)")
+	auditCmd.Flags().StringVar(&auditGroupBy, "group-by", "rule", "Group findings by 'rule' or 'contact'")
+
+	// Setup fix command flags
+	fixCmd.Flags().BoolVar(&fixDryRun, "dry-run", false, "Show changes without applying them")
+	fixCmd.Flags().IntVar(&fixMaxChanges, "max-changes", 50, "Maximum number of contacts to update in one run (0 = no limit)")
+	fixCmd.Flags().StringVar(&fixProgressFile, "progress-file", "", "Progress file for resuming (default: user cache dir)")
+	fixCmd.Flags().BoolVar(&fixRestart, "restart", false, "Ignore progress from a previous interrupted run")
+
+	// Setup edit command flags
+	editCmd.Flags().BoolVarP(&editForce, "force", "f", false, "Apply changes without confirmation")
+
+	// Setup browse command flags
+	browseCmd.Flags().BoolVar(&browseRefresh, "refresh", false, "Refresh the local contact cache before browsing")
+	browseCmd.Flags().StringVar(&browseGroup, "group", "", "Contact group to show first")
+
+	// Setup history and undo command flags
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "Maximum number of operations to list (0 = all)")
+	undoCmd.Flags().BoolVarP(&undoForce, "force", "f", false, "Undo without confirmation")
+
+	// Setup trash commands
+	trashPurgeCmd.Flags().StringVar(&trashPurgeOlderThan, "older-than", "30d", "Purge contacts deleted before this age (e.g. 30d, 2w, 12h)")
+	trashPurgeCmd.Flags().BoolVarP(&trashPurgeForce, "force", "f", false, "Purge without confirmation")
+	trashCmd.AddCommand(trashListCmd, trashRestoreCmd, trashPurgeCmd)
+
+	// Setup config commands
+	configCmd.AddCommand(configShowCmd)
+
+	// Setup auth commands
+	authCmd.AddCommand(authLoginCmd, authStatusCmd, authLogoutCmd, authRevokeCmd, authEncryptCmd, authProfilesCmd)
+	authLoginCmd.Flags().BoolVar(&authLoginDevice, "device", false, "Use the device authorization flow (enter a code on another device)")
+	authLoginCmd.Flags().BoolVar(&authLoginNoBrowser, "no-browser", false, "Print the consent URL instead of opening a browser")
+	authLoginCmd.MarkFlagsMutuallyExclusive("device", "no-browser")
+	authLoginCmd.Flags().StringVar(&authLoginScopes, "scopes", auth.DefaultScopeSet, "OAuth scope set: contacts-readonly, contacts or unified")
+	authRevokeCmd.Flags().BoolVarP(&authRevokeForce, "force", "f", false, "Revoke without confirmation")
+	authEncryptCmd.Flags().BoolVar(&authEncryptAll, "all", false, "Encrypt the tokens of every profile")
+	authEncryptCmd.Flags().BoolVar(&authEncryptDefault, "include-default", false, "Also encrypt the default profile token shared with email-manager")
+
+	// Setup export command flags
+	exportCmd.Flags().StringVar(&exportFormat, "format", "ics", "Export format (ics)")
+	exportCmd.Flags().StringVar(&exportFile, "file", "", "Output file (default: stdout)")
+	exportCmd.Flags().IntSliceVar(&exportAlarmDays, "alarm-days", nil, "Reminder in days before each event (can be repeated, 0 = on the day)")
+	exportCmd.Flags().StringVar(&exportCalendarName, "calendar-name", "Contacts birthdays", "Calendar display name")
 
 	// Register commands
 	RootCmd.AddCommand(versionCmd)
@@ -1252,5 +1495,18 @@ func Init() {
 	RootCmd.AddCommand(showCmd)
 	RootCmd.AddCommand(deleteCmd)
 	RootCmd.AddCommand(updateCmd)
+	RootCmd.AddCommand(editCmd)
 	RootCmd.AddCommand(mcpCmd)
+	RootCmd.AddCommand(exportCmd)
+	RootCmd.AddCommand(auditCmd)
+	RootCmd.AddCommand(fixCmd)
+	RootCmd.AddCommand(browseCmd)
+	RootCmd.AddCommand(historyCmd)
+	RootCmd.AddCommand(undoCmd)
+	RootCmd.AddCommand(trashCmd)
+	RootCmd.AddCommand(configCmd)
+	RootCmd.AddCommand(authCmd)
+
+	// Setup dynamic shell completion
+	registerCompletions()
 }
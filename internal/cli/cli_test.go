@@ -1,7 +1,11 @@
 // Package cli provides unit tests for CLI utilities.
 package cli
 
-import "testing"
+import (
+	"testing"
+
+	"google-contacts/internal/config"
+)
 
 func TestExtractID(t *testing.T) {
 	tests := []struct {
@@ -502,11 +506,23 @@ func TestParseEmails(t *testing.T) {
 			wantErr:    false,
 		},
 		{
-			name:       "email with colons in domain should handle correctly",
-			input:      []string{"work:user@host:port.com"},
-			wantEmails: 1,
-			wantTypes:  []string{"work"},
-			wantValues: []string{"user@host:port.com"},
+			name:        "email with colons in domain is split on first colon and rejected",
+			input:       []string{"work:user@host:port.com"},
+			wantErr:     true,
+			errContains: "'user@host:port.com'",
+		},
+		{
+			name:        "malformed email",
+			input:       []string{"john.example.com"},
+			wantErr:     true,
+			errContains: "has no @",
+		},
+		{
+			name:       "internationalized domain",
+			input:      []string{"home:jean@exemple.fr", "work:user@bücher.de"},
+			wantEmails: 2,
+			wantTypes:  []string{"home", "work"},
+			wantValues: []string{"jean@exemple.fr", "user@bücher.de"},
 			wantErr:    false,
 		},
 	}
@@ -541,3 +557,15 @@ func TestParseEmails(t *testing.T) {
 		})
 	}
 }
+
+func TestMCPConfig_CalendarFeedNeedsToken(t *testing.T) {
+	t.Setenv("CALENDAR_TOKEN", "")
+	r := config.NewResolver(nil)
+	if _, err := mcpConfig(r, config.MCP{CalendarFeed: true}); err == nil {
+		t.Error("mcpConfig() expected error for a calendar feed without token")
+	}
+	cfg, err := mcpConfig(config.NewResolver(nil), config.MCP{CalendarToken: "secret"})
+	if err != nil || !cfg.CalendarFeed {
+		t.Errorf("mcpConfig() with calendar token = %+v, %v", cfg, err)
+	}
+}
@@ -0,0 +1,272 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"google-contacts/internal/contacts"
+	"google-contacts/internal/journal"
+	"google-contacts/pkg/auth"
+)
+
+// completionTimeout bounds the API calls made while completing, so that a
+// slow network never blocks the shell.
+const completionTimeout = 5 * time.Second
+
+// completionService returns a People API service that never starts the
+// browser OAuth flow: completion fails silently when not logged in.
+func completionService(ctx context.Context) (*contacts.Service, error) {
+	return contacts.GetPeopleService(auth.WithNonInteractive(ctx))
+}
+
+// completeContactIDs completes the contact ID argument of show, update,
+// delete and edit. Candidates are IDs described by the contact name, or
+// names described by the ID, sorted by name and read from the local contact cache.
+func completeContactIDs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	list, err := completionContacts()
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return contactIDCompletions(list, toComplete), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// contactIDCompletions returns "id<TAB>name" candidates sorted by name.
+// A "people/" prefix completes full resource names, and anything that does
+// not look like an ID completes contact names, resolved by ResolveContact.
+func contactIDCompletions(list []contacts.ContactDetails, toComplete string) []cobra.Completion {
+	if !isContactIDPrefix(toComplete) {
+		return contactNameCompletions(list, toComplete)
+	}
+
+	var completions []cobra.Completion
+	for _, c := range browseFilter(list, "", "") {
+		id := extractID(c.ResourceName)
+		if strings.HasPrefix(toComplete, "people/") {
+			id = c.ResourceName
+		}
+		if !strings.HasPrefix(id, toComplete) {
+			continue
+		}
+		completions = append(completions, cobra.CompletionWithDesc(id, displayNameOrPlaceholder(c.DisplayName)))
+	}
+	return completions
+}
+
+// contactNameCompletions returns the names starting with toComplete,
+// described by their ID.
+func contactNameCompletions(list []contacts.ContactDetails, toComplete string) []cobra.Completion {
+	var completions []cobra.Completion
+	for _, c := range browseFilter(list, "", "") {
+		if c.DisplayName == "" || !strings.HasPrefix(c.DisplayName, toComplete) {
+			continue
+		}
+		completions = append(completions, cobra.CompletionWithDesc(c.DisplayName, extractID(c.ResourceName)))
+	}
+	return completions
+}
+
+// isContactIDPrefix reports whether toComplete is empty or the beginning of
+// a contact ID ("c", "c12") or resource name ("peo", "people/c1").
+func isContactIDPrefix(toComplete string) bool {
+	if strings.HasPrefix("people/", toComplete) || contacts.IsContactID(toComplete) {
+		return true
+	}
+	return toComplete == "c"
+}
+
+// completionContacts returns the cached contact list, fetching it when stale.
+func completionContacts() ([]contacts.ContactDetails, error) {
+	cache, err := contactCache()
+	if err != nil {
+		return nil, err
+	}
+	if list, ok := cache.Load(); ok {
+		return list, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	srv, err := completionService(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return srv.ListContactsCached(ctx, cache)
+}
+
+// completeGroupNames completes contact group names.
+func completeGroupNames(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	groups, err := completionGroups()
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []cobra.Completion
+	for _, g := range browsableGroups(groups) {
+		completions = append(completions, cobra.CompletionWithDesc(g.Name, fmt.Sprintf("%d contacts", g.MemberCount)))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completionGroups returns the cached contact groups, fetching them when stale.
+func completionGroups() ([]contacts.ContactGroup, error) {
+	cache, err := contactCache()
+	if err != nil {
+		return nil, err
+	}
+	if groups, ok := cache.LoadGroups(); ok {
+		return groups, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	srv, err := completionService(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return srv.ListContactGroupsCached(ctx, cache)
+}
+
+// completeTypePrefix completes the "type:" prefix of typed flag values.
+// Once a type is entered, the value itself is not completed.
+func completeTypePrefix(types []string) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if strings.Contains(toComplete, ":") {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var completions []cobra.Completion
+		for _, t := range types {
+			completions = append(completions, t+":")
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+}
+
+// completeFixTransforms completes the transform arguments of fix.
+func completeFixTransforms(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	completions := []cobra.Completion{"all"}
+	for _, t := range contacts.FixTransforms {
+		completions = append(completions, string(t))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeAuditRules completes the --rule flag of audit.
+func completeAuditRules(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	var completions []cobra.Completion
+	for _, r := range contacts.AuditRules {
+		completions = append(completions, string(r))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeOperationIDs completes the operation ID argument of undo with the
+// operations not undone yet, most recent first.
+func completeOperationIDs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	srv, err := completionService(ctx)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	_, entries, err := accountOperations(ctx, srv)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []cobra.Completion
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if journal.UndoneBy(entries, e.ID) != "" {
+			continue
+		}
+		completions = append(completions, cobra.CompletionWithDesc(e.ID, fmt.Sprintf("%s %s", e.Operation, displayNameOrPlaceholder(e.DisplayName))))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeTrashIDs completes the argument of trash restore with the deleted
+// contacts, most recent first.
+func completeTrashIDs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	store, err := contactTrash()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	items, err := store.List()
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []cobra.Completion
+	for _, item := range items {
+		completions = append(completions, cobra.CompletionWithDesc(item.ID, displayNameOrPlaceholder(item.DisplayName)))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// registerCompletions sets up dynamic completion of arguments and flag values.
+func registerCompletions() {
+	for _, cmd := range []*cobra.Command{showCmd, updateCmd, deleteCmd, editCmd} {
+		cmd.ValidArgsFunction = completeContactIDs
+	}
+	fixCmd.ValidArgsFunction = completeFixTransforms
+	undoCmd.ValidArgsFunction = completeOperationIDs
+	trashRestoreCmd.ValidArgsFunction = completeTrashIDs
+
+	flagCompletions := []struct {
+		cmd   *cobra.Command
+		flags []string
+		fn    cobra.CompletionFunc
+	}{
+		{createCmd, []string{"phone"}, completeTypePrefix(contacts.PhoneTypes)},
+		{createCmd, []string{"email"}, completeTypePrefix(contacts.EmailTypes)},
+		{createCmd, []string{"address"}, completeTypePrefix(contacts.AddressTypes)},
+		{updateCmd, []string{"phones", "add-phone"}, completeTypePrefix(contacts.PhoneTypes)},
+		{updateCmd, []string{"emails", "add-email"}, completeTypePrefix(contacts.EmailTypes)},
+		{updateCmd, []string{"addresses", "add-address"}, completeTypePrefix(contacts.AddressTypes)},
+		{browseCmd, []string{"group"}, completeGroupNames},
+		{auditCmd, []string{"rule"}, completeAuditRules},
+		{auditCmd, []string{"group-by"}, cobra.FixedCompletions([]cobra.Completion{"rule", "contact"}, cobra.ShellCompDirectiveNoFileComp)},
+		{exportCmd, []string{"format"}, cobra.FixedCompletions([]cobra.Completion{"ics"}, cobra.ShellCompDirectiveNoFileComp)},
+		{RootCmd, []string{"output"}, cobra.FixedCompletions(outputFormats, cobra.ShellCompDirectiveNoFileComp)},
+		{RootCmd, []string{"profile"}, completeProfiles},
+		{RootCmd, []string{"phone-region"}, cobra.FixedCompletions(contacts.PhoneRegions(), cobra.ShellCompDirectiveNoFileComp)},
+		{searchCmd, []string{"profiles"}, completeProfiles},
+		{authLoginCmd, []string{"scopes"}, cobra.FixedCompletions(auth.ScopeSets, cobra.ShellCompDirectiveNoFileComp)},
+	}
+	for _, fc := range flagCompletions {
+		for _, flag := range fc.flags {
+			if err := fc.cmd.RegisterFlagCompletionFunc(flag, fc.fn); err != nil {
+				panic(fmt.Sprintf("failed to register completion for --%s: %v", flag, err))
+			}
+		}
+	}
+}
+
+// completeProfiles completes the profiles found in the credentials directory.
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	profiles, err := auth.ListProfiles()
+	if err != nil {
+		cobra.CompDebugln(fmt.Sprintf("profile completion failed: %v", err), false)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return profiles, cobra.ShellCompDirectiveNoFileComp
+}
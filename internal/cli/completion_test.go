@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"google-contacts/internal/contacts"
+)
+
+func TestContactIDCompletions(t *testing.T) {
+	list := []contacts.ContactDetails{
+		{ResourceName: "people/c200", DisplayName: "Zoé Martin"},
+		{ResourceName: "people/c100", DisplayName: "Alice Durand"},
+		{ResourceName: "people/c300"},
+	}
+
+	tests := []struct {
+		name       string
+		toComplete string
+		expected   []cobra.Completion
+	}{
+		{
+			name:     "all sorted by name",
+			expected: []cobra.Completion{"c300\t(no name)", "c100\tAlice Durand", "c200\tZoé Martin"},
+		},
+		{
+			name:       "id prefix",
+			toComplete: "c2",
+			expected:   []cobra.Completion{"c200\tZoé Martin"},
+		},
+		{
+			name:       "resource name prefix",
+			toComplete: "people/c1",
+			expected:   []cobra.Completion{"people/c100\tAlice Durand"},
+		},
+		{
+			name:       "name prefix",
+			toComplete: "Zo",
+			expected:   []cobra.Completion{"Zoé Martin\tc200"},
+		},
+		{
+			name:       "no match",
+			toComplete: "x",
+			expected:   nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := contactIDCompletions(list, tc.toComplete)
+			if len(got) != len(tc.expected) {
+				t.Fatalf("contactIDCompletions() = %q, want %q", got, tc.expected)
+			}
+			for i := range got {
+				if got[i] != tc.expected[i] {
+					t.Errorf("contactIDCompletions()[%d] = %q, want %q", i, got[i], tc.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCompleteTypePrefix(t *testing.T) {
+	complete := completeTypePrefix(contacts.EmailTypes)
+
+	got, directive := complete(nil, nil, "")
+	if len(got) != 3 || got[0] != "work:" {
+		t.Errorf("completeTypePrefix() = %q, want type prefixes", got)
+	}
+	if directive&cobra.ShellCompDirectiveNoSpace == 0 {
+		t.Error("type prefixes should be completed without a trailing space")
+	}
+
+	if got, _ := complete(nil, nil, "work:jo"); got != nil {
+		t.Errorf("completeTypePrefix() after type = %q, want nil", got)
+	}
+}
+
+func TestFindGroup(t *testing.T) {
+	groups := []contacts.ContactGroup{{Name: "Équipe"}, {Name: "Family"}}
+	if got := findGroup(groups, "equipe"); got != 0 {
+		t.Errorf("findGroup(equipe) = %d, want 0", got)
+	}
+	if got := findGroup(groups, "work"); got != -1 {
+		t.Errorf("findGroup(work) = %d, want -1", got)
+	}
+}
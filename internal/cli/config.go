@@ -0,0 +1,366 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"google-contacts/internal/config"
+	"google-contacts/internal/contacts"
+	mcpserver "google-contacts/internal/mcp"
+	"google-contacts/pkg/auth"
+)
+
+// Global configuration flags
+var (
+	configFile     string
+	profileName    string
+	phoneRegion    string
+	credentialsDir string
+	impersonate    string
+)
+
+// Resolved configuration, set by loadConfig before each command
+var (
+	userConfig     = &config.Config{}
+	userConfigPath string
+	lastNameCase   = contacts.LastNamePreserve
+	tokenKeyFile   string
+	configSettings []config.Setting
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show the configuration",
+	Long: `google-contacts reads its defaults from a YAML configuration file, by default
+` + "`" + `<user config dir>/google-contacts/config.yaml` + "`" + ` (~/.config/google-contacts/config.yaml
+on Linux). Use --config or GOOGLE_CONTACTS_CONFIG to read another file.
+
+Each setting is taken from the first of: command-line flag, environment
+variable, configuration file, built-in default.
+
+Example configuration:
+
+  profile: work              # Default account profile (see 'auth profiles')
+  output: table              # table, json, yaml, csv or vcard
+  phoneRegion: FR            # Region of national numbers (0612345678 → +33612345678)
+  phoneType: mobile          # Type of phones given without one
+  emailType: work            # Type of emails given without one
+  addressType: home          # Type of addresses given without one
+  lastNameCase: preserve     # preserve, upper or title
+  credentialsDir: ~/.credentials
+  scopes: contacts           # OAuth scope set: contacts-readonly, contacts or unified
+  tokenStore: encrypted      # file, encrypted or env (see 'auth encrypt')
+  tokenKeyFile: ~/.credentials/token.key  # Encryption key (passphrase if unset)
+  serviceAccount: ~/.credentials/sa.json  # Domain-wide delegation key (see --impersonate)
+  impersonate: jane@example.com           # Workspace user the service account acts as
+  mcp:
+    host: localhost
+    port: 8080
+    baseUrl: https://contacts.example.com
+    secretName: google-contacts-oauth
+    secretProject: my-project
+    credentialFile: /path/to/credentials.json
+    calendarFeed: true
+    calendarToken: my-secret-token
+    calendarAlarmDays: [1, 7]
+    lastNameCase: upper
+    scopes: contacts-readonly
+    serviceAccount: /path/to/sa.json       # Act as each caller (domain-wide delegation)
+    impersonateDomain: example.com         # Only serve the users of this domain
+    oauthStore: firestore                  # memory, file or firestore
+    oauthStorePath: ~/.config/google-contacts/mcp-oauth.json  # With the file store
+    firestoreProject: my-project
+    firestoreDatabase: google-contacts`,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the effective configuration and where each value comes from",
+	Example: `  google-contacts config show
+  google-contacts config show --config ./work.yaml -o json`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{outputFormatsAnnotation: "json,yaml,csv"},
+	RunE:        runConfigShow,
+}
+
+// configOutput is the config show document.
+type configOutput struct {
+	File     string           `json:"file"`
+	Found    bool             `json:"found"`
+	Settings []config.Setting `json:"settings"`
+}
+
+// Setting sources of the global configuration
+var (
+	profileSpec        = config.Spec{Key: "profile", Flag: "profile", Envs: []string{"GOOGLE_CONTACTS_PROFILE"}}
+	outputSpec         = config.Spec{Key: "output", Flag: "output", Envs: []string{"GOOGLE_CONTACTS_OUTPUT"}}
+	phoneRegionSpec    = config.Spec{Key: "phoneRegion", Flag: "phone-region", Envs: []string{"GOOGLE_CONTACTS_PHONE_REGION"}}
+	phoneTypeSpec      = config.Spec{Key: "phoneType", Envs: []string{"GOOGLE_CONTACTS_PHONE_TYPE"}}
+	emailTypeSpec      = config.Spec{Key: "emailType", Envs: []string{"GOOGLE_CONTACTS_EMAIL_TYPE"}}
+	addressTypeSpec    = config.Spec{Key: "addressType", Envs: []string{"GOOGLE_CONTACTS_ADDRESS_TYPE"}}
+	lastNameCaseSpec   = config.Spec{Key: "lastNameCase", Envs: []string{"GOOGLE_CONTACTS_LAST_NAME_CASE"}}
+	credentialsDirSpec = config.Spec{Key: "credentialsDir", Flag: "credentials-dir", Envs: []string{"GOOGLE_CONTACTS_CREDENTIALS_DIR"}}
+	scopesSpec         = config.Spec{Key: "scopes", Flag: "scopes", Envs: []string{"GOOGLE_CONTACTS_SCOPES"}}
+	tokenStoreSpec     = config.Spec{Key: "tokenStore", Envs: []string{"GOOGLE_CONTACTS_TOKEN_STORE"}}
+	tokenKeyFileSpec   = config.Spec{Key: "tokenKeyFile", Envs: []string{"GOOGLE_CONTACTS_TOKEN_KEY_FILE"}}
+	serviceAccountSpec = config.Spec{Key: "serviceAccount", Envs: []string{"GOOGLE_CONTACTS_SERVICE_ACCOUNT"}}
+	impersonateSpec    = config.Spec{Key: "impersonate", Flag: "impersonate", Envs: []string{"GOOGLE_CONTACTS_IMPERSONATE"}}
+)
+
+// configFilePath returns the configuration file to read and whether it must
+// exist (explicitly selected with --config or GOOGLE_CONTACTS_CONFIG).
+func configFilePath(cmd *cobra.Command) (string, bool, error) {
+	if cmd.Flags().Changed("config") {
+		return configFile, true, nil
+	}
+	if path := os.Getenv(config.EnvConfig); path != "" {
+		return path, true, nil
+	}
+	path, err := config.DefaultPath()
+	return path, false, err
+}
+
+// loadConfig reads the configuration file and applies the resolved settings.
+func loadConfig(cmd *cobra.Command) error {
+	path, required, err := configFilePath(cmd)
+	if err != nil {
+		return err
+	}
+	c, err := config.Load(expandHome(path), required)
+	if err != nil {
+		return err
+	}
+
+	r := config.NewResolver(cmd.Flags())
+	if err := auth.SetProfile(r.String(profileSpec, c.Profile, auth.DefaultProfile)); err != nil {
+		return err
+	}
+	outputFormat = r.String(outputSpec, c.Output, outputTable)
+
+	if err := contacts.SetPhoneRegion(r.String(phoneRegionSpec, c.PhoneRegion, contacts.DefaultPhoneRegion)); err != nil {
+		return err
+	}
+
+	types := []struct {
+		spec   config.Spec
+		file   string
+		def    string
+		target *string
+		valid  []string
+	}{
+		{phoneTypeSpec, c.PhoneType, "mobile", &contacts.DefaultPhoneType, contacts.PhoneTypes},
+		{emailTypeSpec, c.EmailType, "work", &contacts.DefaultEmailType, contacts.EmailTypes},
+		{addressTypeSpec, c.AddressType, "home", &contacts.DefaultAddressType, contacts.AddressTypes},
+		{lastNameCaseSpec, c.LastNameCase, contacts.LastNamePreserve, &lastNameCase, contacts.LastNameCases},
+	}
+	for _, t := range types {
+		value := strings.ToLower(r.String(t.spec, t.file, t.def))
+		if err := config.CheckChoice(t.spec.Key, value, t.valid); err != nil {
+			return err
+		}
+		*t.target = value
+	}
+
+	auth.SetCredentialsPath("")
+	dir := r.String(credentialsDirSpec, c.CredentialsDir, auth.GetCredentialsPath())
+	auth.SetCredentialsPath(expandHome(dir))
+
+	scopes := strings.ToLower(r.String(scopesSpec, c.Scopes, auth.DefaultScopeSet))
+	if err := config.CheckChoice(scopesSpec.Key, scopes, auth.ScopeSets); err != nil {
+		return err
+	}
+	_ = auth.SetScopeSet(scopes)
+
+	store := strings.ToLower(r.String(tokenStoreSpec, c.TokenStore, auth.StoreFile))
+	if err := config.CheckChoice(tokenStoreSpec.Key, store, auth.TokenStores); err != nil {
+		return err
+	}
+	tokenKeyFile = expandHome(r.String(tokenKeyFileSpec, c.TokenKeyFile, ""))
+	auth.SetTokenStore(newTokenStore(store, tokenKeyFile))
+
+	// The service account key is only read when a user is impersonated
+	var sa *auth.ServiceAccount
+	user := r.String(impersonateSpec, c.Impersonate, "")
+	if saFile := expandHome(r.String(serviceAccountSpec, c.ServiceAccount, "")); user != "" && saFile != "" {
+		if sa, err = auth.LoadServiceAccount(saFile); err != nil {
+			return err
+		}
+	}
+	if err := auth.SetImpersonation(sa, user); err != nil {
+		return err
+	}
+
+	userConfig, userConfigPath, configSettings = c, path, r.Settings()
+	return nil
+}
+
+// applyConfig loads the configuration before each command, then checks the
+// output format. A default format from the environment or the file that the
+// command does not support falls back to table instead of failing.
+func applyConfig(cmd *cobra.Command, args []string) error {
+	if err := loadConfig(cmd); err != nil {
+		return err
+	}
+	if !cmd.Flags().Changed("output") && outputFormat != outputTable && slices.Contains(outputFormats, outputFormat) &&
+		!slices.Contains(strings.Split(cmd.Annotations[outputFormatsAnnotation], ","), outputFormat) {
+		outputFormat = outputTable
+	}
+	return validateOutputFormat(cmd, args)
+}
+
+// MCP server setting sources
+var (
+	mcpHostSpec          = config.Spec{Key: "mcp.host", Flag: "host", Envs: []string{"HOST"}}
+	mcpPortSpec          = config.Spec{Key: "mcp.port", Flag: "port", Envs: []string{"PORT"}}
+	mcpBaseURLSpec       = config.Spec{Key: "mcp.baseUrl", Flag: "base-url", Envs: []string{"BASE_URL"}}
+	mcpSecretNameSpec    = config.Spec{Key: "mcp.secretName", Flag: "secret-name", Envs: []string{"SECRET_NAME"}}
+	mcpSecretProjectSpec = config.Spec{Key: "mcp.secretProject", Flag: "secret-project", Envs: []string{"SECRET_PROJECT", "PROJECT_ID"}}
+	mcpCredFileSpec      = config.Spec{Key: "mcp.credentialFile", Flag: "credential-file"}
+	mcpCalendarFeedSpec  = config.Spec{Key: "mcp.calendarFeed", Flag: "calendar-feed"}
+	mcpCalendarTokenSpec = config.Spec{Key: "mcp.calendarToken", Flag: "calendar-token", Envs: []string{"CALENDAR_TOKEN"}, Secret: true}
+	mcpCalendarAlarmSpec = config.Spec{Key: "mcp.calendarAlarmDays", Flag: "calendar-alarm-days"}
+	mcpLastNameCaseSpec  = config.Spec{Key: "mcp.lastNameCase", Envs: []string{"GOOGLE_CONTACTS_MCP_LAST_NAME_CASE"}}
+	mcpScopesSpec        = config.Spec{Key: "mcp.scopes", Envs: []string{"GOOGLE_CONTACTS_MCP_SCOPES"}}
+	mcpServiceAcctSpec   = config.Spec{Key: "mcp.serviceAccount", Flag: "service-account", Envs: []string{"SERVICE_ACCOUNT_FILE"}}
+	mcpImpersonateSpec   = config.Spec{Key: "mcp.impersonateDomain", Flag: "impersonate-domain", Envs: []string{"IMPERSONATE_DOMAIN"}}
+	mcpOAuthStoreSpec    = config.Spec{Key: "mcp.oauthStore", Flag: "oauth-store", Envs: []string{"OAUTH_STORE"}}
+	mcpStorePathSpec     = config.Spec{Key: "mcp.oauthStorePath", Flag: "oauth-store-path", Envs: []string{"OAUTH_STORE_PATH"}}
+	mcpKeyFileSpec       = config.Spec{Key: "mcp.oauthKeyFile", Flag: "oauth-key-file", Envs: []string{"OAUTH_KEY_FILE"}}
+	mcpFirestoreProjSpec = config.Spec{Key: "mcp.firestoreProject", Envs: []string{"FIRESTORE_PROJECT", "PROJECT_ID"}}
+	mcpFirestoreDBSpec   = config.Spec{Key: "mcp.firestoreDatabase", Envs: []string{"FIRESTORE_DATABASE"}}
+)
+
+// mcpConfig resolves the MCP server configuration from the mcp command
+// flags, the environment and the configuration file.
+func mcpConfig(r *config.Resolver, c config.MCP) (*mcpserver.Config, error) {
+	cfg := &mcpserver.Config{Host: r.String(mcpHostSpec, c.Host, "localhost")}
+	var err error
+	if cfg.Port, err = r.Int(mcpPortSpec, c.Port, 8080); err != nil {
+		return nil, err
+	}
+	cfg.BaseURL = r.String(mcpBaseURLSpec, c.BaseURL, "")
+	cfg.SecretName = r.String(mcpSecretNameSpec, c.SecretName, "")
+	cfg.SecretProject = r.String(mcpSecretProjectSpec, c.SecretProject, "")
+	cfg.CredentialFile = expandHome(r.String(mcpCredFileSpec, c.CredentialFile, ""))
+	if cfg.CalendarFeed, err = r.Bool(mcpCalendarFeedSpec, c.CalendarFeed, false); err != nil {
+		return nil, err
+	}
+	cfg.CalendarToken = r.String(mcpCalendarTokenSpec, c.CalendarToken, "")
+	cfg.CalendarFeed = cfg.CalendarFeed || cfg.CalendarToken != ""
+	if cfg.CalendarFeed && cfg.CalendarToken == "" {
+		// A generated token would change the feed URL on every restart
+		return nil, fmt.Errorf("%s needs %s", mcpCalendarFeedSpec.Key, mcpCalendarTokenSpec.Key)
+	}
+	if cfg.CalendarAlarmDays, err = r.Ints(mcpCalendarAlarmSpec, c.CalendarAlarmDays, nil); err != nil {
+		return nil, err
+	}
+	cfg.LastNameCase = strings.ToLower(r.String(mcpLastNameCaseSpec, c.LastNameCase, contacts.LastNameUpper))
+	if err := config.CheckChoice(mcpLastNameCaseSpec.Key, cfg.LastNameCase, contacts.LastNameCases); err != nil {
+		return nil, err
+	}
+	cfg.ScopeSet = strings.ToLower(r.String(mcpScopesSpec, c.Scopes, auth.ScopeSetContacts))
+	if err := config.CheckChoice(mcpScopesSpec.Key, cfg.ScopeSet, auth.ScopeSets); err != nil {
+		return nil, err
+	}
+	cfg.ServiceAccountFile = expandHome(r.String(mcpServiceAcctSpec, c.ServiceAccount, ""))
+	cfg.ImpersonateDomain = strings.ToLower(r.String(mcpImpersonateSpec, c.ImpersonateDomain, ""))
+	if cfg.ImpersonateDomain != "" && cfg.ServiceAccountFile == "" {
+		return nil, fmt.Errorf("%s needs %s", mcpImpersonateSpec.Key, mcpServiceAcctSpec.Key)
+	}
+	cfg.OAuthStore = strings.ToLower(r.String(mcpOAuthStoreSpec, c.OAuthStore, mcpserver.StoreFile))
+	if err := config.CheckChoice(mcpOAuthStoreSpec.Key, cfg.OAuthStore, mcpserver.OAuthStores); err != nil {
+		return nil, err
+	}
+	defaultStorePath, _ := mcpserver.DefaultFileStorePath()
+	cfg.OAuthStorePath = expandHome(r.String(mcpStorePathSpec, c.OAuthStorePath, defaultStorePath))
+	defaultKeyFile := ""
+	if cfg.OAuthStore == mcpserver.StoreFile {
+		defaultKeyFile, _ = mcpserver.DefaultKeyFilePath()
+	}
+	cfg.OAuthKeyFile = expandHome(r.String(mcpKeyFileSpec, c.OAuthKeyFile, defaultKeyFile))
+	cfg.FirestoreProject = r.String(mcpFirestoreProjSpec, c.FirestoreProject, "")
+	cfg.FirestoreDatabase = r.String(mcpFirestoreDBSpec, c.FirestoreDatabase, "(default)")
+	if cfg.OAuthStore == mcpserver.StoreFirestore && cfg.FirestoreProject == "" {
+		return nil, fmt.Errorf("%s %s needs %s", mcpOAuthStoreSpec.Key, cfg.OAuthStore, mcpFirestoreProjSpec.Key)
+	}
+	if cfg.OAuthStore == mcpserver.StoreFirestore && cfg.OAuthKeyFile == "" {
+		return nil, fmt.Errorf("%s %s needs %s", mcpOAuthStoreSpec.Key, cfg.OAuthStore, mcpKeyFileSpec.Key)
+	}
+	return cfg, nil
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	// The MCP settings have no flags outside the mcp command
+	r := config.NewResolver(nil)
+	if _, err := mcpConfig(r, userConfig.MCP); err != nil {
+		return err
+	}
+
+	out := configOutput{File: userConfigPath, Settings: slices.Concat(configSettings, r.Settings())}
+	if _, err := os.Stat(expandHome(userConfigPath)); err == nil {
+		out.Found = true
+	}
+	for i, s := range out.Settings {
+		if s.Secret && s.Value != "" {
+			out.Settings[i].Value = "********"
+		}
+	}
+
+	return commandOutput{
+		table: func() { displayConfig(out) },
+		data:  out,
+		csv: func() ([]string, [][]string) {
+			var rows [][]string
+			for _, s := range out.Settings {
+				rows = append(rows, []string{s.Key, s.Value, string(s.Source), s.Origin})
+			}
+			return []string{"key", "value", "source", "origin"}, rows
+		},
+	}.write()
+}
+
+// displayConfig prints the effective settings with their source.
+func displayConfig(out configOutput) {
+	cyan := color.New(color.FgCyan).SprintFunc()
+	faint := color.New(color.Faint).SprintFunc()
+
+	status := "not found, using defaults"
+	if out.Found {
+		status = "loaded"
+	}
+	fmt.Printf("Config file: %s (%s)\n\n", out.File, status)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, s := range out.Settings {
+		value := s.Value
+		if value == "" {
+			value = "-"
+		}
+		source := string(s.Source)
+		if s.Origin != "" {
+			source += " (" + s.Origin + ")"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", cyan(s.Key), value, faint(source))
+	}
+	w.Flush()
+}
+
+// expandHome replaces a leading "~/" with the user home directory.
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
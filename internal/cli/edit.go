@@ -0,0 +1,399 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"google-contacts/internal/contacts"
+	"google-contacts/internal/journal"
+)
+
+// Edit command flags
+var editForce bool
+
+var editCmd = &cobra.Command{
+	Use:   "edit <contact>",
+	Short: "Edit a contact in your text editor",
+	Long: `Open a contact as a YAML document in your editor and apply the changes.
+
+The editor is taken from $VISUAL, then $EDITOR (default: vi, or notepad on
+Windows). After saving and closing the editor, the changes are validated and
+shown as a field-level diff before being applied. Only modified fields are
+sent to Google.
+
+If the document is invalid, the editor is reopened with the error at the
+top of the file. Leaving the document unchanged, or emptying it, cancels
+the edit.
+
+The contact can be:
+  - Full resource name: people/c123456789
+  - Just the ID: c123456789
+  - A name, email or phone number: "Jane Doe", jane@example.com, 0612345678
+    (when several contacts match, you are asked to choose one; without a
+    terminal the command fails and lists the candidates)`,
+	Example: `  # Edit a contact
+  google-contacts edit c123456789
+  google-contacts edit "Jane Doe"
+
+  # Use a specific editor and skip the confirmation
+  EDITOR=nano google-contacts edit c123456789 --force`,
+	Args: cobra.ExactArgs(1),
+	RunE: runEdit,
+}
+
+// editDocument is the YAML document edited by the user.
+type editDocument struct {
+	FirstName string      `yaml:"firstName"`
+	LastName  string      `yaml:"lastName"`
+	Phones    []editEntry `yaml:"phones"`
+	Emails    []editEntry `yaml:"emails"`
+	Addresses []editEntry `yaml:"addresses"`
+	Company   string      `yaml:"company"`
+	Position  string      `yaml:"position"`
+	Birthday  string      `yaml:"birthday"`
+	Notes     string      `yaml:"notes"`
+}
+
+// editEntry is a typed value (phone, email or address) of an edit document.
+type editEntry struct {
+	Type  string `yaml:"type"`
+	Value string `yaml:"value"`
+}
+
+// editFieldComments documents the fields of the edit document.
+var editFieldComments = map[string]string{
+	"phones":    "Types: mobile, work, home, main, other",
+	"emails":    "Types: work, home, other",
+	"addresses": "Types: home, work, other. Value: \"street, postal code city, country\"",
+	"birthday":  "YYYY-MM-DD, or --MM-DD when the year is unknown. Empty removes it",
+}
+
+// editErrorPrefix marks the error comment added when the editor is reopened.
+const editErrorPrefix = "# ERROR: "
+
+func runEdit(cmd *cobra.Command, args []string) error {
+	contactID := args[0]
+	ctx := context.Background()
+
+	// Get People API service
+	srv, err := contacts.GetPeopleService(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize service: %w", err)
+	}
+
+	contactID, err = resolveContact(ctx, srv, contactID)
+	if err != nil {
+		return err
+	}
+
+	before, err := srv.GetContactDetails(ctx, contactID)
+	if err != nil {
+		return err
+	}
+
+	original, err := renderEditDocument(before)
+	if err != nil {
+		return err
+	}
+
+	// Edit until the document is valid, unchanged or the user gives up
+	text := original
+	var input *contacts.UpdateInput
+	var changes []contacts.FixChange
+	for {
+		edited, err := editInEditor(text)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(stripEditError(edited), original) || isBlankDocument(edited) {
+			fmt.Println("Edit cancelled, no changes.")
+			return nil
+		}
+
+		doc, err := parseEditDocument(edited)
+		if err == nil {
+			input, changes, err = editUpdateInput(before, doc)
+		}
+		if err == nil {
+			break
+		}
+
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if !confirm("Reopen the editor? (Y/n): ", true) {
+			return fmt.Errorf("edit aborted: %w", err)
+		}
+		text = withEditError(edited, err)
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("No changes.")
+		return nil
+	}
+
+	// Show the diff and confirm
+	cyan := color.New(color.FgCyan).SprintFunc()
+	fmt.Printf("%s (%s)\n", cyan(displayNameOrPlaceholder(before.DisplayName)), extractID(before.ResourceName))
+	displayFieldChanges(changes)
+	fmt.Println()
+	if !editForce && !confirm("Apply these changes? (y/N): ", false) {
+		fmt.Println("Edit cancelled.")
+		return nil
+	}
+
+	after, err := srv.UpdateContact(ctx, before.ResourceName, *input)
+	if err != nil {
+		return err
+	}
+	invalidateContactCache()
+	recordOperation(ctx, srv, "edit", journal.OpUpdate, before, after)
+
+	green := color.New(color.FgGreen).SprintFunc()
+	fmt.Printf("%s Contact '%s' has been updated.\n", green("✓"), after.DisplayName)
+	return nil
+}
+
+// confirm asks a yes/no question on the terminal.
+func confirm(prompt string, defaultYes bool) bool {
+	fmt.Print(prompt)
+	var response string
+	fmt.Scanln(&response)
+
+	response = strings.ToLower(strings.TrimSpace(response))
+	if response == "" {
+		return defaultYes
+	}
+	return response == "y" || response == "yes"
+}
+
+// renderEditDocument renders a contact as a commented YAML document.
+func renderEditDocument(c *contacts.ContactDetails) ([]byte, error) {
+	doc := editDocument{
+		FirstName: c.FirstName,
+		LastName:  c.LastName,
+		Company:   c.Company,
+		Position:  c.Position,
+		Birthday:  c.Birthday,
+		Notes:     c.Notes,
+		Phones:    []editEntry{},
+		Emails:    []editEntry{},
+		Addresses: []editEntry{},
+	}
+	for _, p := range c.Phones {
+		doc.Phones = append(doc.Phones, editEntry{Type: p.Type, Value: p.Value})
+	}
+	for _, e := range c.Emails {
+		doc.Emails = append(doc.Emails, editEntry{Type: e.Type, Value: e.Value})
+	}
+	for _, a := range c.Addresses {
+		doc.Addresses = append(doc.Addresses, editEntry{Type: a.Type, Value: a.Value})
+	}
+
+	var node yaml.Node
+	if err := node.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to encode contact: %w", err)
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		key := node.Content[i]
+		key.HeadComment = editFieldComments[key.Value]
+	}
+	node.HeadComment = fmt.Sprintf(`Editing %s (%s)
+
+Change the fields below, then save and close the editor to review the changes.
+Lines starting with '#' are ignored. Leave the file unchanged or empty to cancel.`,
+		displayNameOrPlaceholder(c.DisplayName), extractID(c.ResourceName))
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, fmt.Errorf("failed to encode contact: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// parseEditDocument decodes an edited document, rejecting unknown fields.
+func parseEditDocument(data []byte) (*editDocument, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	var doc editDocument
+	if err := dec.Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	return &doc, nil
+}
+
+// editUpdateInput validates an edited document and compares it with the
+// contact. It returns the update for the changed fields only, and the
+// field-level changes for display.
+func editUpdateInput(c *contacts.ContactDetails, doc *editDocument) (*contacts.UpdateInput, []contacts.FixChange, error) {
+	input := &contacts.UpdateInput{}
+	var changes []contacts.FixChange
+
+	setString := func(field, value, current string, target **string) {
+		value = strings.TrimSpace(value)
+		if value != strings.TrimSpace(current) {
+			*target = &value
+			changes = append(changes, contacts.FixChange{Field: field, Before: current, After: value})
+		}
+	}
+	setString("firstName", doc.FirstName, c.FirstName, &input.FirstName)
+	setString("lastName", doc.LastName, c.LastName, &input.LastName)
+	setString("company", doc.Company, c.Company, &input.Company)
+	setString("position", doc.Position, c.Position, &input.Position)
+	setString("notes", doc.Notes, c.Notes, &input.Notes)
+
+	birthday := strings.TrimSpace(doc.Birthday)
+	if birthday != c.Birthday {
+		if err := contacts.ValidateBirthday(birthday); err != nil {
+			return nil, nil, err
+		}
+		if birthday == "" {
+			input.ClearBirthday = true
+		} else {
+			input.Birthday = &birthday
+		}
+		changes = append(changes, contacts.FixChange{Field: "birthday", Before: c.Birthday, After: birthday})
+	}
+
+	// Typed lists are validated with the flag parsers, and replaced as a whole when changed
+	if before, after := contacts.PhoneValues(c.Phones), editEntryValues(doc.Phones); !slices.Equal(before, after) {
+		phones, err := parsePhones(after)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid phones: %w", err)
+		}
+		input.Phones = phones
+		changes = append(changes, contacts.ListChanges("phones", before, after)...)
+	}
+	if before, after := contacts.EmailValues(c.Emails), editEntryValues(doc.Emails); !slices.Equal(before, after) {
+		emails, err := parseEditedEmails(after, c.Emails)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid emails: %w", err)
+		}
+		input.Emails = emails
+		changes = append(changes, contacts.ListChanges("emails", before, after)...)
+	}
+	if before, after := contacts.AddressValues(c.Addresses), editEntryValues(doc.Addresses); !slices.Equal(before, after) {
+		for _, a := range doc.Addresses {
+			if t := strings.ToLower(strings.TrimSpace(a.Type)); t != "" && !slices.Contains(contacts.AddressTypes, t) {
+				return nil, nil, fmt.Errorf("invalid addresses: invalid address type '%s', valid types: %s", a.Type, strings.Join(contacts.AddressTypes, ", "))
+			}
+		}
+		addresses, err := parseAddresses(after)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid addresses: %w", err)
+		}
+		input.Addresses = addresses
+		changes = append(changes, contacts.ListChanges("addresses", before, after)...)
+	}
+
+	return input, changes, nil
+}
+
+// editEntryValues converts edited entries to the "type:value" flag syntax.
+// Entries without type use the flag parser default.
+func editEntryValues(entries []editEntry) []string {
+	var values []string
+	for _, e := range entries {
+		value := strings.TrimSpace(e.Value)
+		if t := strings.TrimSpace(e.Type); t != "" {
+			value = t + ":" + value
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// editInEditor writes text to a temporary file, opens it in the user's
+// editor and returns the saved content.
+func editInEditor(text []byte) ([]byte, error) {
+	f, err := os.CreateTemp("", "google-contacts-*.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	path := f.Name()
+	defer os.Remove(path)
+
+	if _, err := f.Write(text); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	editor := editorCommand()
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("editor '%s' failed: %w", strings.Join(editor, " "), err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read edited file: %w", err)
+	}
+	return data, nil
+}
+
+// editorCommand returns the user's editor command with its arguments.
+func editorCommand() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// withEditError puts an error comment at the top of the document.
+func withEditError(text []byte, err error) []byte {
+	var buf bytes.Buffer
+	for _, line := range strings.Split(err.Error(), "\n") {
+		buf.WriteString(editErrorPrefix + line + "\n")
+	}
+	buf.WriteString("# Fix the error and save again, or empty the file to cancel.\n")
+	buf.Write(stripEditError(text))
+	return buf.Bytes()
+}
+
+// stripEditError removes the error comment added by withEditError.
+func stripEditError(text []byte) []byte {
+	lines := strings.SplitAfter(string(text), "\n")
+	i := 0
+	for i < len(lines) && strings.HasPrefix(lines[i], editErrorPrefix) {
+		i++
+	}
+	if i > 0 && i < len(lines) && strings.HasPrefix(lines[i], "# Fix the error") {
+		i++
+	}
+	return []byte(strings.Join(lines[i:], ""))
+}
+
+// isBlankDocument reports whether a document only contains comments and blank lines.
+func isBlankDocument(text []byte) bool {
+	for _, line := range strings.Split(string(text), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+	return true
+}
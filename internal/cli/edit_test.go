@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"google-contacts/internal/contacts"
+)
+
+func editTestContact() *contacts.ContactDetails {
+	return &contacts.ContactDetails{
+		ResourceName: "people/c123",
+		FirstName:    "Jane",
+		LastName:     "DOE",
+		DisplayName:  "Jane DOE",
+		Phones:       []contacts.PhoneEntry{{Value: "+33612345678", Type: "mobile"}},
+		Emails:       []contacts.EmailEntry{{Value: "jane@example.com", Type: "work"}},
+		Addresses:    []contacts.AddressEntry{{Value: "10 Rue Example, 75001 Paris, France", Type: "home"}},
+		Company:      "Acme",
+		Birthday:     "--03-15",
+		Notes:        "Line 1\nLine 2",
+	}
+}
+
+func TestRenderEditDocument_RoundTrip(t *testing.T) {
+	c := editTestContact()
+	text, err := renderEditDocument(c)
+	if err != nil {
+		t.Fatalf("renderEditDocument() error: %v", err)
+	}
+
+	for _, want := range []string{"# Editing Jane DOE (c123)", "# Types: mobile, work, home, main, other\nphones:", "birthday: --03-15"} {
+		if !strings.Contains(string(text), want) {
+			t.Errorf("rendered document missing %q:\n%s", want, text)
+		}
+	}
+
+	doc, err := parseEditDocument(text)
+	if err != nil {
+		t.Fatalf("parseEditDocument() error: %v", err)
+	}
+	_, changes, err := editUpdateInput(c, doc)
+	if err != nil {
+		t.Fatalf("editUpdateInput() error: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("unchanged document produced changes: %+v", changes)
+	}
+}
+
+func TestEditUpdateInput(t *testing.T) {
+	c := editTestContact()
+	doc := &editDocument{
+		FirstName: "Jane",
+		LastName:  "DOE",
+		Phones:    []editEntry{{Type: "mobile", Value: "+33612345678"}, {Type: "work", Value: "+33100000000"}},
+		Emails:    []editEntry{{Type: "work", Value: "jane@example.com"}},
+		Addresses: []editEntry{{Type: "home", Value: "10 Rue Example, 75001 Paris, France"}},
+		Company:   "New Corp",
+		Notes:     "Line 1\nLine 2\n",
+	}
+
+	input, changes, err := editUpdateInput(c, doc)
+	if err != nil {
+		t.Fatalf("editUpdateInput() error: %v", err)
+	}
+	if input.Company == nil || *input.Company != "New Corp" {
+		t.Errorf("Company = %v, want New Corp", input.Company)
+	}
+	if input.FirstName != nil || input.Notes != nil || input.Emails != nil || input.Addresses != nil {
+		t.Errorf("unchanged fields should not be updated: %+v", input)
+	}
+	if !input.ClearBirthday {
+		t.Error("ClearBirthday = false, want true")
+	}
+	if len(input.Phones) != 2 {
+		t.Errorf("Phones = %+v, want 2 phones", input.Phones)
+	}
+
+	expected := []contacts.FixChange{
+		{Field: "company", Before: "Acme", After: "New Corp"},
+		{Field: "birthday", Before: "--03-15"},
+		{Field: "phones", After: "work:+33100000000"},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("changes = %+v, want %+v", changes, expected)
+	}
+	for i, want := range expected {
+		if changes[i] != want {
+			t.Errorf("changes[%d] = %+v, want %+v", i, changes[i], want)
+		}
+	}
+}
+
+func TestEditUpdateInput_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		edit        func(d *editDocument)
+		errContains string
+	}{
+		{name: "birthday", edit: func(d *editDocument) { d.Birthday = "15/03/1985" }, errContains: "invalid birthday"},
+		{name: "email", edit: func(d *editDocument) { d.Emails = []editEntry{{Type: "work", Value: "jane@"}} }, errContains: "invalid emails"},
+		{name: "phone type", edit: func(d *editDocument) { d.Phones = []editEntry{{Type: "fax", Value: "+331"}} }, errContains: "invalid phone type"},
+		{name: "empty phone", edit: func(d *editDocument) { d.Phones = []editEntry{{Type: "mobile"}} }, errContains: "cannot be empty"},
+		{name: "address type", edit: func(d *editDocument) { d.Addresses = []editEntry{{Type: "office", Value: "Paris"}} }, errContains: "invalid address type"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := editTestContact()
+			text, _ := renderEditDocument(c)
+			doc, _ := parseEditDocument(text)
+			tc.edit(doc)
+			_, _, err := editUpdateInput(c, doc)
+			if err == nil || !strings.Contains(err.Error(), tc.errContains) {
+				t.Errorf("editUpdateInput() error = %v, want error containing %q", err, tc.errContains)
+			}
+		})
+	}
+}
+
+func TestEditUpdateInput_ExistingMalformedEmail(t *testing.T) {
+	c := editTestContact()
+	c.Emails = []contacts.EmailEntry{{Value: "jane@", Type: "work"}}
+	text, _ := renderEditDocument(c)
+	doc, _ := parseEditDocument(text)
+	doc.Emails = append(doc.Emails, editEntry{Type: "home", Value: "jane@example.com"})
+
+	input, _, err := editUpdateInput(c, doc)
+	if err != nil {
+		t.Fatalf("editUpdateInput() error: %v", err)
+	}
+	if len(input.Emails) != 2 || input.Emails[0].Value != "jane@" {
+		t.Errorf("Emails = %+v, want the kept and the new email", input.Emails)
+	}
+}
+
+func TestParseEditDocument_UnknownField(t *testing.T) {
+	if _, err := parseEditDocument([]byte("firstName: Jane\nnickname: JJ\n")); err == nil {
+		t.Error("parseEditDocument() expected error for unknown field")
+	}
+}
+
+func TestEditError(t *testing.T) {
+	text := []byte("# Editing\nfirstName: Jane\n")
+	withError := withEditError(text, errors.New("invalid birthday"))
+	if !strings.HasPrefix(string(withError), "# ERROR: invalid birthday\n") {
+		t.Errorf("withEditError() = %q", withError)
+	}
+
+	// A second error replaces the first one
+	again := withEditError(withError, errors.New("invalid emails"))
+	if strings.Contains(string(again), "invalid birthday") || string(stripEditError(again)) != string(text) {
+		t.Errorf("withEditError() did not replace the previous error: %q", again)
+	}
+}
+
+func TestIsBlankDocument(t *testing.T) {
+	if !isBlankDocument([]byte("# comment\n\n  # indented\n")) {
+		t.Error("isBlankDocument() = false for comments only")
+	}
+	if isBlankDocument([]byte("# comment\nfirstName: Jane\n")) {
+		t.Error("isBlankDocument() = true for a document with fields")
+	}
+}
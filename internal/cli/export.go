@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"google-contacts/internal/contacts"
+)
+
+// Export command flags
+var (
+	exportFormat       string
+	exportFile         string
+	exportAlarmDays    []int
+	exportCalendarName string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export contacts data",
+	Long: `Export data derived from all contacts.
+
+Formats:
+  ics: iCalendar (RFC 5545) file of yearly recurring events built from
+       contact birthdays and significant dates (anniversaries, etc.)
+
+Event UIDs are derived from the contact IDs, so re-importing an updated
+file into a calendar updates existing events instead of duplicating them.
+
+Reminders:
+  --alarm-days adds a reminder N days before each event (0 = on the day).
+  Can be repeated for multiple reminders.
+
+To publish the calendar as a subscription URL instead of a file, start
+the MCP server with --calendar-feed (see 'google-contacts mcp --help').`,
+	Example: `  # Export birthdays to stdout
+  google-contacts export --format ics
+
+  # Export to a file with a reminder one day before
+  google-contacts export --format ics --file birthdays.ics --alarm-days 1
+
+  # Two reminders: one week before and on the day
+  google-contacts export --format ics --file birthdays.ics --alarm-days 7 --alarm-days 0`,
+	RunE: runExport,
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	if exportFormat != "ics" {
+		return fmt.Errorf("unsupported export format '%s', valid formats: ics", exportFormat)
+	}
+
+	ctx := context.Background()
+
+	// Get People API service
+	srv, err := contacts.GetPeopleService(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize service: %w", err)
+	}
+
+	// Fetch all contacts
+	list, err := srv.ListContacts(ctx)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if exportFile != "" {
+		f, err := os.Create(exportFile)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", exportFile, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	opts := contacts.ICalOptions{
+		CalendarName: exportCalendarName,
+		AlarmDays:    exportAlarmDays,
+	}
+	if err := contacts.WriteICal(w, list, opts); err != nil {
+		return fmt.Errorf("failed to write calendar: %w", err)
+	}
+
+	// Only print a summary when not writing to stdout
+	if exportFile != "" {
+		green := color.New(color.FgGreen).SprintFunc()
+		fmt.Printf("%s Exported %d events to %s\n", green("✓"), len(contacts.CalendarEvents(list)), exportFile)
+	}
+
+	return nil
+}
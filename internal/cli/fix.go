@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"google-contacts/internal/contacts"
+	"google-contacts/internal/journal"
+	"google-contacts/pkg/auth"
+)
+
+// Fix command flags
+var (
+	fixDryRun       bool
+	fixMaxChanges   int
+	fixProgressFile string
+	fixRestart      bool
+)
+
+var fixCmd = &cobra.Command{
+	Use:   "fix <transform>... | all",
+	Short: "Apply bulk fix-ups to all contacts",
+	Long: `Apply named transforms to the whole address book.
+
+Transforms:
+  trim-whitespace          Trim and collapse spaces in names, company, position,
+                           notes, emails and addresses
+  normalize-phones         Convert phone numbers to international format
+  lowercase-email-domains  Lowercase the domain part of email addresses
+  uppercase-lastnames      Convert last names to UPPERCASE
+  parse-addresses          Re-parse free-form addresses into structured fields
+                           (street, postal code, city, country)
+
+Use 'all' to apply every transform. Transforms are always applied in the
+order above, whatever the order given on the command line.
+
+Safety:
+  --dry-run shows the diff for every contact without modifying anything.
+  --max-changes limits how many contacts are updated in one run (0 = no limit).
+
+Contacts that fail to update (e.g. because of an invalid email address)
+are reported and skipped. Progress is saved after each updated contact:
+if a run is interrupted, stops at the change budget or has failures,
+running the same command again resumes where it stopped. The progress
+file is removed once all contacts are processed.`,
+	Example: `  # Preview all fixes
+  google-contacts fix all --dry-run
+
+  # Normalize phones, 20 contacts at a time
+  google-contacts fix normalize-phones --max-changes 20
+
+  # Start over, ignoring a previous interrupted run
+  google-contacts fix trim-whitespace uppercase-lastnames --restart`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runFix,
+}
+
+func runFix(cmd *cobra.Command, args []string) error {
+	transforms, err := parseFixArgs(args)
+	if err != nil {
+		return err
+	}
+
+	progressPath := fixProgressFile
+	if progressPath == "" {
+		progressPath, err = defaultFixProgressPath()
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx := context.Background()
+
+	// Get People API service
+	srv, err := contacts.GetPeopleService(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize service: %w", err)
+	}
+
+	// Fetch all contacts
+	list, err := srv.ListContacts(ctx)
+	if err != nil {
+		return err
+	}
+
+	if fixDryRun {
+		return previewFixes(list, transforms)
+	}
+
+	if fixRestart {
+		if err := os.Remove(progressPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove progress file: %w", err)
+		}
+	}
+	progress, err := contacts.LoadFixProgress(progressPath, transforms)
+	if err != nil {
+		return err
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	if len(progress.Done) > 0 {
+		fmt.Printf("%s Resuming: %d contacts already processed\n", yellow("→"), len(progress.Done))
+	}
+
+	updated, failed := 0, 0
+	for _, c := range list {
+		if progress.IsDone(c.ResourceName) {
+			continue
+		}
+
+		plan := contacts.PlanFix(c, transforms)
+		if plan == nil {
+			progress.MarkDone(c.ResourceName, false)
+			continue
+		}
+
+		if fixMaxChanges > 0 && updated >= fixMaxChanges {
+			if err := progress.Save(progressPath); err != nil {
+				return err
+			}
+			fmt.Printf("\n%s Change budget of %d reached, run the same command again to continue\n", yellow("!"), fixMaxChanges)
+			return nil
+		}
+
+		displayFixPlan(plan)
+		after, err := srv.UpdateContact(ctx, plan.ResourceName, plan.Input)
+		if err != nil {
+			// Leave the contact out of the progress so a later run retries it
+			fmt.Printf("  %s %v\n", red("✗"), err)
+			failed++
+			continue
+		}
+		updated++
+		recordOperation(ctx, srv, "fix", journal.OpUpdate, &c, after)
+
+		progress.MarkDone(c.ResourceName, true)
+		if err := progress.Save(progressPath); err != nil {
+			return err
+		}
+	}
+
+	if updated > 0 {
+		invalidateContactCache()
+	}
+
+	if failed > 0 {
+		// Keep the progress file so that only failed contacts are retried
+		if err := progress.Save(progressPath); err != nil {
+			return err
+		}
+		return fmt.Errorf("fixed %d contacts, %d failed: run the same command again to retry", updated, failed)
+	}
+
+	// All contacts processed: the run is complete
+	if err := os.Remove(progressPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove progress file: %w", err)
+	}
+
+	fmt.Printf("\n%s Fixed %d contacts (%d in total across runs)\n", green("✓"), updated, progress.Updated)
+	return nil
+}
+
+// parseFixArgs validates transform names, expanding "all".
+func parseFixArgs(args []string) ([]contacts.FixTransform, error) {
+	var transforms []contacts.FixTransform
+	for _, arg := range args {
+		if arg == "all" {
+			return contacts.FixTransforms, nil
+		}
+		transform, err := contacts.ParseFixTransform(arg)
+		if err != nil {
+			return nil, err
+		}
+		transforms = append(transforms, transform)
+	}
+	return transforms, nil
+}
+
+// defaultFixProgressPath returns the progress file location in the user cache directory.
+func defaultFixProgressPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return profilePath(filepath.Join(dir, "google-contacts", "fix-progress.json"), auth.CurrentProfile()), nil
+}
+
+// previewFixes prints the diff of every contact that would change.
+func previewFixes(list []contacts.ContactDetails, transforms []contacts.FixTransform) error {
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	count := 0
+	for _, c := range list {
+		plan := contacts.PlanFix(c, transforms)
+		if plan == nil {
+			continue
+		}
+		displayFixPlan(plan)
+		count++
+	}
+
+	fmt.Println()
+	if count == 0 {
+		fmt.Println("No changes needed")
+		return nil
+	}
+	fmt.Printf("%s Dry run: %d of %d contacts would be updated\n", yellow("!"), count, len(list))
+	if fixMaxChanges > 0 && count > fixMaxChanges {
+		fmt.Printf("  With --max-changes %d, this takes %d runs\n", fixMaxChanges, (count+fixMaxChanges-1)/fixMaxChanges)
+	}
+	return nil
+}
+
+// displayFixPlan shows the field changes planned for one contact.
+func displayFixPlan(plan *contacts.FixPlan) {
+	cyan := color.New(color.FgCyan).SprintFunc()
+
+	fmt.Printf("%s (%s)\n", cyan(displayNameOrPlaceholder(plan.DisplayName)), extractID(plan.ResourceName))
+	displayFieldChanges(plan.Changes)
+}
+
+// displayFieldChanges shows field changes as removed/added lines.
+func displayFieldChanges(changes []contacts.FixChange) {
+	red := color.New(color.FgRed).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+
+	for _, change := range changes {
+		fmt.Printf("  %s:\n", change.Field)
+		if change.Before != "" {
+			fmt.Printf("    %s\n", red("- "+change.Before))
+		}
+		if change.After != "" {
+			fmt.Printf("    %s\n", green("+ "+change.After))
+		}
+	}
+}
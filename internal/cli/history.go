@@ -0,0 +1,397 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"google-contacts/internal/contacts"
+	"google-contacts/internal/journal"
+	"google-contacts/internal/trash"
+	"google-contacts/pkg/auth"
+)
+
+// History and undo command flags
+var (
+	historyLimit int
+	undoForce    bool
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List recorded contact modifications",
+	Long: `List the contact creations, updates and deletions recorded in the local
+operation journal, most recent first.
+
+Every modification made by the CLI (create, update, edit, delete, fix, browse,
+undo) and by the MCP server is recorded with the contact state before and
+after the operation. Only the operations of the current account are listed.
+Use the operation ID with 'undo' to revert it.`,
+	Example: `  # Last 20 operations
+  google-contacts history
+
+  # All operations as JSON
+  google-contacts history --limit 0 -o json`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{outputFormatsAnnotation: "json,yaml,csv"},
+	RunE:        runHistory,
+}
+
+var undoCmd = &cobra.Command{
+	Use:   "undo [operation-id]",
+	Short: "Revert a recorded contact modification",
+	Long: `Revert an operation from the journal (see 'history').
+
+Without an ID, the most recent operation that has not been undone is
+reverted, so that repeated undos walk back through the history. An ID
+prefix is enough when it is unambiguous.
+
+  - create: the contact is deleted
+  - update: the previous field values are restored (names, phones, emails,
+    addresses, company, position, notes, birthday)
+  - delete: the contact is restored from the trash (see 'trash'), or
+    recreated from its last known state if it was purged, without its events,
+    groups and photo. It gets a new ID
+
+The undo is itself recorded, so it can be undone too.`,
+	Example: `  # Revert the last operation
+  google-contacts undo
+
+  # Revert a specific operation without confirmation
+  google-contacts undo 3f2a9c1e --force`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runUndo,
+}
+
+// operationJournal returns the local operation journal of the current profile.
+func operationJournal() (*journal.Journal, error) {
+	path, err := journal.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return &journal.Journal{Path: profilePath(path, auth.CurrentProfile())}, nil
+}
+
+// The account of the current profile, read once per run
+var (
+	accountOnce   sync.Once
+	cachedAccount string
+	accountErr    error
+)
+
+// currentAccount returns the email address of the current account.
+func currentAccount(ctx context.Context, srv *contacts.Service) (string, error) {
+	accountOnce.Do(func() {
+		cachedAccount, accountErr = srv.AccountEmail(ctx)
+	})
+	return cachedAccount, accountErr
+}
+
+// accountOperations returns the journal and the entries of the current
+// account: the journal of a profile also holds the operations of the MCP
+// server, made on behalf of its callers.
+func accountOperations(ctx context.Context, srv *contacts.Service) (*journal.Journal, []journal.Entry, error) {
+	j, err := operationJournal()
+	if err != nil {
+		return nil, nil, err
+	}
+	entries, err := j.Entries()
+	if err != nil {
+		return nil, nil, err
+	}
+	account, err := currentAccount(ctx, srv)
+	if err != nil {
+		return nil, nil, err
+	}
+	return j, journal.ForAccount(entries, account), nil
+}
+
+// appendOperation records a modification of the account of srv in the
+// journal. The entry has no account when it cannot be read.
+func appendOperation(ctx context.Context, srv *contacts.Service, e journal.Entry) (journal.Entry, error) {
+	j, err := operationJournal()
+	if err != nil {
+		return journal.Entry{}, err
+	}
+	e.Account, _ = currentAccount(ctx, srv)
+	return j.Append(e)
+}
+
+// recordOperation records a modification made by command. A journal failure
+// does not revert the modification, so it is only reported as a warning.
+func recordOperation(ctx context.Context, srv *contacts.Service, command string, op journal.Operation, before, after *contacts.ContactDetails) {
+	if _, err := appendOperation(ctx, srv, journal.NewEntry(op, journal.SourceCLI, command, before, after)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record operation: %v\n", err)
+	}
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	// Get People API service
+	srv, err := contacts.GetPeopleService(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize service: %w", err)
+	}
+	_, entries, err := accountOperations(ctx, srv)
+	if err != nil {
+		return err
+	}
+
+	// Most recent first
+	recent := slices.Clone(entries)
+	slices.Reverse(recent)
+	if historyLimit > 0 && len(recent) > historyLimit {
+		recent = recent[:historyLimit]
+	}
+	if recent == nil {
+		recent = []journal.Entry{}
+	}
+
+	return commandOutput{
+		table: func() { displayHistory(recent, entries) },
+		data:  recent,
+		csv: func() ([]string, [][]string) {
+			header := []string{"id", "time", "operation", "source", "command", "contactId", "displayName", "undoes", "undoneBy"}
+			var rows [][]string
+			for _, e := range recent {
+				rows = append(rows, []string{
+					e.ID,
+					e.Time.Format(time.RFC3339),
+					string(e.Operation),
+					e.Source,
+					e.Command,
+					extractID(e.ResourceName),
+					e.DisplayName,
+					e.Undoes,
+					journal.UndoneBy(entries, e.ID),
+				})
+			}
+			return header, rows
+		},
+	}.write()
+}
+
+// displayHistory prints journal entries with the fields each update changed.
+// all is the full journal, used to find which entries were undone.
+func displayHistory(recent, all []journal.Entry) {
+	if len(recent) == 0 {
+		fmt.Println("No recorded operations.")
+		return
+	}
+
+	cyan := color.New(color.FgCyan).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	faint := color.New(color.Faint).SprintFunc()
+
+	for _, e := range recent {
+		source := e.Source
+		if e.Command != "" {
+			source += ":" + e.Command
+		}
+		fmt.Printf("%s  %s  %-6s  %s (%s)  %s",
+			yellow(e.ID),
+			e.Time.Local().Format("2006-01-02 15:04"),
+			e.Operation,
+			cyan(displayNameOrPlaceholder(e.DisplayName)),
+			extractID(e.ResourceName),
+			faint(source),
+		)
+		if e.Undoes != "" {
+			fmt.Printf("  %s", faint("undoes "+e.Undoes))
+		}
+		if by := journal.UndoneBy(all, e.ID); by != "" {
+			fmt.Printf("  %s", faint("undone by "+by))
+		}
+		fmt.Println()
+
+		if e.Operation == journal.OpUpdate && e.Before != nil && e.After != nil {
+			if fields := changedFields(e.Before, e.After); len(fields) > 0 {
+				fmt.Printf("          changed: %s\n", strings.Join(fields, ", "))
+			}
+		}
+	}
+}
+
+// changedFields returns the names of the fields that differ between two states.
+func changedFields(before, after *contacts.ContactDetails) []string {
+	_, changes := journal.RestoreInput(before, after)
+	var fields []string
+	for _, c := range changes {
+		if !slices.Contains(fields, c.Field) {
+			fields = append(fields, c.Field)
+		}
+	}
+	return fields
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	// Get People API service
+	srv, err := contacts.GetPeopleService(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize service: %w", err)
+	}
+	j, entries, err := accountOperations(ctx, srv)
+	if err != nil {
+		return err
+	}
+
+	var entry journal.Entry
+	if len(args) > 0 {
+		entry, err = journal.Find(entries, args[0])
+		if err != nil {
+			return err
+		}
+	} else {
+		var ok bool
+		if entry, ok = journal.LastUndoable(entries); !ok {
+			return fmt.Errorf("no operation to undo")
+		}
+	}
+	if by := journal.UndoneBy(entries, entry.ID); by != "" {
+		return fmt.Errorf("operation %s was already undone by %s", entry.ID, by)
+	}
+
+	cyan := color.New(color.FgCyan).SprintFunc()
+	fmt.Printf("Undoing %s of %s (%s) from %s\n\n",
+		entry.Operation,
+		cyan(displayNameOrPlaceholder(entry.DisplayName)),
+		extractID(entry.ResourceName),
+		entry.Time.Local().Format("2006-01-02 15:04"),
+	)
+
+	var undo journal.Entry
+	switch entry.Operation {
+	case journal.OpCreate:
+		undo, err = undoCreate(ctx, srv, entry)
+	case journal.OpUpdate:
+		undo, err = undoUpdate(ctx, srv, entry)
+	case journal.OpDelete:
+		undo, err = undoDelete(ctx, srv, entry)
+	default:
+		return fmt.Errorf("cannot undo operation %q", entry.Operation)
+	}
+	if err != nil || undo.Operation == "" {
+		return err
+	}
+	invalidateContactCache()
+
+	undo.Undoes = entry.ID
+	undo.Account, _ = currentAccount(ctx, srv)
+	if _, err := j.Append(undo); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record operation: %v\n", err)
+	}
+	return nil
+}
+
+// undoCreate deletes a created contact. An empty entry means the user cancelled.
+func undoCreate(ctx context.Context, srv *contacts.Service, entry journal.Entry) (journal.Entry, error) {
+	current, err := srv.GetContactDetails(ctx, entry.ResourceName)
+	if err != nil {
+		return journal.Entry{}, err
+	}
+	displayDeleteSummary(current)
+	if !undoForce && !confirm("\nDelete this contact? (y/N): ", false) {
+		fmt.Println("Undo cancelled.")
+		return journal.Entry{}, nil
+	}
+
+	if _, err := trashContact(ctx, srv, current.ResourceName); err != nil {
+		return journal.Entry{}, err
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	fmt.Printf("%s Contact '%s' has been deleted.\n", green("✓"), current.DisplayName)
+	return journal.NewEntry(journal.OpDelete, journal.SourceCLI, "undo", current, nil), nil
+}
+
+// undoUpdate restores the fields of a contact before an update.
+func undoUpdate(ctx context.Context, srv *contacts.Service, entry journal.Entry) (journal.Entry, error) {
+	if entry.Before == nil {
+		return journal.Entry{}, fmt.Errorf("operation %s has no previous state to restore", entry.ID)
+	}
+	current, err := srv.GetContactDetails(ctx, entry.ResourceName)
+	if err != nil {
+		return journal.Entry{}, err
+	}
+
+	input, changes := journal.RestoreInput(current, entry.Before)
+	if len(changes) == 0 {
+		fmt.Println("The contact already has its previous values, nothing to undo.")
+		return journal.Entry{}, nil
+	}
+
+	yellow := color.New(color.FgYellow).SprintFunc()
+	if entry.After != nil && current.UpdatedAt != entry.After.UpdatedAt {
+		fmt.Printf("%s The contact was modified after this operation: later changes to these fields will be lost.\n\n", yellow("!"))
+	}
+	displayFieldChanges(changes)
+	if !undoForce && !confirm("\nRestore these values? (y/N): ", false) {
+		fmt.Println("Undo cancelled.")
+		return journal.Entry{}, nil
+	}
+
+	after, err := srv.UpdateContact(ctx, current.ResourceName, input)
+	if err != nil {
+		return journal.Entry{}, err
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	fmt.Printf("%s Contact '%s' has been restored.\n", green("✓"), after.DisplayName)
+	return journal.NewEntry(journal.OpUpdate, journal.SourceCLI, "undo", current, after), nil
+}
+
+// undoDelete restores a deleted contact from the trash, or recreates it
+// from its last known state when it is no longer in the trash.
+func undoDelete(ctx context.Context, srv *contacts.Service, entry journal.Entry) (journal.Entry, error) {
+	store, err := contactTrash()
+	if err != nil {
+		return journal.Entry{}, err
+	}
+	items, err := store.List()
+	if err != nil {
+		return journal.Entry{}, err
+	}
+	if item, err := trash.Find(items, entry.ResourceName); err == nil {
+		displayFullContactDetails(item.Archive.Details())
+		if !undoForce && !confirm("\nRestore this contact? (y/N): ", false) {
+			fmt.Println("Undo cancelled.")
+			return journal.Entry{}, nil
+		}
+		restored, err := restoreFromTrash(ctx, srv, store, item)
+		if err != nil {
+			return journal.Entry{}, err
+		}
+		displayRestored(restored)
+		after := &contacts.ContactDetails{ResourceName: restored.ResourceName, DisplayName: restored.DisplayName}
+		return journal.NewEntry(journal.OpCreate, journal.SourceCLI, "undo", nil, after), nil
+	}
+
+	if entry.Before == nil {
+		return journal.Entry{}, fmt.Errorf("operation %s has no previous state to restore", entry.ID)
+	}
+	displayFullContactDetails(entry.Before)
+	if !undoForce && !confirm("\nRecreate this contact? (y/N): ", false) {
+		fmt.Println("Undo cancelled.")
+		return journal.Entry{}, nil
+	}
+
+	created, err := srv.CreateContact(ctx, journal.RecreateInput(entry.Before))
+	if err != nil {
+		return journal.Entry{}, err
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	fmt.Printf("%s Contact '%s' recreated (%s)\n", green("✓"), created.DisplayName, extractID(created.ResourceName))
+	after := &contacts.ContactDetails{ResourceName: created.ResourceName, DisplayName: created.DisplayName}
+	return journal.NewEntry(journal.OpCreate, journal.SourceCLI, "undo", nil, after), nil
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"slices"
+	"testing"
+
+	"google-contacts/internal/contacts"
+)
+
+func TestChangedFields(t *testing.T) {
+	before := &contacts.ContactDetails{
+		FirstName: "Jane",
+		Phones: []contacts.PhoneEntry{
+			{Value: "+33612345678", Type: "mobile"},
+			{Value: "+33145678901", Type: "work"},
+		},
+	}
+	after := &contacts.ContactDetails{
+		FirstName: "Jane",
+		Phones:    []contacts.PhoneEntry{{Value: "+33700000000", Type: "mobile"}},
+		Company:   "Acme",
+	}
+
+	got := changedFields(before, after)
+	want := []string{"company", "phones"}
+	if !slices.Equal(got, want) {
+		t.Errorf("changedFields() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"google-contacts/internal/contacts"
+	mcpserver "google-contacts/internal/mcp"
+)
+
+// Output formats for the global --output flag.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputCSV   = "csv"
+	outputVCard = "vcard"
+)
+
+// outputFormats lists all output formats.
+var outputFormats = []string{outputTable, outputJSON, outputYAML, outputCSV, outputVCard}
+
+// outputFormat is the value of the global --output flag.
+var outputFormat string
+
+// outputFormatsAnnotation is the command annotation listing the machine-readable
+// formats a command supports (comma-separated). Commands without it only
+// support table output.
+const outputFormatsAnnotation = "output-formats"
+
+// validateOutputFormat checks the --output flag against the command's supported formats.
+func validateOutputFormat(cmd *cobra.Command, args []string) error {
+	if !slices.Contains(outputFormats, outputFormat) {
+		return fmt.Errorf("invalid output format '%s', valid formats: %s", outputFormat, strings.Join(outputFormats, ", "))
+	}
+	if outputFormat == outputTable {
+		return nil
+	}
+	supported := strings.Split(cmd.Annotations[outputFormatsAnnotation], ",")
+	if !slices.Contains(supported, outputFormat) {
+		return fmt.Errorf("--output %s is not supported by '%s'", outputFormat, cmd.CommandPath())
+	}
+	return nil
+}
+
+// commandOutput describes the result of a command in every output format.
+type commandOutput struct {
+	table func()                        // Human-readable output
+	data  any                           // JSON/YAML document (MCP output schema)
+	csv   func() ([]string, [][]string) // CSV header and rows
+	vcard []contacts.ContactDetails     // Contacts for vCard output
+}
+
+// write prints the output in the format selected with --output.
+func (o commandOutput) write() error {
+	return o.writeTo(os.Stdout, outputFormat)
+}
+
+// writeTo prints the output in the given format.
+func (o commandOutput) writeTo(w io.Writer, format string) error {
+	switch format {
+	case outputJSON:
+		return writeJSON(w, o.data)
+	case outputYAML:
+		return writeYAML(w, o.data)
+	case outputCSV:
+		header, rows := o.csv()
+		return writeCSV(w, header, rows)
+	case outputVCard:
+		return contacts.WriteVCard(w, o.vcard)
+	default:
+		o.table()
+		return nil
+	}
+}
+
+// writeJSON writes v as indented JSON.
+func writeJSON(w io.Writer, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// writeYAML writes v as YAML with the same keys and order as the JSON output.
+func writeYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	// JSON is valid YAML: decoding it into a node keeps the JSON key order
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	clearYAMLStyle(&node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// clearYAMLStyle switches nodes decoded from JSON to block style.
+// Scalars keep their tag, so the encoder still quotes ambiguous strings.
+func clearYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearYAMLStyle(child)
+	}
+}
+
+// writeCSV writes a header and rows as RFC 4180 CSV.
+func writeCSV(w io.Writer, header []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// searchCSV returns the CSV columns of search results.
+func searchCSV(output mcpserver.SearchOutput) func() ([]string, [][]string) {
+	return func() ([]string, [][]string) {
+		header := []string{"resourceName", "displayName", "phone", "email", "company", "position"}
+		var rows [][]string
+		for _, r := range output.Results {
+			rows = append(rows, []string{r.ResourceName, r.DisplayName, r.Phone, r.Email, r.Company, r.Position})
+		}
+		return header, rows
+	}
+}
+
+// showCSV returns the CSV columns of full contact details.
+// Multi-valued fields are "type:value" entries joined with "; ".
+func showCSV(outputs ...mcpserver.ShowOutput) func() ([]string, [][]string) {
+	return func() ([]string, [][]string) {
+		header := []string{"resourceName", "firstName", "lastName", "displayName", "phones", "emails", "addresses",
+			"company", "position", "notes", "birthday", "updatedAt"}
+		var rows [][]string
+		for _, o := range outputs {
+			var phones, emails, addresses []string
+			for _, p := range o.Phones {
+				phones = append(phones, p.Type+":"+p.Value)
+			}
+			for _, e := range o.Emails {
+				emails = append(emails, e.Type+":"+e.Value)
+			}
+			for _, a := range o.Addresses {
+				addresses = append(addresses, a.Type+":"+a.Value)
+			}
+			rows = append(rows, []string{o.ResourceName, o.FirstName, o.LastName, o.DisplayName,
+				strings.Join(phones, "; "), strings.Join(emails, "; "), strings.Join(addresses, "; "),
+				o.Company, o.Position, o.Notes, o.Birthday, o.UpdatedAt})
+		}
+		return header, rows
+	}
+}
+
+// searchResultsAsDetails converts search results to minimal contact details for vCard output.
+func searchResultsAsDetails(results []contacts.SearchResult) []contacts.ContactDetails {
+	var list []contacts.ContactDetails
+	for _, r := range results {
+		c := contacts.ContactDetails{
+			ResourceName: r.ResourceName,
+			DisplayName:  r.DisplayName,
+			Company:      r.Company,
+			Position:     r.Position,
+			Notes:        r.Notes,
+		}
+		if r.Phone != "" {
+			c.Phones = []contacts.PhoneEntry{{Value: r.Phone}}
+		}
+		if r.Email != "" {
+			c.Emails = []contacts.EmailEntry{{Value: r.Email}}
+		}
+		list = append(list, c)
+	}
+	return list
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"google-contacts/internal/contacts"
+	mcpserver "google-contacts/internal/mcp"
+)
+
+func TestCommandOutput_Formats(t *testing.T) {
+	details := &contacts.ContactDetails{
+		ResourceName: "people/c111",
+		FirstName:    "Jane",
+		LastName:     "DOE",
+		DisplayName:  "Jane DOE",
+		Phones:       []contacts.PhoneEntry{{Value: "+33612345678", Type: "mobile"}, {Value: "+33100000000", Type: "work"}},
+		Company:      "Acme, Inc.",
+		Birthday:     "--03-15",
+	}
+	output := mcpserver.NewShowOutput(details)
+	tableCalled := false
+	out := commandOutput{
+		table: func() { tableCalled = true },
+		data:  output,
+		csv:   showCSV(output),
+		vcard: []contacts.ContactDetails{*details},
+	}
+
+	tests := []struct {
+		format   string
+		expected []string
+	}{
+		{
+			format: outputJSON,
+			expected: []string{
+				`"resourceName": "people/c111"`,
+				`"emails": []`,
+				`"birthday": "--03-15"`,
+			},
+		},
+		{
+			format: outputYAML,
+			expected: []string{
+				"resourceName: people/c111\n",
+				"phones:\n  - value: \"+33612345678\"\n    type: mobile\n",
+				"emails: []\n",
+				`birthday: --03-15`,
+			},
+		},
+		{
+			format: outputCSV,
+			expected: []string{
+				"resourceName,firstName,lastName,displayName,phones,emails,addresses,company,position,notes,birthday,updatedAt\n",
+				`people/c111,Jane,DOE,Jane DOE,mobile:+33612345678; work:+33100000000,,,"Acme, Inc.",,,--03-15,` + "\n",
+			},
+		},
+		{
+			format:   outputVCard,
+			expected: []string{"BEGIN:VCARD\r\n", "TEL;TYPE=CELL:+33612345678\r\n"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.format, func(t *testing.T) {
+			var b strings.Builder
+			if err := out.writeTo(&b, tc.format); err != nil {
+				t.Fatalf("writeTo(%s) error: %v", tc.format, err)
+			}
+			for _, want := range tc.expected {
+				if !strings.Contains(b.String(), want) {
+					t.Errorf("writeTo(%s) output missing %q, got:\n%s", tc.format, want, b.String())
+				}
+			}
+		})
+	}
+
+	if tableCalled {
+		t.Error("table output should not be called for machine-readable formats")
+	}
+	if err := out.writeTo(&strings.Builder{}, outputTable); err != nil || !tableCalled {
+		t.Errorf("writeTo(table) should call the table printer, err = %v", err)
+	}
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	searchLike := &cobra.Command{Use: "search", Annotations: map[string]string{outputFormatsAnnotation: "json,yaml,csv,vcard"}}
+	createLike := &cobra.Command{Use: "create", Annotations: map[string]string{outputFormatsAnnotation: "json,yaml,csv"}}
+	plain := &cobra.Command{Use: "export"}
+
+	tests := []struct {
+		name        string
+		cmd         *cobra.Command
+		format      string
+		errContains string
+	}{
+		{name: "table everywhere", cmd: plain, format: outputTable},
+		{name: "supported format", cmd: searchLike, format: outputVCard},
+		{name: "unsupported vcard", cmd: createLike, format: outputVCard, errContains: "not supported"},
+		{name: "command without annotation", cmd: plain, format: outputJSON, errContains: "not supported"},
+		{name: "unknown format", cmd: searchLike, format: "xml", errContains: "invalid output format"},
+	}
+
+	defer func() { outputFormat = outputTable }()
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			outputFormat = tc.format
+			err := validateOutputFormat(tc.cmd, nil)
+			if tc.errContains == "" {
+				if err != nil {
+					t.Errorf("validateOutputFormat() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errContains) {
+				t.Errorf("validateOutputFormat() error = %v, want error containing %q", err, tc.errContains)
+			}
+		})
+	}
+}
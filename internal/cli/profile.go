@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+
+	"google-contacts/internal/contacts"
+	mcpserver "google-contacts/internal/mcp"
+	"google-contacts/pkg/auth"
+)
+
+// profileEntry is a profile in the auth profiles output.
+type profileEntry struct {
+	Name          string `json:"name"`
+	Current       bool   `json:"current"`
+	Authenticated bool   `json:"authenticated"`
+	TokenPath     string `json:"tokenPath"`
+}
+
+// profilePath returns the location of a local file for a profile: the
+// default profile keeps the historical location, other profiles use a
+// profiles/<name> directory next to it. A user impersonated with a service
+// account uses an impersonate/<email> directory instead, whatever the
+// profile, since the profile token is not used.
+func profilePath(path, profile string) string {
+	if user := auth.CurrentSubject(); user != "" {
+		return filepath.Join(filepath.Dir(path), "impersonate", user, filepath.Base(path))
+	}
+	if profile == auth.DefaultProfile {
+		return path
+	}
+	return filepath.Join(filepath.Dir(path), "profiles", profile, filepath.Base(path))
+}
+
+// listProfiles returns the known profiles and whether they have a token.
+func listProfiles() ([]profileEntry, error) {
+	names, err := auth.ListProfiles()
+	if err != nil {
+		return nil, err
+	}
+	entries := []profileEntry{}
+	for _, name := range names {
+		entries = append(entries, profileEntry{
+			Name:          name,
+			Current:       name == auth.CurrentProfile(),
+			Authenticated: auth.HasToken(name),
+			TokenPath:     auth.TokenLocation(name),
+		})
+	}
+	return entries, nil
+}
+
+// searchTargets returns the profiles named by --profiles: "all" selects every
+// authenticated profile. Duplicates are dropped.
+func searchTargets(names []string) ([]string, error) {
+	var targets []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "all" {
+			entries, err := listProfiles()
+			if err != nil {
+				return nil, err
+			}
+			for _, e := range entries {
+				if e.Authenticated && !slices.Contains(targets, e.Name) {
+					targets = append(targets, e.Name)
+				}
+			}
+			continue
+		}
+		if err := auth.ValidateProfile(name); err != nil {
+			return nil, err
+		}
+		if !slices.Contains(targets, name) {
+			targets = append(targets, name)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no authenticated profile to search")
+	}
+	return targets, nil
+}
+
+// profileSearchResult is a search result of a multi-profile search.
+type profileSearchResult struct {
+	Profile string `json:"profile"`
+	mcpserver.SearchResultItem
+}
+
+// profileSearchOutput is the multi-profile search document.
+type profileSearchOutput struct {
+	Results []profileSearchResult `json:"results"`
+	Count   int                   `json:"count"`
+}
+
+// runProfileSearch runs a search in each profile of --profiles. A profile
+// that fails (e.g. not authenticated) is reported and skipped, so that one
+// expired token does not hide the results of the other accounts.
+func runProfileSearch(ctx context.Context, query string, where *contacts.Query) error {
+	targets, err := searchTargets(searchProfiles)
+	if err != nil {
+		return err
+	}
+
+	output := profileSearchOutput{Results: []profileSearchResult{}}
+	var details []contacts.ContactDetails
+	for _, profile := range targets {
+		results, err := searchProfile(ctx, profile, query, where)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: profile %s: %v\n", profile, err)
+			continue
+		}
+		for _, item := range mcpserver.NewSearchOutput(results).Results {
+			output.Results = append(output.Results, profileSearchResult{Profile: profile, SearchResultItem: item})
+		}
+		details = append(details, searchResultsAsDetails(results)...)
+	}
+	output.Count = len(output.Results)
+
+	return commandOutput{
+		table: func() { displayProfileSearch(output, strings.TrimSpace(query+" "+searchWhere)) },
+		data:  output,
+		csv: func() ([]string, [][]string) {
+			header := []string{"profile", "resourceName", "displayName", "phone", "email", "company", "position"}
+			var rows [][]string
+			for _, r := range output.Results {
+				rows = append(rows, []string{r.Profile, r.ResourceName, r.DisplayName, r.Phone, r.Email, r.Company, r.Position})
+			}
+			return header, rows
+		},
+		vcard: details,
+	}.write()
+}
+
+// searchProfile searches the contacts of one profile without starting the
+// browser authentication.
+func searchProfile(ctx context.Context, profile, query string, where *contacts.Query) ([]contacts.SearchResult, error) {
+	ctx = auth.WithNonInteractive(auth.WithProfile(ctx, profile))
+	srv, err := contacts.GetPeopleService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize service: %w", err)
+	}
+	if searchFuzzy || where != nil {
+		return localSearch(ctx, srv, profile, query, where)
+	}
+	return srv.SearchContacts(ctx, query)
+}
+
+// displayProfileSearch prints multi-profile search results with their profile.
+func displayProfileSearch(output profileSearchOutput, query string) {
+	if output.Count == 0 {
+		fmt.Printf("No contacts found matching \"%s\"\n", query)
+		return
+	}
+
+	cyan := color.New(color.FgCyan).SprintFunc()
+	fmt.Printf("Found %d contacts:\n\n", output.Count)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", cyan("Profile"), cyan("ID"), cyan("Name"), cyan("Phone"), cyan("Company"), cyan("Email"))
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+		strings.Repeat("-", 10),
+		strings.Repeat("-", 15),
+		strings.Repeat("-", 20),
+		strings.Repeat("-", 15),
+		strings.Repeat("-", 15),
+		strings.Repeat("-", 25))
+	for _, r := range output.Results {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Profile,
+			extractID(r.ResourceName),
+			truncate(r.DisplayName, 20),
+			truncate(r.Phone, 15),
+			truncate(r.Company, 15),
+			truncate(r.Email, 25))
+	}
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	"google-contacts/pkg/auth"
+)
+
+func TestProfilePath(t *testing.T) {
+	tests := []struct {
+		path     string
+		profile  string
+		expected string
+	}{
+		{"/cache/google-contacts/contacts.json", auth.DefaultProfile, "/cache/google-contacts/contacts.json"},
+		{"/cache/google-contacts/contacts.json", "work", "/cache/google-contacts/profiles/work/contacts.json"},
+		{"/config/google-contacts/trash", "work", "/config/google-contacts/profiles/work/trash"},
+	}
+
+	for _, tc := range tests {
+		if got := profilePath(tc.path, tc.profile); got != tc.expected {
+			t.Errorf("profilePath(%q, %q) = %q, want %q", tc.path, tc.profile, got, tc.expected)
+		}
+	}
+}
+
+func TestProfilePath_Impersonation(t *testing.T) {
+	sa, err := auth.ParseServiceAccount([]byte(`{"type":"service_account","client_email":"sa@project.iam.gserviceaccount.com"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.SetImpersonation(sa, "jane@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	defer auth.SetImpersonation(nil, "")
+
+	for _, profile := range []string{auth.DefaultProfile, "work"} {
+		want := "/config/google-contacts/impersonate/jane@example.com/trash"
+		if got := profilePath("/config/google-contacts/trash", profile); got != want {
+			t.Errorf("profilePath(%q) = %q, want %q", profile, got, want)
+		}
+	}
+}
+
+func TestSearchTargets(t *testing.T) {
+	dir := t.TempDir()
+	auth.SetCredentialsPath(dir)
+	defer auth.SetCredentialsPath("")
+
+	// Authenticated: default and work; personal has no token yet
+	for _, p := range []string{auth.DefaultProfile, "work", "personal"} {
+		if err := os.MkdirAll(auth.ProfileDir(p), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, p := range []string{auth.DefaultProfile, "work"} {
+		if err := os.WriteFile(auth.TokenPath(p), []byte("{}"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		names    []string
+		expected []string
+		wantErr  bool
+	}{
+		{[]string{"all"}, []string{auth.DefaultProfile, "work"}, false},
+		{[]string{"personal", "work", "personal"}, []string{"personal", "work"}, false},
+		{[]string{"work", "all"}, []string{"work", auth.DefaultProfile}, false},
+		{[]string{"../etc"}, nil, true},
+	}
+
+	for _, tc := range tests {
+		got, err := searchTargets(tc.names)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("searchTargets(%v) error = %v, wantErr %v", tc.names, err, tc.wantErr)
+			continue
+		}
+		if !slices.Equal(got, tc.expected) {
+			t.Errorf("searchTargets(%v) = %v, want %v", tc.names, got, tc.expected)
+		}
+	}
+}
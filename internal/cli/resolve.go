@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/fatih/color"
+
+	"google-contacts/internal/contacts"
+)
+
+// errContactNotChosen is returned when the user cancels the disambiguation list.
+var errContactNotChosen = errors.New("no contact selected")
+
+// resolveContact returns the resource name designated by a contact argument.
+// Ambiguous and partial names are resolved by asking the user on a terminal.
+func resolveContact(ctx context.Context, srv *contacts.Service, ref string) (string, error) {
+	resourceName, err := srv.ResolveContact(ctx, ref)
+	var ambiguous *contacts.AmbiguousContactError
+	if !errors.As(err, &ambiguous) || !isInteractive() {
+		return resourceName, err
+	}
+	return newWizard().chooseContact(ambiguous)
+}
+
+// chooseContact lists the candidates of an ambiguous or partial reference and
+// asks for one by number, even if there is a single candidate. An empty
+// answer cancels.
+func (w *wizard) chooseContact(ambiguous *contacts.AmbiguousContactError) (string, error) {
+	cyan := color.New(color.FgCyan).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	if ambiguous.Partial {
+		fmt.Fprintf(w.out, "No contact matches %q exactly, similar contacts:\n", ambiguous.Query)
+	} else {
+		fmt.Fprintf(w.out, "%q matches %d contacts:\n", ambiguous.Query, len(ambiguous.Candidates))
+	}
+	for i, c := range ambiguous.Candidates {
+		fmt.Fprintf(w.out, "  %d) %s (%s)", i+1, cyan(displayNameOrPlaceholder(c.DisplayName)), extractID(c.ResourceName))
+		for _, v := range []string{c.Email, c.Phone, c.Company} {
+			if v != "" {
+				fmt.Fprintf(w.out, "  %s", v)
+			}
+		}
+		fmt.Fprintln(w.out)
+	}
+
+	for {
+		fmt.Fprintf(w.out, "Select a contact [1-%d] (empty to cancel): ", len(ambiguous.Candidates))
+		answer, err := w.readLine()
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			return "", errContactNotChosen
+		}
+		n, err := strconv.Atoi(answer)
+		if err != nil || n < 1 || n > len(ambiguous.Candidates) {
+			fmt.Fprintf(w.out, "  %s enter a number between 1 and %d\n", red("✗"), len(ambiguous.Candidates))
+			continue
+		}
+		return ambiguous.Candidates[n-1].ResourceName, nil
+	}
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"google-contacts/internal/contacts"
+)
+
+func TestWizard_ChooseContact(t *testing.T) {
+	ambiguous := &contacts.AmbiguousContactError{
+		Query: "Jane",
+		Candidates: []contacts.SearchResult{
+			{ResourceName: "people/c1", DisplayName: "Jane Doe", Email: "jane@example.com"},
+			{ResourceName: "people/c2", DisplayName: "Jane Roe", Phone: "+33612345678"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		answers  []string
+		expected string
+		err      error
+	}{
+		{name: "select", answers: []string{"2"}, expected: "people/c2"},
+		{name: "invalid then select", answers: []string{"3", "x", "1"}, expected: "people/c1"},
+		{name: "cancel", answers: []string{""}, err: errContactNotChosen},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w, out := newTestWizard(tc.answers...)
+			got, err := w.chooseContact(ambiguous)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("chooseContact() error = %v, want %v", err, tc.err)
+			}
+			if got != tc.expected {
+				t.Errorf("chooseContact() = %q, want %q", got, tc.expected)
+			}
+			for _, want := range []string{"1) Jane Doe (c1)  jane@example.com", "2) Jane Roe (c2)  +33612345678"} {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestWizard_ChooseContact_Partial(t *testing.T) {
+	// A single partial match is confirmed, not selected silently
+	partial := &contacts.AmbiguousContactError{
+		Query:      "Jan",
+		Candidates: []contacts.SearchResult{{ResourceName: "people/c1", DisplayName: "Jane Doe"}},
+		Partial:    true,
+	}
+	w, out := newTestWizard("")
+	if _, err := w.chooseContact(partial); !errors.Is(err, errContactNotChosen) {
+		t.Fatalf("chooseContact() error = %v, want errContactNotChosen", err)
+	}
+	if !strings.Contains(out.String(), `No contact matches "Jan" exactly`) {
+		t.Errorf("output = %q, want the partial match notice", out.String())
+	}
+}
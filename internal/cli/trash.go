@@ -0,0 +1,259 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"google-contacts/internal/contacts"
+	"google-contacts/internal/journal"
+	"google-contacts/internal/trash"
+	"google-contacts/pkg/auth"
+)
+
+// Trash command flags
+var (
+	trashPurgeOlderThan string
+	trashPurgeForce     bool
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "List, restore and purge deleted contacts",
+	Long: `Deleted contacts are archived in a local trash before being deleted from
+Google: every field, the contact group memberships and the custom photo.
+
+Use 'trash list' to see them, 'trash restore' to recreate one and
+'trash purge' to remove old archives for good.`,
+}
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List deleted contacts",
+	Example: `  google-contacts trash list
+  google-contacts trash list -o json`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{outputFormatsAnnotation: "json,yaml,csv"},
+	RunE:        runTrashList,
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <trash-id>",
+	Short: "Restore a deleted contact",
+	Long: `Recreate a deleted contact from the trash, with all its fields, contact
+groups and photo, then remove it from the trash.
+
+The argument is a trash ID (or an unambiguous prefix) from 'trash list', or the
+ID the contact had before deletion. The restored contact gets a new ID.`,
+	Example: `  google-contacts trash restore 3f2a9c1e
+  google-contacts trash restore c123456789`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTrashRestore,
+}
+
+var trashPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Permanently remove old deleted contacts",
+	Long: `Permanently remove the contacts deleted before the given age from the trash.
+
+The age is a number of days (30d), weeks (2w) or a duration (12h).
+Use --older-than 0d to empty the trash.`,
+	Example: `  # Remove contacts deleted more than 30 days ago
+  google-contacts trash purge
+
+  # Empty the trash without confirmation
+  google-contacts trash purge --older-than 0d --force`,
+	Args: cobra.NoArgs,
+	RunE: runTrashPurge,
+}
+
+// trashEntry is a deleted contact in the trash list output.
+type trashEntry struct {
+	ID          string `json:"id"`
+	DeletedAt   string `json:"deletedAt"`
+	ContactID   string `json:"contactId"`
+	DisplayName string `json:"displayName"`
+	Owner       string `json:"owner,omitempty"`
+	Source      string `json:"source,omitempty"`
+}
+
+// contactTrash returns the local trash store of the current profile.
+func contactTrash() (*trash.Store, error) {
+	dir, err := trash.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return &trash.Store{Dir: profilePath(dir, auth.CurrentProfile())}, nil
+}
+
+// trashContact archives a contact in the local trash, then deletes it.
+func trashContact(ctx context.Context, srv *contacts.Service, resourceName string) (trash.Item, error) {
+	store, err := contactTrash()
+	if err != nil {
+		return trash.Item{}, err
+	}
+	return store.MoveToTrash(ctx, srv, resourceName, journal.SourceCLI)
+}
+
+// restoreFromTrash recreates a trashed contact and removes it from the trash.
+// The contact must come from the current account when both are known.
+func restoreFromTrash(ctx context.Context, srv *contacts.Service, store *trash.Store, item trash.Item) (*contacts.RestoredContact, error) {
+	if item.Owner != "" {
+		if account, err := srv.AccountEmail(ctx); err == nil && !strings.EqualFold(account, item.Owner) {
+			return nil, fmt.Errorf("contact was deleted from %s, but the current account is %s", item.Owner, account)
+		}
+	}
+
+	restored, err := srv.RestoreContact(ctx, &item.Archive)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Remove(item.ID); err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+func runTrashList(cmd *cobra.Command, args []string) error {
+	store, err := contactTrash()
+	if err != nil {
+		return err
+	}
+	items, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	entries := []trashEntry{}
+	for _, item := range items {
+		entries = append(entries, trashEntry{
+			ID:          item.ID,
+			DeletedAt:   item.DeletedAt.Format(time.RFC3339),
+			ContactID:   extractID(item.ResourceName),
+			DisplayName: item.DisplayName,
+			Owner:       item.Owner,
+			Source:      item.Source,
+		})
+	}
+
+	return commandOutput{
+		table: func() { displayTrash(items) },
+		data:  entries,
+		csv: func() ([]string, [][]string) {
+			var rows [][]string
+			for _, e := range entries {
+				rows = append(rows, []string{e.ID, e.DeletedAt, e.ContactID, e.DisplayName, e.Owner, e.Source})
+			}
+			return []string{"id", "deletedAt", "contactId", "displayName", "owner", "source"}, rows
+		},
+	}.write()
+}
+
+// displayTrash prints the trash content.
+func displayTrash(items []trash.Item) {
+	if len(items) == 0 {
+		fmt.Println("The trash is empty.")
+		return
+	}
+
+	cyan := color.New(color.FgCyan).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	faint := color.New(color.Faint).SprintFunc()
+
+	fmt.Printf("%d deleted contact(s):\n\n", len(items))
+	for _, item := range items {
+		fmt.Printf("%s  %s  %s (%s)", yellow(item.ID), item.DeletedAt.Local().Format("2006-01-02 15:04"),
+			cyan(displayNameOrPlaceholder(item.DisplayName)), extractID(item.ResourceName))
+		if item.Owner != "" {
+			fmt.Printf("  %s", faint(item.Owner))
+		}
+		fmt.Println()
+	}
+}
+
+func runTrashRestore(cmd *cobra.Command, args []string) error {
+	store, err := contactTrash()
+	if err != nil {
+		return err
+	}
+	items, err := store.List()
+	if err != nil {
+		return err
+	}
+	item, err := trash.Find(items, args[0])
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	// Get People API service
+	srv, err := contacts.GetPeopleService(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize service: %w", err)
+	}
+
+	restored, err := restoreFromTrash(ctx, srv, store, item)
+	if err != nil {
+		return err
+	}
+	invalidateContactCache()
+	recordOperation(ctx, srv, "trash restore", journal.OpCreate, nil, &contacts.ContactDetails{ResourceName: restored.ResourceName, DisplayName: restored.DisplayName})
+
+	displayRestored(restored)
+	return nil
+}
+
+// displayRestored prints a restored contact and what could not be restored.
+func displayRestored(restored *contacts.RestoredContact) {
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	fmt.Printf("%s Contact '%s' restored (%s)\n", green("✓"), restored.DisplayName, extractID(restored.ResourceName))
+	for _, w := range restored.Warnings {
+		fmt.Printf("  %s %s\n", yellow("!"), w)
+	}
+}
+
+func runTrashPurge(cmd *cobra.Command, args []string) error {
+	age, err := trash.ParseAge(trashPurgeOlderThan)
+	if err != nil {
+		return err
+	}
+	store, err := contactTrash()
+	if err != nil {
+		return err
+	}
+	items, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	before := time.Now().Add(-age)
+	count := 0
+	for _, item := range items {
+		if item.DeletedAt.Before(before) {
+			count++
+		}
+	}
+	if count == 0 {
+		fmt.Println("No deleted contact to purge.")
+		return nil
+	}
+	if !trashPurgeForce && !confirm(fmt.Sprintf("Permanently remove %d deleted contact(s) from the trash? (y/N): ", count), false) {
+		fmt.Println("Purge cancelled.")
+		return nil
+	}
+
+	purged, err := store.Purge(before)
+	if err != nil {
+		return err
+	}
+	green := color.New(color.FgGreen).SprintFunc()
+	fmt.Printf("%s %d deleted contact(s) purged\n", green("✓"), len(purged))
+	return nil
+}
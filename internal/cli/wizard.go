@@ -0,0 +1,474 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"google-contacts/internal/contacts"
+	"google-contacts/internal/journal"
+)
+
+// wizard asks for contact fields one by one on the terminal, for the
+// interactive create and update modes.
+type wizard struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// newWizard returns a wizard reading answers from stdin.
+func newWizard() *wizard {
+	return &wizard{in: bufio.NewReader(os.Stdin), out: os.Stdout}
+}
+
+// isInteractive reports whether stdin is a terminal and output is human-readable,
+// the conditions for running a wizard instead of failing on missing flags.
+func isInteractive() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0 && outputFormat == outputTable
+}
+
+// readLine reads one trimmed answer.
+func (w *wizard) readLine() (string, error) {
+	line, err := w.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("input ended before the wizard was completed")
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// ask prompts for a value until check accepts it. An empty answer keeps the
+// default shown in brackets, "-" clears it. check returns an optional
+// preview of how the value will be stored.
+func (w *wizard) ask(label, def string, check func(string) (string, error)) (string, error) {
+	red := color.New(color.FgRed).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+
+	for {
+		if def != "" {
+			fmt.Fprintf(w.out, "%s [%s]: ", label, def)
+		} else {
+			fmt.Fprintf(w.out, "%s: ", label)
+		}
+		answer, err := w.readLine()
+		if err != nil {
+			return "", err
+		}
+		switch answer {
+		case "":
+			answer = def
+		case "-":
+			answer = ""
+		}
+
+		if check == nil {
+			return answer, nil
+		}
+		preview, err := check(answer)
+		if err != nil {
+			fmt.Fprintf(w.out, "  %s %v\n", red("✗"), err)
+			continue
+		}
+		if preview != "" {
+			fmt.Fprintf(w.out, "  %s %s\n", green("→"), preview)
+		}
+		return answer, nil
+	}
+}
+
+// askList prompts for a list of "type:value" entries: each current entry can
+// be kept (Enter), replaced or removed ("-"), then new entries are added
+// until an empty answer.
+func (w *wizard) askList(label string, current []string, required bool, check func(string) (string, error)) ([]string, error) {
+	red := color.New(color.FgRed).SprintFunc()
+
+	// Empty answers are removals or the end of the list, not errors
+	checkEntry := func(value string) (string, error) {
+		if value == "" {
+			return "", nil
+		}
+		return check(value)
+	}
+
+	var values []string
+	for i, value := range current {
+		// Kept entries are not checked: they may use custom labels from Google
+		checkCurrent := func(answer string) (string, error) {
+			if answer == value {
+				return "", nil
+			}
+			return checkEntry(answer)
+		}
+		answer, err := w.ask(fmt.Sprintf("%s %d", label, i+1), value, checkCurrent)
+		if err != nil {
+			return nil, err
+		}
+		if answer != "" {
+			values = append(values, answer)
+		}
+	}
+
+	for {
+		answer, err := w.ask(fmt.Sprintf("Add %s (empty to finish)", strings.ToLower(label)), "", checkEntry)
+		if err != nil {
+			return nil, err
+		}
+		if answer != "" {
+			values = append(values, answer)
+			continue
+		}
+		if required && len(values) == 0 {
+			fmt.Fprintf(w.out, "  %s at least one %s is required\n", red("✗"), strings.ToLower(label))
+			continue
+		}
+		return values, nil
+	}
+}
+
+// confirm asks a yes/no question.
+func (w *wizard) confirm(prompt string, defaultYes bool) (bool, error) {
+	fmt.Fprint(w.out, prompt)
+	answer, err := w.readLine()
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(answer)
+	if answer == "" {
+		return defaultYes, nil
+	}
+	return answer == "y" || answer == "yes", nil
+}
+
+// requiredValue rejects empty answers.
+func requiredValue(name string) func(string) (string, error) {
+	return func(value string) (string, error) {
+		if value == "" {
+			return "", fmt.Errorf("%s is required", name)
+		}
+		return "", nil
+	}
+}
+
+// checkPhone validates a "type:number" answer and previews the normalized number.
+func checkPhone(value string) (string, error) {
+	phones, err := parsePhones([]string{value})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s (%s)", contacts.NormalizePhoneNumber(phones[0].Value), phones[0].Type), nil
+}
+
+// checkEmail validates a "type:email" answer.
+func checkEmail(value string) (string, error) {
+	emails, err := parseEmails([]string{value})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s (%s)", emails[0].Value, emails[0].Type), nil
+}
+
+// checkAddress validates a "type:address" answer and previews how it is split
+// into structured fields.
+func checkAddress(value string) (string, error) {
+	addresses, err := parseAddresses([]string{value})
+	if err != nil {
+		return "", err
+	}
+	return describeAddress(addresses[0]), nil
+}
+
+// describeAddress shows the structured fields parsed from an address.
+func describeAddress(addr contacts.AddressEntry) string {
+	parsed := contacts.ParseAddress(addr.Value)
+	if parsed == nil || (parsed.City == "" && parsed.PostalCode == "") {
+		return fmt.Sprintf("(%s) not recognized, stored as free text", addr.Type)
+	}
+
+	var parts []string
+	for _, field := range []struct{ name, value string }{
+		{"street", parsed.StreetAddress},
+		{"postal code", parsed.PostalCode},
+		{"city", parsed.City},
+		{"region", parsed.Region},
+		{"country", parsed.Country},
+	} {
+		if field.value != "" {
+			parts = append(parts, field.name+": "+field.value)
+		}
+	}
+	return fmt.Sprintf("(%s) %s", addr.Type, strings.Join(parts, ", "))
+}
+
+// checkBirthday validates a birthday answer and previews it.
+func checkBirthday(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if err := contacts.ValidateBirthday(value); err != nil {
+		return "", err
+	}
+	return formatBirthdayDisplay(value), nil
+}
+
+// askContactInput runs the create wizard, using the flag values as defaults.
+func (w *wizard) askContactInput(defaults contacts.ContactInput) (*contacts.ContactInput, error) {
+	input := &contacts.ContactInput{}
+	var err error
+
+	if input.FirstName, err = w.ask("First name", defaults.FirstName, requiredValue("first name")); err != nil {
+		return nil, err
+	}
+	if input.LastName, err = w.ask("Last name", defaults.LastName, requiredValue("last name")); err != nil {
+		return nil, err
+	}
+	input.LastName = contacts.FormatLastName(input.LastName, lastNameCase)
+
+	fmt.Fprintln(w.out, "Phones use 'type:number' (types: mobile, work, home, main, other)")
+	phones, err := w.askList("Phone", contacts.PhoneValues(defaults.Phones), true, checkPhone)
+	if err != nil {
+		return nil, err
+	}
+	if input.Phones, err = parsePhones(phones); err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintln(w.out, "Emails use 'type:email' (types: work, home, other)")
+	emails, err := w.askList("Email", contacts.EmailValues(defaults.Emails), false, checkEmail)
+	if err != nil {
+		return nil, err
+	}
+	if input.Emails, err = parseEmails(emails); err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintln(w.out, "Addresses use 'type:street, postal code city, country' (types: home, work, other)")
+	addresses, err := w.askList("Address", contacts.AddressValues(defaults.Addresses), false, checkAddress)
+	if err != nil {
+		return nil, err
+	}
+	if input.Addresses, err = parseAddresses(addresses); err != nil {
+		return nil, err
+	}
+
+	if input.Company, err = w.ask("Company", defaults.Company, nil); err != nil {
+		return nil, err
+	}
+	if input.Position, err = w.ask("Position", defaults.Position, nil); err != nil {
+		return nil, err
+	}
+	if input.Birthday, err = w.ask("Birthday (YYYY-MM-DD or --MM-DD)", defaults.Birthday, checkBirthday); err != nil {
+		return nil, err
+	}
+	if input.Notes, err = w.ask("Notes", defaults.Notes, nil); err != nil {
+		return nil, err
+	}
+	return input, nil
+}
+
+// askEditDocument runs the update wizard, starting from the current contact.
+func (w *wizard) askEditDocument(c *contacts.ContactDetails) (*editDocument, error) {
+	doc := &editDocument{}
+	var err error
+
+	fmt.Fprintln(w.out, "Press Enter to keep a value, '-' to clear it.")
+	if doc.FirstName, err = w.ask("First name", c.FirstName, nil); err != nil {
+		return nil, err
+	}
+	if doc.LastName, err = w.ask("Last name", c.LastName, nil); err != nil {
+		return nil, err
+	}
+
+	phones, err := w.askList("Phone", contacts.PhoneValues(c.Phones), false, checkPhone)
+	if err != nil {
+		return nil, err
+	}
+	doc.Phones = wizardEntries(phones, contacts.PhoneTypes...)
+
+	emails, err := w.askList("Email", contacts.EmailValues(c.Emails), false, checkEmail)
+	if err != nil {
+		return nil, err
+	}
+	doc.Emails = wizardEntries(emails, contacts.EmailTypes...)
+
+	addresses, err := w.askList("Address", contacts.AddressValues(c.Addresses), false, checkAddress)
+	if err != nil {
+		return nil, err
+	}
+	doc.Addresses = wizardEntries(addresses, contacts.AddressTypes...)
+
+	if doc.Company, err = w.ask("Company", c.Company, nil); err != nil {
+		return nil, err
+	}
+	if doc.Position, err = w.ask("Position", c.Position, nil); err != nil {
+		return nil, err
+	}
+	if doc.Birthday, err = w.ask("Birthday (YYYY-MM-DD or --MM-DD)", c.Birthday, checkBirthday); err != nil {
+		return nil, err
+	}
+	if doc.Notes, err = w.ask("Notes", c.Notes, nil); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// wizardEntries converts "type:value" answers to edit entries. A prefix that
+// is not one of the types is part of the value, as with the flag parsers.
+func wizardEntries(values []string, types ...string) []editEntry {
+	var entries []editEntry
+	for _, v := range values {
+		entry := editEntry{Value: v}
+		if idx := strings.Index(v, ":"); idx > 0 {
+			for _, t := range types {
+				if strings.EqualFold(v[:idx], t) {
+					entry = editEntry{Type: t, Value: v[idx+1:]}
+					break
+				}
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// createDefaults returns the values given with create flags, used as
+// wizard defaults.
+func createDefaults() (contacts.ContactInput, error) {
+	phones, err := parsePhones(createPhones)
+	if err != nil {
+		return contacts.ContactInput{}, fmt.Errorf("invalid phone format: %w", err)
+	}
+	emails, err := parseEmails(createEmails)
+	if err != nil {
+		return contacts.ContactInput{}, fmt.Errorf("invalid email format: %w", err)
+	}
+	addresses, err := parseAddresses(createAddresses)
+	if err != nil {
+		return contacts.ContactInput{}, fmt.Errorf("invalid address format: %w", err)
+	}
+	return contacts.ContactInput{
+		FirstName: createFirstName,
+		LastName:  createLastName,
+		Phones:    phones,
+		Emails:    emails,
+		Addresses: addresses,
+		Company:   createCompany,
+		Position:  createPosition,
+		Notes:     createNotes,
+		Birthday:  createBirthday,
+	}, nil
+}
+
+// runCreateWizard interactively creates a contact.
+func runCreateWizard(defaults contacts.ContactInput) error {
+	w := newWizard()
+	input, err := w.askContactInput(defaults)
+	if err != nil {
+		return err
+	}
+
+	// Confirmation screen
+	fmt.Fprintln(w.out)
+	displayFullContactDetails(&contacts.ContactDetails{
+		FirstName:   input.FirstName,
+		LastName:    input.LastName,
+		DisplayName: input.FirstName + " " + input.LastName,
+		Phones:      input.Phones,
+		Emails:      input.Emails,
+		Addresses:   input.Addresses,
+		Company:     input.Company,
+		Position:    input.Position,
+		Notes:       input.Notes,
+		Birthday:    input.Birthday,
+	})
+	fmt.Fprintln(w.out)
+	ok, err := w.confirm("Create this contact? (Y/n): ", true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		fmt.Println("Creation cancelled.")
+		return nil
+	}
+
+	ctx := context.Background()
+
+	// Get People API service
+	srv, err := contacts.GetPeopleService(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize service: %w", err)
+	}
+
+	created, err := srv.CreateContact(ctx, *input)
+	if err != nil {
+		return err
+	}
+	invalidateContactCache()
+	recordOperation(ctx, srv, "create", journal.OpCreate, nil, &contacts.ContactDetails{ResourceName: created.ResourceName, DisplayName: created.DisplayName})
+
+	green := color.New(color.FgGreen).SprintFunc()
+	fmt.Printf("%s Contact '%s' created (%s)\n", green("✓"), created.DisplayName, extractID(created.ResourceName))
+	return nil
+}
+
+// runUpdateWizard interactively updates a contact.
+func runUpdateWizard(contactID string) error {
+	ctx := context.Background()
+
+	// Get People API service
+	srv, err := contacts.GetPeopleService(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize service: %w", err)
+	}
+
+	contactID, err = resolveContact(ctx, srv, contactID)
+	if err != nil {
+		return err
+	}
+
+	before, err := srv.GetContactDetails(ctx, contactID)
+	if err != nil {
+		return err
+	}
+
+	w := newWizard()
+	doc, err := w.askEditDocument(before)
+	if err != nil {
+		return err
+	}
+	input, changes, err := editUpdateInput(before, doc)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Println("No changes.")
+		return nil
+	}
+
+	// Confirmation screen
+	fmt.Fprintln(w.out)
+	displayFieldChanges(changes)
+	fmt.Fprintln(w.out)
+	ok, err := w.confirm("Apply these changes? (Y/n): ", true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		fmt.Println("Update cancelled.")
+		return nil
+	}
+
+	after, err := srv.UpdateContact(ctx, before.ResourceName, *input)
+	if err != nil {
+		return err
+	}
+	invalidateContactCache()
+	recordOperation(ctx, srv, "update", journal.OpUpdate, before, after)
+
+	displayUpdateSummary(before, after)
+	return nil
+}
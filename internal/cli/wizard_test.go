@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"google-contacts/internal/contacts"
+)
+
+// newTestWizard returns a wizard answering with the given lines.
+func newTestWizard(lines ...string) (*wizard, *strings.Builder) {
+	out := &strings.Builder{}
+	return &wizard{in: bufio.NewReader(strings.NewReader(strings.Join(lines, "\n") + "\n")), out: out}, out
+}
+
+func TestWizard_AskContactInput(t *testing.T) {
+	w, out := newTestWizard(
+		"",               // first name: required, asked again
+		"John",           // first name
+		"Doe",            // last name
+		"",               // phone: at least one required
+		"fax:0612345678", // invalid phone type, asked again
+		"0612345678",     // phone (defaults to mobile)
+		"",               // end of phones
+		"home:john@",     // invalid email, asked again
+		"home:john@example.com",
+		"",
+		"work:50 Avenue Business, 69001 Lyon, France",
+		"",
+		"Acme",
+		"",           // position
+		"15/03/1985", // invalid birthday, asked again
+		"--03-15",
+		"", // notes
+	)
+
+	input, err := w.askContactInput(contacts.ContactInput{})
+	if err != nil {
+		t.Fatalf("askContactInput() error: %v", err)
+	}
+
+	if input.FirstName != "John" || input.LastName != "Doe" || input.Company != "Acme" || input.Birthday != "--03-15" {
+		t.Errorf("askContactInput() = %+v", input)
+	}
+	if len(input.Phones) != 1 || input.Phones[0].Type != "mobile" {
+		t.Errorf("Phones = %+v, want one mobile phone", input.Phones)
+	}
+	if len(input.Emails) != 1 || input.Emails[0].Type != "home" {
+		t.Errorf("Emails = %+v, want one home email", input.Emails)
+	}
+	if len(input.Addresses) != 1 || input.Addresses[0].Type != "work" {
+		t.Errorf("Addresses = %+v, want one work address", input.Addresses)
+	}
+
+	for _, want := range []string{
+		"first name is required",
+		"at least one phone is required",
+		"invalid phone type 'fax'",
+		"→ +33612345678 (mobile)",
+		"(work) street: 50 Avenue Business, postal code: 69001, city: Lyon, country: France",
+		"invalid birthday",
+		"→ March 15",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("wizard output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestWizard_AskContactInput_Defaults(t *testing.T) {
+	// Flag values are defaults: Enter keeps them
+	w, _ := newTestWizard("", "Doe", "", "", "", "", "", "", "", "")
+	input, err := w.askContactInput(contacts.ContactInput{
+		FirstName: "John",
+		Phones:    []contacts.PhoneEntry{{Value: "+33612345678", Type: "work"}},
+	})
+	if err != nil {
+		t.Fatalf("askContactInput() error: %v", err)
+	}
+	if input.FirstName != "John" || len(input.Phones) != 1 || input.Phones[0].Type != "work" {
+		t.Errorf("askContactInput() = %+v", input)
+	}
+}
+
+func TestWizard_AskEditDocument(t *testing.T) {
+	c := &contacts.ContactDetails{
+		FirstName: "Jane",
+		LastName:  "DOE",
+		Phones:    []contacts.PhoneEntry{{Value: "+33100000000", Type: "fax"}, {Value: "+33612345678", Type: "mobile"}},
+		Company:   "Acme",
+		Birthday:  "1985-03-15",
+	}
+
+	// Keep everything: no changes, custom phone labels are accepted as-is
+	w, _ := newTestWizard("", "", "", "", "", "", "", "", "", "", "")
+	doc, err := w.askEditDocument(c)
+	if err != nil {
+		t.Fatalf("askEditDocument() error: %v", err)
+	}
+	if _, changes, err := editUpdateInput(c, doc); err != nil || len(changes) != 0 {
+		t.Errorf("keeping all values produced changes %+v, err %v", changes, err)
+	}
+
+	// Remove the fax, add a work phone, clear the company and birthday
+	w, _ = newTestWizard("", "", "-", "", "work:+33199999999", "", "", "", "-", "", "-", "")
+	doc, err = w.askEditDocument(c)
+	if err != nil {
+		t.Fatalf("askEditDocument() error: %v", err)
+	}
+	input, changes, err := editUpdateInput(c, doc)
+	if err != nil {
+		t.Fatalf("editUpdateInput() error: %v", err)
+	}
+	if len(input.Phones) != 2 || input.Phones[1].Value != "+33199999999" {
+		t.Errorf("Phones = %+v", input.Phones)
+	}
+	if input.Company == nil || *input.Company != "" || !input.ClearBirthday {
+		t.Errorf("company and birthday should be cleared: %+v", input)
+	}
+	if len(changes) != 4 {
+		t.Errorf("changes = %+v, want 4", changes)
+	}
+}
+
+func TestWizard_InputEnded(t *testing.T) {
+	w, _ := newTestWizard("John")
+	if _, err := w.askContactInput(contacts.ContactInput{}); err == nil {
+		t.Error("askContactInput() expected error when input ends")
+	}
+}
+
+func TestDescribeAddress(t *testing.T) {
+	got := describeAddress(contacts.AddressEntry{Value: "somewhere", Type: "home"})
+	if got != "(home) not recognized, stored as free text" {
+		t.Errorf("describeAddress() = %q", got)
+	}
+}
@@ -0,0 +1,149 @@
+// Package config loads the user configuration file and resolves settings
+// with the precedence flag > environment variable > file > default.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"google-contacts/internal/contacts"
+	mcpserver "google-contacts/internal/mcp"
+	"google-contacts/pkg/auth"
+)
+
+// EnvConfig is the environment variable selecting the configuration file.
+const EnvConfig = "GOOGLE_CONTACTS_CONFIG"
+
+// Config is the content of the configuration file. Empty values keep the
+// built-in defaults.
+type Config struct {
+	Profile        string `yaml:"profile,omitempty"`        // Default Google account profile
+	Output         string `yaml:"output,omitempty"`         // Default output format
+	PhoneRegion    string `yaml:"phoneRegion,omitempty"`    // Region of national phone numbers (FR, GB...)
+	PhoneType      string `yaml:"phoneType,omitempty"`      // Type of phones given without one
+	EmailType      string `yaml:"emailType,omitempty"`      // Type of emails given without one
+	AddressType    string `yaml:"addressType,omitempty"`    // Type of addresses given without one
+	LastNameCase   string `yaml:"lastNameCase,omitempty"`   // preserve, upper or title
+	CredentialsDir string `yaml:"credentialsDir,omitempty"` // OAuth credentials and token directory
+	Scopes         string `yaml:"scopes,omitempty"`         // OAuth scope set: contacts-readonly, contacts or unified
+	TokenStore     string `yaml:"tokenStore,omitempty"`     // file, encrypted or env
+	TokenKeyFile   string `yaml:"tokenKeyFile,omitempty"`   // Key of the encrypted store (passphrase if empty)
+	ServiceAccount string `yaml:"serviceAccount,omitempty"` // Service account key with domain-wide delegation
+	Impersonate    string `yaml:"impersonate,omitempty"`    // Workspace user the service account acts as
+	MCP            MCP    `yaml:"mcp,omitempty"`
+}
+
+// MCP holds the settings of the MCP server command.
+type MCP struct {
+	Host              string `yaml:"host,omitempty"`
+	Port              int    `yaml:"port,omitempty"`
+	BaseURL           string `yaml:"baseUrl,omitempty"`
+	SecretName        string `yaml:"secretName,omitempty"`
+	SecretProject     string `yaml:"secretProject,omitempty"`
+	CredentialFile    string `yaml:"credentialFile,omitempty"`
+	CalendarFeed      bool   `yaml:"calendarFeed,omitempty"`
+	CalendarToken     string `yaml:"calendarToken,omitempty"`
+	CalendarAlarmDays []int  `yaml:"calendarAlarmDays,omitempty"`
+	LastNameCase      string `yaml:"lastNameCase,omitempty"`      // Defaults to upper
+	Scopes            string `yaml:"scopes,omitempty"`            // Defaults to contacts
+	ServiceAccount    string `yaml:"serviceAccount,omitempty"`    // Act as each caller with domain-wide delegation
+	ImpersonateDomain string `yaml:"impersonateDomain,omitempty"` // Only serve callers of this Workspace domain
+	OAuthStore        string `yaml:"oauthStore,omitempty"`        // Defaults to file
+	OAuthStorePath    string `yaml:"oauthStorePath,omitempty"`    // File store location
+	OAuthKeyFile      string `yaml:"oauthKeyFile,omitempty"`      // Key encrypting the Google tokens of the store
+	FirestoreProject  string `yaml:"firestoreProject,omitempty"`  // Firestore store project
+	FirestoreDatabase string `yaml:"firestoreDatabase,omitempty"` // Defaults to (default)
+}
+
+// DefaultPath returns the configuration file location in the user config directory.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "google-contacts", "config.yaml"), nil
+}
+
+// Load reads and validates a configuration file. A missing file is an empty
+// configuration unless required is set (the path was given explicitly).
+func Load(path string, required bool) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates a configuration. Unknown keys are rejected
+// so that typos do not go unnoticed.
+func Parse(data []byte) (*Config, error) {
+	var c Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("invalid config file: %w", err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file: %w", err)
+	}
+	return &c, nil
+}
+
+// Validate checks the values that have a fixed set of choices.
+func (c *Config) Validate() error {
+	if c.Profile != "" {
+		if err := auth.ValidateProfile(c.Profile); err != nil {
+			return err
+		}
+	}
+	if c.Impersonate != "" {
+		if err := auth.ValidateSubject(c.Impersonate); err != nil {
+			return err
+		}
+	}
+	if c.PhoneRegion != "" && !slices.Contains(contacts.PhoneRegions(), strings.ToUpper(c.PhoneRegion)) {
+		return fmt.Errorf("invalid phoneRegion '%s', valid regions: %s", c.PhoneRegion, strings.Join(contacts.PhoneRegions(), ", "))
+	}
+	checks := []struct {
+		key, value string
+		valid      []string
+	}{
+		{"phoneType", c.PhoneType, contacts.PhoneTypes},
+		{"emailType", c.EmailType, contacts.EmailTypes},
+		{"addressType", c.AddressType, contacts.AddressTypes},
+		{"lastNameCase", c.LastNameCase, contacts.LastNameCases},
+		{"mcp.lastNameCase", c.MCP.LastNameCase, contacts.LastNameCases},
+		{"scopes", c.Scopes, auth.ScopeSets},
+		{"tokenStore", c.TokenStore, auth.TokenStores},
+		{"mcp.scopes", c.MCP.Scopes, auth.ScopeSets},
+		{"mcp.oauthStore", c.MCP.OAuthStore, mcpserver.OAuthStores},
+	}
+	for _, check := range checks {
+		if err := CheckChoice(check.key, check.value, check.valid); err != nil {
+			return err
+		}
+	}
+	if c.MCP.Port < 0 || c.MCP.Port > 65535 {
+		return fmt.Errorf("invalid mcp.port %d", c.MCP.Port)
+	}
+	return nil
+}
+
+// CheckChoice checks that a non-empty setting value is one of valid.
+func CheckChoice(key, value string, valid []string) error {
+	if value == "" || slices.Contains(valid, value) {
+		return nil
+	}
+	return fmt.Errorf("invalid %s '%s', valid values: %s", key, value, strings.Join(valid, ", "))
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"empty", "", ""},
+		{"full", "output: json\nphoneRegion: gb\nphoneType: work\nlastNameCase: upper\nmcp:\n  port: 9090\n  calendarAlarmDays: [1, 7]\n", ""},
+		{"unknown key", "outptu: json\n", "field outptu not found"},
+		{"invalid region", "phoneRegion: XX\n", "invalid phoneRegion"},
+		{"invalid type", "emailType: mobile\n", "invalid emailType"},
+		{"invalid case", "mcp:\n  lastNameCase: lower\n", "invalid mcp.lastNameCase"},
+		{"invalid port", "mcp:\n  port: 70000\n", "invalid mcp.port"},
+		{"scopes", "scopes: contacts-readonly\nmcp:\n  scopes: contacts\n", ""},
+		{"invalid scopes", "scopes: gmail\n", "invalid scopes"},
+		{"invalid token store", "tokenStore: keychain\n", "invalid tokenStore"},
+		{"impersonate", "serviceAccount: sa.json\nimpersonate: jane@example.com\n", ""},
+		{"invalid impersonate", "impersonate: jane\n", "invalid user to impersonate"},
+		{"oauth store", "mcp:\n  oauthStore: firestore\n  firestoreProject: my-project\n", ""},
+		{"invalid oauth store", "mcp:\n  oauthStore: redis\n", "invalid mcp.oauthStore"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse([]byte(tc.data))
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("Parse() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Parse() error = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_Missing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	c, err := Load(path, false)
+	if err != nil || c == nil {
+		t.Errorf("Load(missing, false) = %v, %v, want empty config", c, err)
+	}
+	if _, err := Load(path, true); err == nil {
+		t.Error("Load(missing, true) should fail")
+	}
+
+	if err := os.WriteFile(path, []byte("phoneType: home\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	c, err = Load(path, true)
+	if err != nil || c.PhoneType != "home" {
+		t.Errorf("Load() = %+v, %v", c, err)
+	}
+}
+
+func TestResolver_Precedence(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("output", "table", "")
+	flags.Int("port", 8080, "")
+	if err := flags.Parse([]string{"--output", "yaml"}); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"OUTPUT": "json", "PORT": "9000", "EMPTY": ""}
+	r := NewResolver(flags)
+	r.lookup = func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	if got := r.String(Spec{Key: "output", Flag: "output", Envs: []string{"OUTPUT"}}, "csv", "table"); got != "yaml" {
+		t.Errorf("flag: got %q, want yaml", got)
+	}
+	if got, err := r.Int(Spec{Key: "port", Flag: "port", Envs: []string{"PORT"}}, 7000, 8080); err != nil || got != 9000 {
+		t.Errorf("env: got %d, %v, want 9000", got, err)
+	}
+	if got := r.String(Spec{Key: "region", Envs: []string{"EMPTY"}}, "GB", "FR"); got != "GB" {
+		t.Errorf("file: got %q, want GB", got)
+	}
+	if got, err := r.Ints(Spec{Key: "days"}, nil, []int{1}); err != nil || !slices.Equal(got, []int{1}) {
+		t.Errorf("default: got %v, %v, want [1]", got, err)
+	}
+
+	var sources []Source
+	for _, s := range r.Settings() {
+		sources = append(sources, s.Source)
+	}
+	want := []Source{SourceFlag, SourceEnv, SourceFile, SourceDefault}
+	if !slices.Equal(sources, want) {
+		t.Errorf("sources = %v, want %v", sources, want)
+	}
+}
+
+func TestResolver_InvalidEnv(t *testing.T) {
+	r := NewResolver(nil)
+	r.lookup = func(string) (string, bool) { return "abc", true }
+
+	if _, err := r.Int(Spec{Key: "port", Envs: []string{"PORT"}}, 0, 8080); err == nil {
+		t.Error("Int() should reject a non-numeric value")
+	}
+	if _, err := r.Bool(Spec{Key: "feed", Envs: []string{"FEED"}}, false, false); err == nil {
+		t.Error("Bool() should reject a non-boolean value")
+	}
+}
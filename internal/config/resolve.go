@@ -0,0 +1,180 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Source tells where a setting value comes from.
+type Source string
+
+// Setting sources, by decreasing precedence.
+const (
+	SourceFlag    Source = "flag"
+	SourceEnv     Source = "env"
+	SourceFile    Source = "file"
+	SourceDefault Source = "default"
+)
+
+// Setting is a resolved setting value and its origin. Origin names the flag
+// or environment variable when the value comes from one.
+type Setting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source Source `json:"source"`
+	Origin string `json:"origin,omitempty"`
+	Secret bool   `json:"-"`
+}
+
+// Resolver resolves settings with the precedence flag > environment
+// variable > file > default, and remembers where each value came from.
+type Resolver struct {
+	flags    *pflag.FlagSet
+	lookup   func(string) (string, bool)
+	settings []Setting
+}
+
+// NewResolver returns a resolver reading the given flags (nil for none) and
+// the process environment.
+func NewResolver(flags *pflag.FlagSet) *Resolver {
+	return &Resolver{flags: flags, lookup: os.LookupEnv}
+}
+
+// Settings returns the settings resolved so far, in resolution order.
+func (r *Resolver) Settings() []Setting {
+	return r.settings
+}
+
+// Spec describes where a setting is read from. Flag and Envs are optional;
+// the first environment variable set wins.
+type Spec struct {
+	Key    string
+	Flag   string
+	Envs   []string
+	Secret bool // Masked by config show
+}
+
+// String resolves a string setting. An empty file value means unset.
+func (r *Resolver) String(spec Spec, file, def string) string {
+	if r.flags != nil && spec.Flag != "" && r.flags.Changed(spec.Flag) {
+		value, _ := r.flags.GetString(spec.Flag)
+		return r.record(spec, value, SourceFlag, "--"+spec.Flag)
+	}
+	if value, env, ok := r.env(spec); ok {
+		return r.record(spec, value, SourceEnv, env)
+	}
+	if file != "" {
+		return r.record(spec, file, SourceFile, "")
+	}
+	return r.record(spec, def, SourceDefault, "")
+}
+
+// Int resolves an integer setting. A zero file value means unset.
+func (r *Resolver) Int(spec Spec, file, def int) (int, error) {
+	if r.flags != nil && spec.Flag != "" && r.flags.Changed(spec.Flag) {
+		value, _ := r.flags.GetInt(spec.Flag)
+		r.record(spec, strconv.Itoa(value), SourceFlag, "--"+spec.Flag)
+		return value, nil
+	}
+	if value, env, ok := r.env(spec); ok {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s '%s': expected a number", env, value)
+		}
+		r.record(spec, value, SourceEnv, env)
+		return n, nil
+	}
+	if file != 0 {
+		r.record(spec, strconv.Itoa(file), SourceFile, "")
+		return file, nil
+	}
+	r.record(spec, strconv.Itoa(def), SourceDefault, "")
+	return def, nil
+}
+
+// Bool resolves a boolean setting. A false file value means unset.
+func (r *Resolver) Bool(spec Spec, file, def bool) (bool, error) {
+	if r.flags != nil && spec.Flag != "" && r.flags.Changed(spec.Flag) {
+		value, _ := r.flags.GetBool(spec.Flag)
+		r.record(spec, strconv.FormatBool(value), SourceFlag, "--"+spec.Flag)
+		return value, nil
+	}
+	if value, env, ok := r.env(spec); ok {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return false, fmt.Errorf("invalid %s '%s': expected true or false", env, value)
+		}
+		r.record(spec, value, SourceEnv, env)
+		return b, nil
+	}
+	if file {
+		r.record(spec, "true", SourceFile, "")
+		return true, nil
+	}
+	r.record(spec, strconv.FormatBool(def), SourceDefault, "")
+	return def, nil
+}
+
+// Ints resolves an integer list setting, given as a comma-separated list in
+// environment variables.
+func (r *Resolver) Ints(spec Spec, file, def []int) ([]int, error) {
+	if r.flags != nil && spec.Flag != "" && r.flags.Changed(spec.Flag) {
+		value, _ := r.flags.GetIntSlice(spec.Flag)
+		r.record(spec, formatInts(value), SourceFlag, "--"+spec.Flag)
+		return value, nil
+	}
+	if value, env, ok := r.env(spec); ok {
+		var list []int
+		for _, part := range strings.Split(value, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s '%s': expected comma-separated numbers", env, value)
+			}
+			list = append(list, n)
+		}
+		r.record(spec, value, SourceEnv, env)
+		return list, nil
+	}
+	if len(file) > 0 {
+		r.record(spec, formatInts(file), SourceFile, "")
+		return file, nil
+	}
+	r.record(spec, formatInts(def), SourceDefault, "")
+	return def, nil
+}
+
+// env returns the value of the first environment variable of spec that is
+// set and not empty.
+func (r *Resolver) env(spec Spec) (string, string, bool) {
+	for _, name := range spec.Envs {
+		if value, ok := r.lookup(name); ok && value != "" {
+			return value, name, true
+		}
+	}
+	return "", "", false
+}
+
+// record remembers a resolved setting and returns its value.
+func (r *Resolver) record(spec Spec, value string, source Source, origin string) string {
+	r.settings = append(r.settings, Setting{
+		Key:    spec.Key,
+		Value:  value,
+		Source: source,
+		Origin: origin,
+		Secret: spec.Secret,
+	})
+	return value
+}
+
+// formatInts formats an integer list as "1,7".
+func formatInts(list []int) string {
+	parts := make([]string, len(list))
+	for i, n := range list {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,197 @@
+package contacts
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	people "google.golang.org/api/people/v1"
+)
+
+// archivePersonFields lists every readable person field, so that an archived
+// contact can be restored with all its data.
+const archivePersonFields = "addresses,ageRanges,biographies,birthdays,calendarUrls,clientData," +
+	"coverPhotos,emailAddresses,events,externalIds,genders,imClients,interests,locales,locations," +
+	"memberships,metadata,miscKeywords,names,nicknames,occupations,organizations,phoneNumbers," +
+	"photos,relations,sipAddresses,skills,urls,userDefined"
+
+// maxPhotoSize bounds the size of an archived contact photo.
+const maxPhotoSize = 10 << 20
+
+// ContactArchive is the complete state of a contact, kept to restore it
+// after deletion.
+type ContactArchive struct {
+	Person *people.Person `json:"person"`
+	Photo  []byte         `json:"photo,omitempty"` // Custom contact photo, if any
+}
+
+// RestoredContact is the result of RestoreContact. Warnings lists the parts
+// of the archive that could not be restored (group memberships, photo).
+type RestoredContact struct {
+	CreatedContact
+	Warnings []string
+}
+
+// Details returns the archived contact in the ContactDetails form.
+func (a *ContactArchive) Details() *ContactDetails {
+	return contactDetailsFromPerson(a.Person)
+}
+
+// ArchiveContact fetches every field of a contact, its group memberships
+// and its custom photo.
+func (s *Service) ArchiveContact(ctx context.Context, resourceName string) (*ContactArchive, error) {
+	// Normalize resource name
+	if len(resourceName) > 0 && resourceName[0] != 'p' {
+		resourceName = "people/" + resourceName
+	}
+
+	p, err := s.People.Get(resourceName).
+		PersonFields(archivePersonFields).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact: %w", err)
+	}
+
+	archive := &ContactArchive{Person: p}
+	for _, photo := range p.Photos {
+		if photo.Default || photo.Url == "" {
+			continue
+		}
+		data, err := downloadPhoto(ctx, photo.Url)
+		if err != nil {
+			return nil, err
+		}
+		archive.Photo = data
+		break
+	}
+	return archive, nil
+}
+
+// downloadPhoto fetches a contact photo from its googleusercontent URL.
+func downloadPhoto(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download contact photo: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download contact photo: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download contact photo: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPhotoSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download contact photo: %w", err)
+	}
+	return data, nil
+}
+
+// RestoreContact recreates an archived contact, then adds it back to its
+// user contact groups and uploads its photo. The restored contact gets a
+// new resource name. Failures after the creation are reported as warnings.
+func (s *Service) RestoreContact(ctx context.Context, archive *ContactArchive) (*RestoredContact, error) {
+	person, err := restorablePerson(archive.Person)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.People.CreateContact(person).
+		PersonFields("names").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create contact: %w", err)
+	}
+
+	result := &RestoredContact{CreatedContact: CreatedContact{ResourceName: created.ResourceName}}
+	if len(created.Names) > 0 {
+		result.DisplayName = created.Names[0].DisplayName
+	}
+
+	for _, group := range restorableGroups(archive.Person) {
+		_, err := s.ContactGroups.Members.Modify(group, &people.ModifyContactGroupMembersRequest{
+			ResourceNamesToAdd: []string{created.ResourceName},
+		}).Context(ctx).Do()
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to add contact to %s: %v", group, err))
+		}
+	}
+
+	if len(archive.Photo) > 0 {
+		_, err := s.People.UpdateContactPhoto(created.ResourceName, &people.UpdateContactPhotoRequest{
+			PhotoBytes: base64.StdEncoding.EncodeToString(archive.Photo),
+		}).Context(ctx).Do()
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to restore photo: %v", err))
+		}
+	}
+
+	return result, nil
+}
+
+// restorablePerson returns a copy of an archived person without the
+// identifiers and read-only fields rejected by createContact. Memberships
+// and photos are restored separately.
+func restorablePerson(p *people.Person) (*people.Person, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy archived contact: %w", err)
+	}
+	var person people.Person
+	if err := json.Unmarshal(data, &person); err != nil {
+		return nil, fmt.Errorf("failed to copy archived contact: %w", err)
+	}
+
+	person.ResourceName = ""
+	person.Etag = ""
+	person.Metadata = nil
+	person.Memberships = nil
+	person.Photos = nil
+	person.CoverPhotos = nil
+	person.AgeRanges = nil
+	return &person, nil
+}
+
+// restorableGroups returns the contact groups of an archived person that
+// must be joined explicitly: myContacts is implied by the creation.
+func restorableGroups(p *people.Person) []string {
+	var groups []string
+	for _, m := range p.Memberships {
+		if m.ContactGroupMembership == nil {
+			continue
+		}
+		group := m.ContactGroupMembership.ContactGroupResourceName
+		if group == "" || group == "contactGroups/myContacts" || !strings.HasPrefix(group, "contactGroups/") {
+			continue
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// AccountEmail returns the primary email address of the authenticated user.
+func (s *Service) AccountEmail(ctx context.Context) (string, error) {
+	me, err := s.People.Get("people/me").
+		PersonFields("emailAddresses").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to get account: %w", err)
+	}
+	for _, email := range me.EmailAddresses {
+		if email.Metadata != nil && email.Metadata.Primary {
+			return email.Value, nil
+		}
+	}
+	if len(me.EmailAddresses) > 0 {
+		return me.EmailAddresses[0].Value, nil
+	}
+	return "", fmt.Errorf("no email address found for the account")
+}
@@ -0,0 +1,63 @@
+package contacts
+
+import (
+	"slices"
+	"testing"
+
+	people "google.golang.org/api/people/v1"
+)
+
+func archivedPerson() *people.Person {
+	return &people.Person{
+		ResourceName: "people/c1",
+		Etag:         "etag",
+		Metadata:     &people.PersonMetadata{ObjectType: "PERSON"},
+		Names:        []*people.Name{{GivenName: "Jane", FamilyName: "DOE", DisplayName: "Jane DOE"}},
+		Nicknames:    []*people.Nickname{{Value: "JD"}},
+		Photos:       []*people.Photo{{Url: "https://example.com/photo.jpg"}},
+		Memberships: []*people.Membership{
+			{ContactGroupMembership: &people.ContactGroupMembership{ContactGroupResourceName: "contactGroups/myContacts"}},
+			{ContactGroupMembership: &people.ContactGroupMembership{ContactGroupResourceName: "contactGroups/starred"}},
+			{ContactGroupMembership: &people.ContactGroupMembership{ContactGroupResourceName: "contactGroups/1a2b"}},
+			{DomainMembership: &people.DomainMembership{InViewerDomain: true}},
+		},
+	}
+}
+
+func TestRestorablePerson(t *testing.T) {
+	original := archivedPerson()
+	p, err := restorablePerson(original)
+	if err != nil {
+		t.Fatalf("restorablePerson() error = %v", err)
+	}
+
+	if p.ResourceName != "" || p.Etag != "" || p.Metadata != nil || p.Photos != nil || p.Memberships != nil {
+		t.Errorf("restorablePerson() kept read-only fields: %+v", p)
+	}
+	if len(p.Names) != 1 || p.Names[0].GivenName != "Jane" || len(p.Nicknames) != 1 {
+		t.Errorf("restorablePerson() lost contact data: %+v", p)
+	}
+	// The archive itself is left untouched
+	if original.ResourceName != "people/c1" || len(original.Memberships) != 4 {
+		t.Error("restorablePerson() modified the archived person")
+	}
+}
+
+func TestRestorableGroups(t *testing.T) {
+	got := restorableGroups(archivedPerson())
+	want := []string{"contactGroups/starred", "contactGroups/1a2b"}
+	if !slices.Equal(got, want) {
+		t.Errorf("restorableGroups() = %v, want %v", got, want)
+	}
+}
+
+func TestContactArchive_Details(t *testing.T) {
+	archive := &ContactArchive{Person: archivedPerson()}
+	details := archive.Details()
+	if details.ResourceName != "people/c1" || details.DisplayName != "Jane DOE" {
+		t.Errorf("Details() = %+v", details)
+	}
+	if !slices.Contains(details.Groups, "contactGroups/1a2b") {
+		t.Errorf("Details().Groups = %v, want the archived memberships", details.Groups)
+	}
+}
@@ -0,0 +1,374 @@
+package contacts
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// AuditRule identifies a contact data-quality check.
+type AuditRule string
+
+// Available audit rules.
+const (
+	RulePhoneNotE164       AuditRule = "phone-not-e164"
+	RuleEmailMalformed     AuditRule = "email-malformed"
+	RuleDuplicateEmail     AuditRule = "duplicate-email"
+	RuleAddressIncomplete  AuditRule = "address-incomplete"
+	RuleMissingName        AuditRule = "missing-name"
+	RuleEmptyContact       AuditRule = "empty-contact"
+	RuleSharedPhone        AuditRule = "shared-phone"
+	RuleSuspiciousBirthday AuditRule = "suspicious-birthday"
+)
+
+// AuditRules lists all audit rules in display order.
+var AuditRules = []AuditRule{
+	RulePhoneNotE164,
+	RuleEmailMalformed,
+	RuleDuplicateEmail,
+	RuleAddressIncomplete,
+	RuleMissingName,
+	RuleEmptyContact,
+	RuleSharedPhone,
+	RuleSuspiciousBirthday,
+}
+
+// AuditFinding describes a single data-quality problem on a contact.
+type AuditFinding struct {
+	Rule         AuditRule
+	ResourceName string
+	DisplayName  string
+	Field        string // phones, emails, addresses, names, birthday, or contact
+	Value        string // Offending value (empty for contact-level findings)
+	Message      string // What is wrong
+	Suggestion   string // How to fix it
+}
+
+// AuditOptions controls which rules are run.
+type AuditOptions struct {
+	Rules []AuditRule // Rules to run (all rules if empty)
+	Now   time.Time   // Reference time for birthday checks (defaults to time.Now)
+}
+
+// e164Regex matches phone numbers in E.164 format (+ followed by up to 15 digits).
+var e164Regex = regexp.MustCompile(`^\+[1-9]\d{6,14}$`)
+
+// ParseAuditRule validates an audit rule name.
+func ParseAuditRule(name string) (AuditRule, error) {
+	for _, rule := range AuditRules {
+		if string(rule) == name {
+			return rule, nil
+		}
+	}
+	var names []string
+	for _, rule := range AuditRules {
+		names = append(names, string(rule))
+	}
+	return "", fmt.Errorf("unknown audit rule '%s', valid rules: %s", name, strings.Join(names, ", "))
+}
+
+// AuditContacts runs data-quality checks over the given contacts.
+// Findings are sorted by rule (in AuditRules order), then by display name.
+func AuditContacts(list []ContactDetails, opts AuditOptions) []AuditFinding {
+	enabled := make(map[AuditRule]bool)
+	if len(opts.Rules) == 0 {
+		for _, rule := range AuditRules {
+			enabled[rule] = true
+		}
+	}
+	for _, rule := range opts.Rules {
+		enabled[rule] = true
+	}
+
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	var findings []AuditFinding
+	for i := range list {
+		c := &list[i]
+		if enabled[RulePhoneNotE164] {
+			findings = append(findings, auditPhones(c)...)
+		}
+		if enabled[RuleEmailMalformed] {
+			findings = append(findings, auditEmails(c)...)
+		}
+		if enabled[RuleDuplicateEmail] {
+			findings = append(findings, auditDuplicateEmails(c)...)
+		}
+		if enabled[RuleAddressIncomplete] {
+			findings = append(findings, auditAddresses(c)...)
+		}
+		if enabled[RuleMissingName] || enabled[RuleEmptyContact] {
+			findings = append(findings, auditNames(c, enabled)...)
+		}
+		if enabled[RuleSuspiciousBirthday] {
+			findings = append(findings, auditBirthday(c, now)...)
+		}
+	}
+	if enabled[RuleSharedPhone] {
+		findings = append(findings, auditSharedPhones(list)...)
+	}
+
+	order := make(map[AuditRule]int)
+	for i, rule := range AuditRules {
+		order[rule] = i
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Rule != findings[j].Rule {
+			return order[findings[i].Rule] < order[findings[j].Rule]
+		}
+		return strings.ToLower(findings[i].DisplayName) < strings.ToLower(findings[j].DisplayName)
+	})
+
+	return findings
+}
+
+// auditPhones reports phone numbers that are not in E.164 format.
+func auditPhones(c *ContactDetails) []AuditFinding {
+	var findings []AuditFinding
+	for _, phone := range c.Phones {
+		if e164Regex.MatchString(phone.Value) {
+			continue
+		}
+		suggestion := "Check the number and add the country code (e.g. +33612345678)"
+		if normalized := NormalizePhoneNumber(phone.Value); e164Regex.MatchString(normalized) {
+			suggestion = fmt.Sprintf("Replace with %s", normalized)
+		}
+		findings = append(findings, newFinding(c, RulePhoneNotE164, "phones", phone.Value,
+			fmt.Sprintf("phone '%s' is not in E.164 format", phone.Value), suggestion))
+	}
+	return findings
+}
+
+// auditEmails reports malformed email addresses.
+func auditEmails(c *ContactDetails) []AuditFinding {
+	var findings []AuditFinding
+	for _, email := range c.Emails {
+		err := ValidateEmail(email.Value)
+		if err == nil {
+			continue
+		}
+		suggestion := "Correct or remove this email address"
+		cleaned := strings.TrimPrefix(strings.Join(strings.Fields(email.Value), ""), "mailto:")
+		if cleaned != email.Value && ValidateEmail(cleaned) == nil {
+			suggestion = fmt.Sprintf("Replace with %s", cleaned)
+		}
+		findings = append(findings, newFinding(c, RuleEmailMalformed, "emails", email.Value,
+			err.Error(), suggestion))
+	}
+	return findings
+}
+
+// auditDuplicateEmails reports email addresses of a contact that deliver to
+// the same mailbox as a previous one (SameEmail: domain case, Gmail dots and
+// +aliases).
+func auditDuplicateEmails(c *ContactDetails) []AuditFinding {
+	var findings []AuditFinding
+	first := make(map[string]string)
+	for _, email := range c.Emails {
+		// Malformed addresses are reported by RuleEmailMalformed
+		if ValidateEmail(email.Value) != nil {
+			continue
+		}
+		key := CanonicalEmail(email.Value)
+		previous, ok := first[key]
+		if !ok {
+			first[key] = email.Value
+			continue
+		}
+		findings = append(findings, newFinding(c, RuleDuplicateEmail, "emails", email.Value,
+			fmt.Sprintf("email '%s' is the same mailbox as '%s'", email.Value, previous),
+			fmt.Sprintf("Remove %s", email.Value)))
+	}
+	return findings
+}
+
+// auditAddresses reports addresses whose parsed form lacks a city or postal code.
+func auditAddresses(c *ContactDetails) []AuditFinding {
+	var findings []AuditFinding
+	for _, addr := range c.Addresses {
+		parsed := ParseAddress(addr.Value)
+		var missing []string
+		if parsed == nil || parsed.City == "" {
+			missing = append(missing, "city")
+		}
+		if parsed == nil || parsed.PostalCode == "" {
+			missing = append(missing, "postal code")
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		findings = append(findings, newFinding(c, RuleAddressIncomplete, "addresses", addr.Value,
+			fmt.Sprintf("address has no %s", strings.Join(missing, " and no ")),
+			"Rewrite as 'street, postal city, country' or use 'street=...;city=...;postal=...;country=...'"))
+	}
+	return findings
+}
+
+// auditNames reports contacts without a name, and contacts with no data at all.
+func auditNames(c *ContactDetails, enabled map[AuditRule]bool) []AuditFinding {
+	hasName := strings.TrimSpace(c.FirstName) != "" || strings.TrimSpace(c.LastName) != ""
+	if hasName {
+		return nil
+	}
+
+	isEmpty := len(c.Phones) == 0 && len(c.Emails) == 0 && len(c.Addresses) == 0 &&
+		c.Company == "" && c.Notes == "" && c.Birthday == ""
+	if isEmpty {
+		if !enabled[RuleEmptyContact] {
+			return nil
+		}
+		return []AuditFinding{newFinding(c, RuleEmptyContact, "contact", "",
+			"contact has no name and no data", "Delete this contact")}
+	}
+
+	if !enabled[RuleMissingName] {
+		return nil
+	}
+	suggestion := "Add a first and last name"
+	if guess := nameFromEmail(c.Emails); guess != "" {
+		suggestion = fmt.Sprintf("Add a name, e.g. '%s' (from email)", guess)
+	} else if c.Company != "" {
+		suggestion = fmt.Sprintf("Add the name of your contact at %s", c.Company)
+	}
+	return []AuditFinding{newFinding(c, RuleMissingName, "names", "",
+		"contact has no first or last name", suggestion)}
+}
+
+// nameFromEmail guesses "First Last" from a "first.last@domain" email address.
+func nameFromEmail(emails []EmailEntry) string {
+	for _, email := range emails {
+		at := strings.Index(email.Value, "@")
+		if at <= 0 {
+			continue
+		}
+		parts := strings.FieldsFunc(email.Value[:at], func(r rune) bool {
+			return r == '.' || r == '_' || r == '-'
+		})
+		if len(parts) < 2 {
+			continue
+		}
+		for i, part := range parts {
+			parts[i] = capitalize(strings.ToLower(part))
+		}
+		return strings.Join(parts, " ")
+	}
+	return ""
+}
+
+// auditBirthday reports impossible, future, placeholder or implausibly old birthdays.
+func auditBirthday(c *ContactDetails, now time.Time) []AuditFinding {
+	if c.Birthday == "" {
+		return nil
+	}
+
+	report := func(message string) []AuditFinding {
+		return []AuditFinding{newFinding(c, RuleSuspiciousBirthday, "birthday", c.Birthday, message,
+			"Verify the date, or use --MM-DD if the year is unknown")}
+	}
+
+	year, month, day, ok := splitDate(c.Birthday)
+	if !ok {
+		return report("birthday is not a valid date")
+	}
+
+	checkYear := year
+	if checkYear == 0 {
+		checkYear = leapYear
+	}
+	date := time.Date(checkYear, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Day() != day {
+		return report(fmt.Sprintf("birthday %s does not exist", c.Birthday))
+	}
+	if year == 0 {
+		return nil
+	}
+
+	switch {
+	case date.After(now):
+		return report("birthday is in the future")
+	case (year == 1900 || year == 1970) && month == 1 && day == 1:
+		return report("birthday looks like a placeholder date")
+	case now.Year()-year > 120:
+		return report(fmt.Sprintf("birthday implies an age of %d years", now.Year()-year))
+	}
+	return nil
+}
+
+// auditSharedPhones reports phone numbers used by more than one contact.
+func auditSharedPhones(list []ContactDetails) []AuditFinding {
+	owners := make(map[string][]int)
+	for i, c := range list {
+		seen := make(map[string]bool)
+		for _, phone := range c.Phones {
+			normalized := NormalizePhoneNumber(phone.Value)
+			if normalized == "" || seen[normalized] {
+				continue
+			}
+			seen[normalized] = true
+			owners[normalized] = append(owners[normalized], i)
+		}
+	}
+
+	phones := make([]string, 0, len(owners))
+	for phone := range owners {
+		phones = append(phones, phone)
+	}
+	sort.Strings(phones)
+
+	var findings []AuditFinding
+	for _, phone := range phones {
+		indexes := owners[phone]
+		if len(indexes) < 2 {
+			continue
+		}
+		for _, i := range indexes {
+			var others []string
+			for _, j := range indexes {
+				if j != i {
+					others = append(others, contactLabel(&list[j]))
+				}
+			}
+			findings = append(findings, newFinding(&list[i], RuleSharedPhone, "phones", phone,
+				fmt.Sprintf("phone %s is also used by %s", phone, strings.Join(others, ", ")),
+				"Merge duplicate contacts or remove the number from the wrong contact"))
+		}
+	}
+	return findings
+}
+
+// capitalize upper-cases the first letter of s.
+func capitalize(s string) string {
+	first, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return s
+	}
+	return strings.ToUpper(string(first)) + s[size:]
+}
+
+// contactLabel returns a short human label for a contact: "Name (id)".
+func contactLabel(c *ContactDetails) string {
+	name := c.DisplayName
+	if name == "" {
+		name = "(no name)"
+	}
+	return fmt.Sprintf("%s (%s)", name, extractID(c.ResourceName))
+}
+
+// newFinding builds an AuditFinding for the given contact.
+func newFinding(c *ContactDetails, rule AuditRule, field, value, message, suggestion string) AuditFinding {
+	return AuditFinding{
+		Rule:         rule,
+		ResourceName: c.ResourceName,
+		DisplayName:  c.DisplayName,
+		Field:        field,
+		Value:        value,
+		Message:      message,
+		Suggestion:   suggestion,
+	}
+}
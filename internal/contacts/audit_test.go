@@ -0,0 +1,190 @@
+package contacts
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAuditRule(t *testing.T) {
+	for _, rule := range AuditRules {
+		got, err := ParseAuditRule(string(rule))
+		if err != nil {
+			t.Errorf("ParseAuditRule(%q) unexpected error: %v", rule, err)
+		}
+		if got != rule {
+			t.Errorf("ParseAuditRule(%q) = %q", rule, got)
+		}
+	}
+
+	if _, err := ParseAuditRule("unknown"); err == nil {
+		t.Error("ParseAuditRule(\"unknown\") expected error, got nil")
+	}
+}
+
+func TestAuditContacts(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		contact        ContactDetails
+		wantRule       AuditRule
+		wantCount      int
+		wantSuggestion string
+	}{
+		{
+			name:      "valid contact",
+			contact:   ContactDetails{FirstName: "John", Phones: []PhoneEntry{{Value: "+33612345678"}}, Emails: []EmailEntry{{Value: "john@example.com"}}, Addresses: []AddressEntry{{Value: "1 rue de la Paix, 75001 Paris, France"}}, Birthday: "1980-05-15"},
+			wantCount: 0,
+		},
+		{
+			name:           "local phone",
+			contact:        ContactDetails{FirstName: "John", Phones: []PhoneEntry{{Value: "06 12 34 56 78"}}},
+			wantRule:       RulePhoneNotE164,
+			wantCount:      1,
+			wantSuggestion: "Replace with +33612345678",
+		},
+		{
+			name:           "email with mailto prefix",
+			contact:        ContactDetails{FirstName: "John", Emails: []EmailEntry{{Value: "mailto:john@example.com"}}},
+			wantRule:       RuleEmailMalformed,
+			wantCount:      1,
+			wantSuggestion: "Replace with john@example.com",
+		},
+		{
+			name:      "email without domain",
+			contact:   ContactDetails{FirstName: "John", Emails: []EmailEntry{{Value: "john@localhost"}}},
+			wantRule:  RuleEmailMalformed,
+			wantCount: 1,
+		},
+		{
+			name:      "address without city",
+			contact:   ContactDetails{FirstName: "John", Addresses: []AddressEntry{{Value: "somewhere"}}},
+			wantRule:  RuleAddressIncomplete,
+			wantCount: 1,
+		},
+		{
+			name:           "missing name with email",
+			contact:        ContactDetails{Emails: []EmailEntry{{Value: "jane.doe@example.com"}}},
+			wantRule:       RuleMissingName,
+			wantCount:      1,
+			wantSuggestion: "Add a name, e.g. 'Jane Doe' (from email)",
+		},
+		{
+			name:      "empty contact",
+			contact:   ContactDetails{},
+			wantRule:  RuleEmptyContact,
+			wantCount: 1,
+		},
+		{
+			name:      "future birthday",
+			contact:   ContactDetails{FirstName: "John", Birthday: "2030-01-01"},
+			wantRule:  RuleSuspiciousBirthday,
+			wantCount: 1,
+		},
+		{
+			name:      "placeholder birthday",
+			contact:   ContactDetails{FirstName: "John", Birthday: "1970-01-01"},
+			wantRule:  RuleSuspiciousBirthday,
+			wantCount: 1,
+		},
+		{
+			name:      "implausibly old birthday",
+			contact:   ContactDetails{FirstName: "John", Birthday: "1880-03-10"},
+			wantRule:  RuleSuspiciousBirthday,
+			wantCount: 1,
+		},
+		{
+			name:      "nonexistent birthday without year",
+			contact:   ContactDetails{FirstName: "John", Birthday: "--02-30"},
+			wantRule:  RuleSuspiciousBirthday,
+			wantCount: 1,
+		},
+		{
+			name:      "leap day birthday without year",
+			contact:   ContactDetails{FirstName: "John", Birthday: "--02-29"},
+			wantCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := AuditContacts([]ContactDetails{tt.contact}, AuditOptions{Now: now})
+			if len(findings) != tt.wantCount {
+				t.Fatalf("AuditContacts() returned %d findings, want %d: %+v", len(findings), tt.wantCount, findings)
+			}
+			if tt.wantCount == 0 {
+				return
+			}
+			if findings[0].Rule != tt.wantRule {
+				t.Errorf("AuditContacts() rule = %q, want %q", findings[0].Rule, tt.wantRule)
+			}
+			if tt.wantSuggestion != "" && findings[0].Suggestion != tt.wantSuggestion {
+				t.Errorf("AuditContacts() suggestion = %q, want %q", findings[0].Suggestion, tt.wantSuggestion)
+			}
+		})
+	}
+}
+
+func TestAuditContacts_SharedPhone(t *testing.T) {
+	list := []ContactDetails{
+		{ResourceName: "people/c1", DisplayName: "Bob", FirstName: "Bob", Phones: []PhoneEntry{{Value: "+33612345678"}}},
+		{ResourceName: "people/c2", DisplayName: "Alice", FirstName: "Alice", Phones: []PhoneEntry{{Value: "06 12 34 56 78"}}},
+		{ResourceName: "people/c3", DisplayName: "Carol", FirstName: "Carol", Phones: []PhoneEntry{{Value: "+33700000000"}}},
+	}
+
+	findings := AuditContacts(list, AuditOptions{Rules: []AuditRule{RuleSharedPhone}})
+	if len(findings) != 2 {
+		t.Fatalf("AuditContacts() returned %d findings, want 2: %+v", len(findings), findings)
+	}
+	// Sorted by display name within the rule
+	if findings[0].DisplayName != "Alice" || findings[1].DisplayName != "Bob" {
+		t.Errorf("AuditContacts() order = %q, %q, want Alice, Bob", findings[0].DisplayName, findings[1].DisplayName)
+	}
+}
+
+func TestAuditContacts_RuleFilter(t *testing.T) {
+	list := []ContactDetails{
+		{Phones: []PhoneEntry{{Value: "0612345678"}}, Emails: []EmailEntry{{Value: "bad"}}},
+	}
+
+	findings := AuditContacts(list, AuditOptions{Rules: []AuditRule{RuleEmailMalformed}})
+	if len(findings) != 1 {
+		t.Fatalf("AuditContacts() returned %d findings, want 1: %+v", len(findings), findings)
+	}
+	if findings[0].Rule != RuleEmailMalformed {
+		t.Errorf("AuditContacts() rule = %q, want %q", findings[0].Rule, RuleEmailMalformed)
+	}
+}
+
+func TestNameFromEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"jane.doe@example.com", "Jane Doe"},
+		{"JOHN_SMITH@example.com", "John Smith"},
+		{"contact@example.com", ""},
+		{"invalid", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			got := nameFromEmail([]EmailEntry{{Value: tt.email}})
+			if got != tt.want {
+				t.Errorf("nameFromEmail(%q) = %q, want %q", tt.email, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditContacts_DuplicateEmail(t *testing.T) {
+	list := []ContactDetails{{
+		ResourceName: "people/c1", DisplayName: "Jane", FirstName: "Jane",
+		Emails: []EmailEntry{{Value: "jane.doe@gmail.com"}, {Value: "JaneDoe+news@googlemail.com"}, {Value: "jane@example.com"}},
+	}}
+
+	findings := AuditContacts(list, AuditOptions{Rules: []AuditRule{RuleDuplicateEmail}})
+	if len(findings) != 1 || findings[0].Value != "JaneDoe+news@googlemail.com" {
+		t.Fatalf("AuditContacts() = %+v, want the Gmail alias", findings)
+	}
+}
@@ -0,0 +1,154 @@
+package contacts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultCacheTTL is how long the local contact cache is used before the
+// contact list is fetched again.
+const DefaultCacheTTL = 10 * time.Minute
+
+// ContactCache stores the full contact list on disk for local searches.
+// Contact groups are cached in groups.json next to the contact list.
+// The files contain personal data and are written with 0600 permissions.
+type ContactCache struct {
+	Path string
+	TTL  time.Duration
+}
+
+// cacheFile is the on-disk format of the contact and group caches.
+type cacheFile struct {
+	FetchedAt time.Time        `json:"fetchedAt"`
+	Contacts  []ContactDetails `json:"contacts,omitempty"`
+	Groups    []ContactGroup   `json:"groups,omitempty"`
+}
+
+// DefaultCachePath returns the contact cache location in the user cache directory.
+func DefaultCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return filepath.Join(dir, "google-contacts", "contacts.json"), nil
+}
+
+// groupsPath returns the location of the contact group cache.
+func (c *ContactCache) groupsPath() string {
+	return filepath.Join(filepath.Dir(c.Path), "groups.json")
+}
+
+// Load returns the cached contacts, or ok=false if the cache is missing,
+// unreadable or older than the TTL.
+func (c *ContactCache) Load() (list []ContactDetails, ok bool) {
+	file, ok := c.read(c.Path)
+	if !ok {
+		return nil, false
+	}
+	return file.Contacts, true
+}
+
+// Save writes the contact list to the cache atomically.
+func (c *ContactCache) Save(list []ContactDetails) error {
+	return c.write(c.Path, cacheFile{FetchedAt: time.Now(), Contacts: list})
+}
+
+// LoadGroups returns the cached contact groups, or ok=false if the cache is
+// missing, unreadable or older than the TTL.
+func (c *ContactCache) LoadGroups() (groups []ContactGroup, ok bool) {
+	file, ok := c.read(c.groupsPath())
+	if !ok {
+		return nil, false
+	}
+	return file.Groups, true
+}
+
+// SaveGroups writes the contact groups to the cache atomically.
+func (c *ContactCache) SaveGroups(groups []ContactGroup) error {
+	return c.write(c.groupsPath(), cacheFile{FetchedAt: time.Now(), Groups: groups})
+}
+
+// Invalidate removes the caches so that the next read fetches fresh data.
+// Groups are dropped too since their member counts change with contacts.
+func (c *ContactCache) Invalidate() error {
+	for _, path := range []string{c.Path, c.groupsPath()} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove contact cache: %w", err)
+		}
+	}
+	return nil
+}
+
+// read loads a cache file if it is fresh.
+func (c *ContactCache) read(path string) (cacheFile, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cacheFile{}, false
+	}
+	var file cacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return cacheFile{}, false
+	}
+	if time.Since(file.FetchedAt) > c.TTL {
+		return cacheFile{}, false
+	}
+	return file, true
+}
+
+// write saves a cache file atomically.
+func (c *ContactCache) write(path string, file cacheFile) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	data, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to encode contact cache: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write contact cache: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write contact cache: %w", err)
+	}
+	return nil
+}
+
+// ListContactsCached returns all contacts from the cache if it is fresh,
+// otherwise fetches them with ListContacts and refreshes the cache.
+// A cache write failure is not fatal: the fetched contacts are still returned.
+func (s *Service) ListContactsCached(ctx context.Context, cache *ContactCache) ([]ContactDetails, error) {
+	if list, ok := cache.Load(); ok {
+		return list, nil
+	}
+
+	list, err := s.ListContacts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := cache.Save(list); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return list, nil
+}
+
+// ListContactGroupsCached returns the contact groups from the cache if it is
+// fresh, otherwise fetches them with ListContactGroups and refreshes the cache.
+func (s *Service) ListContactGroupsCached(ctx context.Context, cache *ContactCache) ([]ContactGroup, error) {
+	if groups, ok := cache.LoadGroups(); ok {
+		return groups, nil
+	}
+
+	groups, err := s.ListContactGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := cache.SaveGroups(groups); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return groups, nil
+}
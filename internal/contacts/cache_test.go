@@ -0,0 +1,80 @@
+package contacts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestContactCache(t *testing.T) {
+	cache := &ContactCache{Path: filepath.Join(t.TempDir(), "sub", "contacts.json"), TTL: time.Minute}
+
+	if _, ok := cache.Load(); ok {
+		t.Fatal("Load() on missing cache returned ok")
+	}
+
+	list := []ContactDetails{{ResourceName: "people/c1", DisplayName: "John DOE"}}
+	if err := cache.Save(list); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	info, err := os.Stat(cache.Path)
+	if err != nil {
+		t.Fatalf("Stat() unexpected error: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("cache permissions = %v, want 0600", info.Mode().Perm())
+	}
+
+	got, ok := cache.Load()
+	if !ok || len(got) != 1 || got[0].DisplayName != "John DOE" {
+		t.Errorf("Load() = %+v, %v", got, ok)
+	}
+
+	// Expired cache is ignored
+	expired := &ContactCache{Path: cache.Path, TTL: -time.Second}
+	if _, ok := expired.Load(); ok {
+		t.Error("Load() on expired cache returned ok")
+	}
+
+	if err := cache.Invalidate(); err != nil {
+		t.Fatalf("Invalidate() unexpected error: %v", err)
+	}
+	if _, ok := cache.Load(); ok {
+		t.Error("Load() after Invalidate() returned ok")
+	}
+	if err := cache.Invalidate(); err != nil {
+		t.Errorf("Invalidate() on missing cache returned error: %v", err)
+	}
+}
+
+func TestContactCache_Groups(t *testing.T) {
+	cache := &ContactCache{Path: filepath.Join(t.TempDir(), "contacts.json"), TTL: time.Minute}
+
+	if _, ok := cache.LoadGroups(); ok {
+		t.Fatal("LoadGroups() on missing cache returned ok")
+	}
+	groups := []ContactGroup{{ResourceName: "contactGroups/abc", Name: "Climbing", MemberCount: 2}}
+	if err := cache.SaveGroups(groups); err != nil {
+		t.Fatalf("SaveGroups() unexpected error: %v", err)
+	}
+	if err := cache.Save([]ContactDetails{{ResourceName: "people/c1"}}); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	got, ok := cache.LoadGroups()
+	if !ok || len(got) != 1 || got[0].Name != "Climbing" {
+		t.Errorf("LoadGroups() = %+v, %v", got, ok)
+	}
+	if list, ok := cache.Load(); !ok || len(list) != 1 {
+		t.Errorf("Load() = %+v, %v: groups and contacts must not overwrite each other", list, ok)
+	}
+
+	if err := cache.Invalidate(); err != nil {
+		t.Fatalf("Invalidate() unexpected error: %v", err)
+	}
+	if _, ok := cache.LoadGroups(); ok {
+		t.Error("LoadGroups() after Invalidate() returned ok")
+	}
+}
@@ -0,0 +1,102 @@
+package contacts
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Valid types of phone numbers, email addresses and postal addresses.
+var (
+	PhoneTypes   = []string{"mobile", "work", "home", "main", "other"}
+	EmailTypes   = []string{"work", "home", "other"}
+	AddressTypes = []string{"home", "work", "other"}
+)
+
+// Types given to phones, emails and addresses entered without one.
+// They can be changed from the user configuration.
+var (
+	DefaultPhoneType   = "mobile"
+	DefaultEmailType   = "work"
+	DefaultAddressType = "home"
+)
+
+// Last name casing applied when creating or updating contacts.
+const (
+	LastNamePreserve = "preserve" // Keep the last name as typed
+	LastNameUpper    = "upper"    // DUPONT
+	LastNameTitle    = "title"    // Dupont
+)
+
+// LastNameCases lists the valid last name casings.
+var LastNameCases = []string{LastNamePreserve, LastNameUpper, LastNameTitle}
+
+// FormatLastName applies a last name casing. An unknown casing keeps the
+// name unchanged.
+func FormatLastName(name, nameCase string) string {
+	switch nameCase {
+	case LastNameUpper:
+		return strings.ToUpper(name)
+	case LastNameTitle:
+		// Capitalize each part of compound names ("jean-dupont" → "Jean-Dupont")
+		runes := []rune(strings.ToLower(name))
+		start := true
+		for i, r := range runes {
+			if start {
+				runes[i] = unicode.ToUpper(r)
+			}
+			start = r == ' ' || r == '-' || r == '\''
+		}
+		return string(runes)
+	}
+	return name
+}
+
+// phoneRegion describes how national phone numbers of a region are
+// converted to international format: the trunk prefix is replaced with the
+// country calling code.
+type phoneRegion struct {
+	callingCode string
+	trunkPrefix string
+}
+
+// phoneRegions lists the supported default phone regions (ISO 3166 codes).
+var phoneRegions = map[string]phoneRegion{
+	"AT": {"43", "0"},
+	"AU": {"61", "0"},
+	"BE": {"32", "0"},
+	"CH": {"41", "0"},
+	"DE": {"49", "0"},
+	"FI": {"358", "0"},
+	"FR": {"33", "0"},
+	"GB": {"44", "0"},
+	"IE": {"353", "0"},
+	"JP": {"81", "0"},
+	"NL": {"31", "0"},
+	"NZ": {"64", "0"},
+	"SE": {"46", "0"},
+}
+
+// DefaultPhoneRegion is the region of national phone numbers when none is configured.
+const DefaultPhoneRegion = "FR"
+
+// currentPhoneRegion is the region used by NormalizePhoneNumber.
+var currentPhoneRegion = phoneRegions[DefaultPhoneRegion]
+
+// PhoneRegions returns the supported phone regions, sorted.
+func PhoneRegions() []string {
+	return slices.Sorted(maps.Keys(phoneRegions))
+}
+
+// SetPhoneRegion sets the region of the national phone numbers converted by
+// NormalizePhoneNumber, e.g. "FR" or "GB".
+func SetPhoneRegion(region string) error {
+	r, ok := phoneRegions[strings.ToUpper(region)]
+	if !ok {
+		return fmt.Errorf("invalid phone region '%s', valid regions: %s", region, strings.Join(PhoneRegions(), ", "))
+	}
+	currentPhoneRegion = r
+	return nil
+}
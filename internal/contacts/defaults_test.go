@@ -0,0 +1,49 @@
+package contacts
+
+import "testing"
+
+func TestFormatLastName(t *testing.T) {
+	tests := []struct {
+		name     string
+		nameCase string
+		expected string
+	}{
+		{"dupont", LastNameUpper, "DUPONT"},
+		{"DE LA FONTAINE", LastNameTitle, "De La Fontaine"},
+		{"martin-dupré", LastNameTitle, "Martin-Dupré"},
+		{"o'brien", LastNameTitle, "O'Brien"},
+		{"McDonald", LastNamePreserve, "McDonald"},
+		{"McDonald", "", "McDonald"},
+	}
+
+	for _, tc := range tests {
+		if got := FormatLastName(tc.name, tc.nameCase); got != tc.expected {
+			t.Errorf("FormatLastName(%q, %q) = %q, want %q", tc.name, tc.nameCase, got, tc.expected)
+		}
+	}
+}
+
+func TestSetPhoneRegion(t *testing.T) {
+	defer SetPhoneRegion(DefaultPhoneRegion)
+
+	if err := SetPhoneRegion("xx"); err == nil {
+		t.Error("SetPhoneRegion(xx) should fail")
+	}
+	if err := SetPhoneRegion("gb"); err != nil {
+		t.Fatalf("SetPhoneRegion(gb) error = %v", err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"07700 900123", "+447700900123"},
+		{"0033612345678", "+33612345678"},
+		{"+33612345678", "+33612345678"},
+	}
+	for _, tc := range tests {
+		if got := NormalizePhoneNumber(tc.input); got != tc.expected {
+			t.Errorf("NormalizePhoneNumber(%q) = %q, want %q", tc.input, got, tc.expected)
+		}
+	}
+}
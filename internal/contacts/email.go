@@ -0,0 +1,186 @@
+package contacts
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// Email length limits from RFC 5321 section 4.5.3.1.
+const (
+	maxEmailLocalLength  = 64
+	maxEmailDomainLength = 253
+	maxEmailLength       = 254
+)
+
+// gmailDomains lists domains where dots in the local part are ignored and
+// "+suffix" aliases are delivered to the base address.
+var gmailDomains = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+}
+
+// ValidateEmail checks that an email address is a valid RFC 5322 addr-spec.
+// The supported subset is a dot-atom local part (quoted strings and comments
+// are rejected) and a DNS domain name, which may be internationalized (IDN).
+func ValidateEmail(email string) error {
+	if email == "" {
+		return fmt.Errorf("email address cannot be empty")
+	}
+	if strings.TrimSpace(email) != email {
+		return fmt.Errorf("email address '%s' has leading or trailing spaces", email)
+	}
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return fmt.Errorf("email address '%s' has no @", email)
+	}
+	local, domain := email[:at], email[at+1:]
+
+	if err := validateEmailLocal(local); err != nil {
+		return fmt.Errorf("email address '%s' is invalid: %w", email, err)
+	}
+
+	asciiDomain, err := emailDomainToASCII(domain)
+	if err != nil {
+		return fmt.Errorf("email address '%s' is invalid: %w", email, err)
+	}
+
+	if len(local)+1+len(asciiDomain) > maxEmailLength {
+		return fmt.Errorf("email address '%s' is longer than %d characters", email, maxEmailLength)
+	}
+	return nil
+}
+
+// validateEmailLocal checks a dot-atom local part.
+// Non-ASCII characters are accepted as allowed by RFC 6531 (SMTPUTF8).
+func validateEmailLocal(local string) error {
+	if local == "" {
+		return fmt.Errorf("local part is empty")
+	}
+	if len(local) > maxEmailLocalLength {
+		return fmt.Errorf("local part is longer than %d characters", maxEmailLocalLength)
+	}
+	if !utf8.ValidString(local) {
+		return fmt.Errorf("local part is not valid UTF-8")
+	}
+	if strings.HasPrefix(local, ".") || strings.HasSuffix(local, ".") || strings.Contains(local, "..") {
+		return fmt.Errorf("local part has a misplaced dot")
+	}
+	for _, c := range local {
+		if c != '.' && !isAtext(c) {
+			return fmt.Errorf("local part contains invalid character '%c'", c)
+		}
+	}
+	return nil
+}
+
+// isAtext reports whether c is an RFC 5322 atext character (or non-ASCII).
+func isAtext(c rune) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	case strings.ContainsRune("!#$%&'*+-/=?^_`{|}~", c):
+		return true
+	case c > utf8.RuneSelf:
+		return true
+	}
+	return false
+}
+
+// emailDomainToASCII validates a domain name and returns its ASCII (punycode) form.
+func emailDomainToASCII(domain string) (string, error) {
+	if domain == "" {
+		return "", fmt.Errorf("domain is empty")
+	}
+	if strings.HasPrefix(domain, "[") {
+		return "", fmt.Errorf("IP address literals are not supported")
+	}
+
+	ascii, err := idna.Lookup.ToASCII(domain)
+	if err != nil {
+		return "", fmt.Errorf("domain '%s' is not a valid host name: %w", domain, err)
+	}
+	if len(ascii) > maxEmailDomainLength {
+		return "", fmt.Errorf("domain is longer than %d characters", maxEmailDomainLength)
+	}
+
+	labels := strings.Split(ascii, ".")
+	if len(labels) < 2 {
+		return "", fmt.Errorf("domain '%s' has no top-level domain", domain)
+	}
+	for _, label := range labels {
+		if label == "" {
+			return "", fmt.Errorf("domain '%s' has an empty label", domain)
+		}
+		if len(label) > 63 {
+			return "", fmt.Errorf("domain label '%s' is longer than 63 characters", label)
+		}
+		if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return "", fmt.Errorf("domain label '%s' starts or ends with a hyphen", label)
+		}
+	}
+	if strings.Trim(labels[len(labels)-1], "0123456789") == "" {
+		return "", fmt.Errorf("top-level domain of '%s' is numeric", domain)
+	}
+	return ascii, nil
+}
+
+// CanonicalEmail returns a normalized form of an email address for comparison.
+// The domain is lowercased and converted to ASCII (punycode). For Gmail
+// addresses the local part is lowercased, dots are removed, "+suffix" aliases
+// are dropped and googlemail.com is folded into gmail.com.
+// Other local parts are kept as-is, since they may be case-sensitive.
+// Invalid addresses are returned trimmed and lowercased.
+func CanonicalEmail(email string) string {
+	email = strings.TrimSpace(email)
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return strings.ToLower(email)
+	}
+	local, domain := email[:at], email[at+1:]
+
+	asciiDomain, err := idna.Lookup.ToASCII(domain)
+	if err != nil {
+		return strings.ToLower(email)
+	}
+	asciiDomain = strings.ToLower(asciiDomain)
+
+	if gmailDomains[asciiDomain] {
+		local = strings.ToLower(local)
+		if plus := strings.Index(local, "+"); plus >= 0 {
+			local = local[:plus]
+		}
+		local = strings.ReplaceAll(local, ".", "")
+		asciiDomain = "gmail.com"
+	}
+	return local + "@" + asciiDomain
+}
+
+// SameEmail reports whether two email addresses deliver to the same mailbox.
+func SameEmail(a, b string) bool {
+	return CanonicalEmail(a) == CanonicalEmail(b)
+}
+
+// validateEmailEntries checks all email entries with ValidateEmail.
+func validateEmailEntries(emails []EmailEntry) error {
+	return ValidateNewEmails(emails, nil)
+}
+
+// ValidateNewEmails checks with ValidateEmail the entries whose value is not
+// one of existing, so that addresses already stored on a contact, even
+// malformed ones, can be kept when editing or restoring it.
+func ValidateNewEmails(emails []EmailEntry, existing []string) error {
+	for _, email := range emails {
+		if slices.Contains(existing, email.Value) {
+			continue
+		}
+		if err := ValidateEmail(email.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package contacts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateEmail(t *testing.T) {
+	tests := []struct {
+		email   string
+		wantErr bool
+	}{
+		{"john@example.com", false},
+		{"john.doe+tag@example.co.uk", false},
+		{"o'brien@example.ie", false},
+		{"user@bücher.de", false},
+		{"用户@例子.广告", false},
+		{"user@xn--bcher-kva.de", false},
+		{"", true},
+		{"john.example.com", true},
+		{"@example.com", true},
+		{"john@", true},
+		{".john@example.com", true},
+		{"john.@example.com", true},
+		{"jo..hn@example.com", true},
+		{"jo hn@example.com", true},
+		{"\"john\"@example.com", true},
+		{"mailto:john@example.com", true},
+		{"john@localhost", true},
+		{"john@example..com", true},
+		{"john@-example.com", true},
+		{"john@example.123", true},
+		{"john@[192.168.0.1]", true},
+		{" john@example.com", true},
+		{strings.Repeat("a", 65) + "@example.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			err := ValidateEmail(tt.email)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateEmail(%q) error = %v, wantErr %v", tt.email, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCanonicalEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"John.Doe@Example.COM", "John.Doe@example.com"},
+		{"John.Doe+news@Gmail.com", "johndoe@gmail.com"},
+		{"j.o.h.n@googlemail.com", "john@gmail.com"},
+		{"user+tag@example.com", "user+tag@example.com"},
+		{"user@Bücher.de", "user@xn--bcher-kva.de"},
+		{"  john@example.com ", "john@example.com"},
+		{"Not An Email", "not an email"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			if got := CanonicalEmail(tt.email); got != tt.want {
+				t.Errorf("CanonicalEmail(%q) = %q, want %q", tt.email, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSameEmail(t *testing.T) {
+	if !SameEmail("johndoe@gmail.com", "John.Doe+work@GoogleMail.com") {
+		t.Error("SameEmail() expected Gmail aliases to match")
+	}
+	if SameEmail("John@example.com", "john@example.com") {
+		t.Error("SameEmail() expected non-Gmail local parts to stay case-sensitive")
+	}
+}
+
+func TestValidateNewEmails(t *testing.T) {
+	emails := []EmailEntry{{Value: "jane@"}, {Value: "jane@example.com"}}
+	if err := ValidateNewEmails(emails, []string{"jane@"}); err != nil {
+		t.Errorf("ValidateNewEmails() with existing malformed email error = %v", err)
+	}
+	if err := ValidateNewEmails(emails, nil); err == nil {
+		t.Error("ValidateNewEmails() expected error for a new malformed email")
+	}
+}
@@ -0,0 +1,351 @@
+package contacts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// FixTransform identifies a bulk data fix applied to contacts.
+type FixTransform string
+
+// Available fix transforms.
+const (
+	FixNormalizePhones       FixTransform = "normalize-phones"
+	FixParseAddresses        FixTransform = "parse-addresses"
+	FixUppercaseLastNames    FixTransform = "uppercase-lastnames"
+	FixTrimWhitespace        FixTransform = "trim-whitespace"
+	FixLowercaseEmailDomains FixTransform = "lowercase-email-domains"
+)
+
+// FixTransforms lists all fix transforms in the order they are applied.
+var FixTransforms = []FixTransform{
+	FixTrimWhitespace,
+	FixNormalizePhones,
+	FixLowercaseEmailDomains,
+	FixUppercaseLastNames,
+	FixParseAddresses,
+}
+
+// FixChange describes a single field change made by a fix.
+type FixChange struct {
+	Field  string
+	Before string
+	After  string
+}
+
+// PhoneValues returns phones as "type:value" strings.
+func PhoneValues(phones []PhoneEntry) []string {
+	var values []string
+	for _, p := range phones {
+		values = append(values, p.Type+":"+p.Value)
+	}
+	return values
+}
+
+// EmailValues returns emails as "type:value" strings.
+func EmailValues(emails []EmailEntry) []string {
+	var values []string
+	for _, e := range emails {
+		values = append(values, e.Type+":"+e.Value)
+	}
+	return values
+}
+
+// AddressValues returns addresses as "type:value" strings.
+func AddressValues(addresses []AddressEntry) []string {
+	var values []string
+	for _, a := range addresses {
+		values = append(values, a.Type+":"+a.Value)
+	}
+	return values
+}
+
+// ListChanges returns one change per removed and per added value of a list
+// field, or a single change listing both lists when only the order differs.
+func ListChanges(field string, before, after []string) []FixChange {
+	var changes []FixChange
+	for _, v := range before {
+		if !slices.Contains(after, v) {
+			changes = append(changes, FixChange{Field: field, Before: v})
+		}
+	}
+	for _, v := range after {
+		if !slices.Contains(before, v) {
+			changes = append(changes, FixChange{Field: field, After: v})
+		}
+	}
+	if len(changes) == 0 && !slices.Equal(before, after) {
+		// Same values in a different order
+		changes = append(changes, FixChange{Field: field, Before: strings.Join(before, ", "), After: strings.Join(after, ", ")})
+	}
+	return changes
+}
+
+// FixPlan describes the update to apply to one contact.
+type FixPlan struct {
+	ResourceName string
+	DisplayName  string
+	Changes      []FixChange
+	Input        UpdateInput // Update to pass to UpdateContact
+}
+
+// ParseFixTransform validates a fix transform name.
+func ParseFixTransform(name string) (FixTransform, error) {
+	for _, transform := range FixTransforms {
+		if string(transform) == name {
+			return transform, nil
+		}
+	}
+	var names []string
+	for _, transform := range FixTransforms {
+		names = append(names, string(transform))
+	}
+	return "", fmt.Errorf("unknown fix '%s', valid fixes: %s", name, strings.Join(names, ", "))
+}
+
+// PlanFix computes the changes the given transforms would make to a contact.
+// Returns nil if the contact is already clean.
+func PlanFix(c ContactDetails, transforms []FixTransform) *FixPlan {
+	plan := &FixPlan{
+		ResourceName: c.ResourceName,
+		DisplayName:  c.DisplayName,
+	}
+	// Copy entry slices so the caller's contact is left untouched
+	c.Phones = slices.Clone(c.Phones)
+	c.Emails = slices.Clone(c.Emails)
+	c.Addresses = slices.Clone(c.Addresses)
+	for _, transform := range FixTransforms {
+		if slices.Contains(transforms, transform) {
+			applyFix(&c, transform, plan)
+		}
+	}
+	if len(plan.Changes) == 0 {
+		return nil
+	}
+	return plan
+}
+
+// applyFix applies one transform to the contact and records changes in the plan.
+// The contact is modified in place so that later transforms see earlier results.
+func applyFix(c *ContactDetails, transform FixTransform, plan *FixPlan) {
+	switch transform {
+	case FixTrimWhitespace:
+		fixString(plan, "firstName", &c.FirstName, collapseSpaces(c.FirstName), &plan.Input.FirstName)
+		fixString(plan, "lastName", &c.LastName, collapseSpaces(c.LastName), &plan.Input.LastName)
+		fixString(plan, "company", &c.Company, collapseSpaces(c.Company), &plan.Input.Company)
+		fixString(plan, "position", &c.Position, collapseSpaces(c.Position), &plan.Input.Position)
+		fixString(plan, "notes", &c.Notes, strings.TrimSpace(c.Notes), &plan.Input.Notes)
+		if fixEntries(plan, "emails", c.Emails, strings.TrimSpace) {
+			plan.Input.Emails = c.Emails
+		}
+		if fixAddresses(plan, c.Addresses, collapseSpaces) {
+			plan.Input.Addresses = c.Addresses
+		}
+
+	case FixNormalizePhones:
+		changed := false
+		for i, phone := range c.Phones {
+			normalized := NormalizePhoneNumber(phone.Value)
+			if normalized != phone.Value {
+				plan.Changes = append(plan.Changes, FixChange{Field: "phones", Before: phone.Value, After: normalized})
+				c.Phones[i].Value = normalized
+				changed = true
+			}
+		}
+		if changed {
+			plan.Input.Phones = c.Phones
+		}
+
+	case FixLowercaseEmailDomains:
+		if fixEntries(plan, "emails", c.Emails, lowercaseEmailDomain) {
+			plan.Input.Emails = c.Emails
+		}
+
+	case FixUppercaseLastNames:
+		fixString(plan, "lastName", &c.LastName, strings.ToUpper(c.LastName), &plan.Input.LastName)
+
+	case FixParseAddresses:
+		changed := false
+		for i, addr := range c.Addresses {
+			if addr.Structured {
+				continue
+			}
+			parsed := ParseAddress(addr.Value)
+			if parsed == nil || (parsed.City == "" && parsed.PostalCode == "") {
+				continue
+			}
+			plan.Changes = append(plan.Changes, FixChange{
+				Field:  "addresses",
+				Before: addr.Value,
+				After:  describeStructuredAddress(parsed),
+			})
+			c.Addresses[i].Structured = true
+			changed = true
+		}
+		if changed {
+			// UpdateContact re-parses every address it is given
+			plan.Input.Addresses = c.Addresses
+		}
+	}
+}
+
+// fixString updates a string field when the fixed value differs.
+func fixString(plan *FixPlan, field string, value *string, fixed string, input **string) {
+	if fixed == *value {
+		return
+	}
+	plan.Changes = append(plan.Changes, FixChange{Field: field, Before: *value, After: fixed})
+	*value = fixed
+	*input = value
+}
+
+// fixEntries applies fn to each email value and reports whether any changed.
+func fixEntries(plan *FixPlan, field string, entries []EmailEntry, fn func(string) string) bool {
+	changed := false
+	for i, entry := range entries {
+		fixed := fn(entry.Value)
+		if fixed != entry.Value {
+			plan.Changes = append(plan.Changes, FixChange{Field: field, Before: entry.Value, After: fixed})
+			entries[i].Value = fixed
+			changed = true
+		}
+	}
+	return changed
+}
+
+// fixAddresses applies fn to each address value and reports whether any changed.
+func fixAddresses(plan *FixPlan, entries []AddressEntry, fn func(string) string) bool {
+	changed := false
+	for i, entry := range entries {
+		fixed := fn(entry.Value)
+		if fixed != entry.Value {
+			plan.Changes = append(plan.Changes, FixChange{Field: "addresses", Before: entry.Value, After: fixed})
+			entries[i].Value = fixed
+			changed = true
+		}
+	}
+	return changed
+}
+
+// collapseSpaces trims a string and collapses inner whitespace runs (but not newlines) to one space.
+func collapseSpaces(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// lowercaseEmailDomain lowercases the domain part of an email address.
+// The local part is case-sensitive per RFC 5321 and left untouched.
+func lowercaseEmailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return email
+	}
+	return email[:at+1] + strings.ToLower(email[at+1:])
+}
+
+// describeStructuredAddress renders parsed address fields for diff output.
+func describeStructuredAddress(addr *StructuredAddress) string {
+	var parts []string
+	add := func(label, value string) {
+		if value != "" {
+			parts = append(parts, fmt.Sprintf("%s=%s", label, value))
+		}
+	}
+	add("street", addr.StreetAddress)
+	add("postal", addr.PostalCode)
+	add("city", addr.City)
+	add("region", addr.Region)
+	add("country", addr.Country)
+	return strings.Join(parts, ";")
+}
+
+// FixProgress records the contacts already processed by an interrupted fix run,
+// so that a new run with the same transforms resumes where it stopped.
+type FixProgress struct {
+	Transforms []FixTransform `json:"transforms"`
+	Done       []string       `json:"done"` // Resource names processed (updated or clean)
+	Updated    int            `json:"updated"`
+
+	done map[string]bool
+}
+
+// LoadFixProgress reads a progress file. A missing file, or one recorded for
+// different transforms, yields empty progress for the given transforms.
+func LoadFixProgress(path string, transforms []FixTransform) (*FixProgress, error) {
+	fresh := &FixProgress{Transforms: transforms, done: make(map[string]bool)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return fresh, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read progress file: %w", err)
+	}
+
+	var progress FixProgress
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return nil, fmt.Errorf("failed to parse progress file %s: %w", path, err)
+	}
+	if !slices.Equal(sortedTransforms(progress.Transforms), sortedTransforms(transforms)) {
+		return fresh, nil
+	}
+
+	progress.done = make(map[string]bool)
+	for _, name := range progress.Done {
+		progress.done[name] = true
+	}
+	return &progress, nil
+}
+
+// IsDone reports whether the contact was already processed.
+func (p *FixProgress) IsDone(resourceName string) bool {
+	return p.done[resourceName]
+}
+
+// MarkDone records a processed contact.
+func (p *FixProgress) MarkDone(resourceName string, updated bool) {
+	if p.done == nil {
+		p.done = make(map[string]bool)
+	}
+	if p.done[resourceName] {
+		return
+	}
+	p.done[resourceName] = true
+	p.Done = append(p.Done, resourceName)
+	if updated {
+		p.Updated++
+	}
+}
+
+// Save writes the progress file atomically.
+func (p *FixProgress) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create progress directory: %w", err)
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode progress: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write progress file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write progress file: %w", err)
+	}
+	return nil
+}
+
+// sortedTransforms returns a sorted copy of transforms for comparison.
+func sortedTransforms(transforms []FixTransform) []FixTransform {
+	sorted := slices.Clone(transforms)
+	slices.Sort(sorted)
+	return sorted
+}
@@ -0,0 +1,201 @@
+package contacts
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseFixTransform(t *testing.T) {
+	for _, transform := range FixTransforms {
+		got, err := ParseFixTransform(string(transform))
+		if err != nil {
+			t.Errorf("ParseFixTransform(%q) unexpected error: %v", transform, err)
+		}
+		if got != transform {
+			t.Errorf("ParseFixTransform(%q) = %q", transform, got)
+		}
+	}
+
+	if _, err := ParseFixTransform("unknown"); err == nil {
+		t.Error("ParseFixTransform(\"unknown\") expected error, got nil")
+	}
+}
+
+func TestPlanFix(t *testing.T) {
+	tests := []struct {
+		name        string
+		contact     ContactDetails
+		transforms  []FixTransform
+		wantChanges []FixChange
+	}{
+		{
+			name:       "clean contact",
+			contact:    ContactDetails{FirstName: "John", LastName: "DOE", Phones: []PhoneEntry{{Value: "+33612345678"}}},
+			transforms: FixTransforms,
+		},
+		{
+			name:        "normalize phones",
+			contact:     ContactDetails{Phones: []PhoneEntry{{Value: "06 12 34 56 78", Type: "mobile"}, {Value: "+33123456789"}}},
+			transforms:  []FixTransform{FixNormalizePhones},
+			wantChanges: []FixChange{{Field: "phones", Before: "06 12 34 56 78", After: "+33612345678"}},
+		},
+		{
+			name:        "trim then uppercase last name",
+			contact:     ContactDetails{LastName: "  van  der berg "},
+			transforms:  []FixTransform{FixUppercaseLastNames, FixTrimWhitespace},
+			wantChanges: []FixChange{{Field: "lastName", Before: "  van  der berg ", After: "van der berg"}, {Field: "lastName", Before: "van der berg", After: "VAN DER BERG"}},
+		},
+		{
+			name:        "lowercase email domain only",
+			contact:     ContactDetails{Emails: []EmailEntry{{Value: "John.Doe@Example.COM"}}},
+			transforms:  []FixTransform{FixLowercaseEmailDomains},
+			wantChanges: []FixChange{{Field: "emails", Before: "John.Doe@Example.COM", After: "John.Doe@example.com"}},
+		},
+		{
+			name:        "parse free-form address",
+			contact:     ContactDetails{Addresses: []AddressEntry{{Value: "10 rue de la Paix, 75002 Paris, France"}}},
+			transforms:  []FixTransform{FixParseAddresses},
+			wantChanges: []FixChange{{Field: "addresses", Before: "10 rue de la Paix, 75002 Paris, France", After: "street=10 rue de la Paix;postal=75002;city=Paris;country=France"}},
+		},
+		{
+			name:       "already structured address",
+			contact:    ContactDetails{Addresses: []AddressEntry{{Value: "10 rue de la Paix, 75002 Paris, France", Structured: true}}},
+			transforms: []FixTransform{FixParseAddresses},
+		},
+		{
+			name:       "notes keep inner newlines",
+			contact:    ContactDetails{Notes: "line 1\nline 2"},
+			transforms: []FixTransform{FixTrimWhitespace},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := PlanFix(tt.contact, tt.transforms)
+			if len(tt.wantChanges) == 0 {
+				if plan != nil {
+					t.Errorf("PlanFix() = %+v, want nil", plan.Changes)
+				}
+				return
+			}
+			if plan == nil {
+				t.Fatal("PlanFix() = nil, want changes")
+			}
+			if len(plan.Changes) != len(tt.wantChanges) {
+				t.Fatalf("PlanFix() changes = %+v, want %+v", plan.Changes, tt.wantChanges)
+			}
+			for i, want := range tt.wantChanges {
+				if plan.Changes[i] != want {
+					t.Errorf("PlanFix() change[%d] = %+v, want %+v", i, plan.Changes[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanFix_Input(t *testing.T) {
+	contact := ContactDetails{
+		LastName: "doe",
+		Phones:   []PhoneEntry{{Value: "0612345678", Type: "mobile"}},
+	}
+
+	plan := PlanFix(contact, []FixTransform{FixNormalizePhones, FixUppercaseLastNames})
+	if plan == nil {
+		t.Fatal("PlanFix() = nil, want changes")
+	}
+	if plan.Input.LastName == nil || *plan.Input.LastName != "DOE" {
+		t.Errorf("PlanFix() Input.LastName = %v, want DOE", plan.Input.LastName)
+	}
+	if len(plan.Input.Phones) != 1 || plan.Input.Phones[0].Value != "+33612345678" || plan.Input.Phones[0].Type != "mobile" {
+		t.Errorf("PlanFix() Input.Phones = %+v", plan.Input.Phones)
+	}
+	if plan.Input.Emails != nil || plan.Input.FirstName != nil {
+		t.Error("PlanFix() Input has unexpected fields set")
+	}
+	// The original contact must not be modified
+	if contact.Phones[0].Value != "0612345678" || contact.LastName != "doe" {
+		t.Errorf("PlanFix() modified the input contact: %+v", contact)
+	}
+}
+
+func TestFixProgress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress.json")
+	transforms := []FixTransform{FixNormalizePhones, FixTrimWhitespace}
+
+	progress, err := LoadFixProgress(path, transforms)
+	if err != nil {
+		t.Fatalf("LoadFixProgress() unexpected error: %v", err)
+	}
+	if len(progress.Done) != 0 {
+		t.Errorf("LoadFixProgress() on missing file has %d done", len(progress.Done))
+	}
+
+	progress.MarkDone("people/c1", true)
+	progress.MarkDone("people/c2", false)
+	progress.MarkDone("people/c1", true)
+	if err := progress.Save(path); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	// Same transforms in another order resume the run
+	resumed, err := LoadFixProgress(path, []FixTransform{FixTrimWhitespace, FixNormalizePhones})
+	if err != nil {
+		t.Fatalf("LoadFixProgress() unexpected error: %v", err)
+	}
+	if !resumed.IsDone("people/c1") || !resumed.IsDone("people/c2") || resumed.IsDone("people/c3") {
+		t.Errorf("LoadFixProgress() done = %v", resumed.Done)
+	}
+	if resumed.Updated != 1 {
+		t.Errorf("LoadFixProgress() updated = %d, want 1", resumed.Updated)
+	}
+
+	// Different transforms start over
+	other, err := LoadFixProgress(path, []FixTransform{FixParseAddresses})
+	if err != nil {
+		t.Fatalf("LoadFixProgress() unexpected error: %v", err)
+	}
+	if other.IsDone("people/c1") {
+		t.Error("LoadFixProgress() with different transforms should start fresh")
+	}
+}
+
+func TestListChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		before []string
+		after  []string
+		want   []FixChange
+	}{
+		{
+			name:   "added and removed",
+			before: []string{"mobile:+33612345678", "work:+33112345678"},
+			after:  []string{"mobile:+33612345678", "home:+33212345678"},
+			want: []FixChange{
+				{Field: "phones", Before: "work:+33112345678"},
+				{Field: "phones", After: "home:+33212345678"},
+			},
+		},
+		{
+			name:   "reordered",
+			before: []string{"mobile:+33612345678", "work:+33112345678"},
+			after:  []string{"work:+33112345678", "mobile:+33612345678"},
+			want: []FixChange{
+				{Field: "phones", Before: "mobile:+33612345678, work:+33112345678", After: "work:+33112345678, mobile:+33612345678"},
+			},
+		},
+		{
+			name:   "unchanged",
+			before: []string{"mobile:+33612345678"},
+			after:  []string{"mobile:+33612345678"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ListChanges("phones", tc.before, tc.after); !slices.Equal(got, tc.want) {
+				t.Errorf("ListChanges() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,323 @@
+package contacts
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// FuzzyResult is a contact matched by FuzzySearch with its relevance score.
+type FuzzyResult struct {
+	Contact ContactDetails
+	Score   float64 // 0 < Score <= 1, higher is more relevant
+}
+
+// Match scores for a query token against a contact token.
+const (
+	scoreExact    = 1.0
+	scorePrefix   = 0.9
+	scoreTypo     = 0.8 // Minus 0.1 per edit beyond the first
+	scorePhonetic = 0.6
+	scoreTrigram  = 0.7 // Multiplied by the trigram similarity
+	minTrigramSim = 0.4
+)
+
+// foldReplacer expands letters that have no canonical decomposition.
+var foldReplacer = strings.NewReplacer(
+	"ß", "ss", "æ", "ae", "œ", "oe", "ø", "o", "ł", "l", "đ", "d", "ð", "d", "þ", "th", "ı", "i",
+)
+
+// FoldText lowercases s and removes diacritics, so that "François" and
+// "FRANCOIS" both become "francois".
+func FoldText(s string) string {
+	s = foldReplacer.Replace(strings.ToLower(s))
+	var b strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// fuzzyTokens folds s and splits it into words.
+func fuzzyTokens(s string) []string {
+	return strings.FieldsFunc(FoldText(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// FuzzySearch ranks contacts against a query, tolerating accents, typos and
+// phonetic spelling variants. Every query word must match a word of the
+// contact's names, company, position or emails; digit-only queries match
+// phone numbers. Results are sorted by descending score, then by name.
+// If limit > 0, at most limit results are returned.
+func FuzzySearch(list []ContactDetails, query string, limit int) []FuzzyResult {
+	queryTokens := fuzzyTokens(query)
+	if len(queryTokens) == 0 {
+		return nil
+	}
+	queryDigits := phoneDigits(query)
+	isPhoneQuery := len(queryDigits) >= 3 && len(queryDigits) >= len(strings.Join(queryTokens, ""))
+
+	var results []FuzzyResult
+	for _, c := range list {
+		var score float64
+		if isPhoneQuery {
+			score = phoneScore(c, queryDigits)
+		} else {
+			score = textScore(c, queryTokens)
+		}
+		if score > 0 {
+			results = append(results, FuzzyResult{Contact: c, Score: score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return FoldText(results[i].Contact.DisplayName) < FoldText(results[j].Contact.DisplayName)
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// textScore averages the best match of every query token, weighted by field.
+// Returns 0 if any query token matches nothing.
+func textScore(c ContactDetails, queryTokens []string) float64 {
+	type field struct {
+		tokens []string
+		weight float64
+	}
+	fields := []field{
+		{fuzzyTokens(c.FirstName + " " + c.LastName + " " + c.DisplayName), 1.0},
+		{fuzzyTokens(c.Company), 0.8},
+		{fuzzyTokens(c.Position), 0.6},
+	}
+	for _, email := range c.Emails {
+		local, _, _ := strings.Cut(email.Value, "@")
+		fields = append(fields, field{fuzzyTokens(local), 0.8})
+	}
+
+	var total float64
+	for _, q := range queryTokens {
+		best := 0.0
+		for _, f := range fields {
+			for _, token := range f.tokens {
+				if s := tokenScore(q, token) * f.weight; s > best {
+					best = s
+				}
+			}
+		}
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+	return total / float64(len(queryTokens))
+}
+
+// tokenScore scores a folded query token against a folded contact token.
+func tokenScore(q, token string) float64 {
+	switch {
+	case q == token:
+		return scoreExact
+	case strings.HasPrefix(token, q):
+		return scorePrefix
+	}
+
+	// Allow one typo from 4 letters, two from 8 letters
+	maxEdits := 0
+	if n := len([]rune(q)); n >= 8 {
+		maxEdits = 2
+	} else if n >= 4 {
+		maxEdits = 1
+	}
+	if maxEdits > 0 {
+		if d := editDistance(q, token, maxEdits); d <= maxEdits {
+			return scoreTypo - 0.1*float64(d-1)
+		}
+	}
+
+	if len(q) >= 3 && soundex(q) != "" && soundex(q) == soundex(token) {
+		return scorePhonetic
+	}
+
+	if sim := trigramSimilarity(q, token); sim >= minTrigramSim {
+		return scoreTrigram * sim
+	}
+	return 0
+}
+
+// phoneScore matches a digit query against the contact's phone numbers.
+// Local numbers (leading 0) also match their international form.
+func phoneScore(c ContactDetails, digits string) float64 {
+	alternates := []string{digits}
+	if strings.HasPrefix(digits, "0") {
+		alternates = append(alternates, phoneDigits(NormalizePhoneNumber(digits)))
+	}
+	best := 0.0
+	for _, phone := range c.Phones {
+		phone := phoneDigits(phone.Value)
+		for _, q := range alternates {
+			switch {
+			case phone == q:
+				return scoreExact
+			case strings.HasSuffix(phone, q):
+				best = max(best, scorePrefix)
+			case strings.Contains(phone, q):
+				best = max(best, scoreTypo)
+			}
+		}
+	}
+	return best
+}
+
+// phoneDigits keeps only the digits of s.
+func phoneDigits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// editDistance returns the Damerau-Levenshtein (optimal string alignment)
+// distance between a and b, or limit+1 as soon as it exceeds limit.
+func editDistance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if abs(len(ra)-len(rb)) > limit {
+		return limit + 1
+	}
+
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(rb)]
+}
+
+// trigramSimilarity returns the Jaccard similarity of the padded trigram sets of a and b.
+func trigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	common := 0
+	for t := range ta {
+		if tb[t] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(ta)+len(tb)-common)
+}
+
+// trigrams returns the set of 3-rune sequences of s padded with spaces.
+func trigrams(s string) map[string]bool {
+	r := []rune("  " + s + " ")
+	set := make(map[string]bool)
+	for i := 0; i+3 <= len(r); i++ {
+		set[string(r[i:i+3])] = true
+	}
+	return set
+}
+
+// soundex returns the American Soundex code of a folded word (e.g. "robert" → "R163"),
+// or "" if the word does not start with a letter a-z.
+func soundex(s string) string {
+	codes := map[rune]byte{
+		'b': '1', 'f': '1', 'p': '1', 'v': '1',
+		'c': '2', 'g': '2', 'j': '2', 'k': '2', 'q': '2', 's': '2', 'x': '2', 'z': '2',
+		'd': '3', 't': '3',
+		'l': '4',
+		'm': '5', 'n': '5',
+		'r': '6',
+	}
+
+	var code []byte
+	var last byte
+	for i, r := range s {
+		if i == 0 {
+			if r < 'a' || r > 'z' {
+				return ""
+			}
+			code = append(code, byte(unicode.ToUpper(r)))
+			last = codes[r]
+			continue
+		}
+		digit, ok := codes[r]
+		switch {
+		case !ok && (r == 'h' || r == 'w'):
+			// H and W do not separate identical codes
+		case !ok:
+			last = 0
+		case digit != last:
+			code = append(code, digit)
+			last = digit
+		}
+		if len(code) == 4 {
+			break
+		}
+	}
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code)
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// SearchResultFromDetails summarizes contact details as a search result
+// (first phone and email only).
+func SearchResultFromDetails(c ContactDetails) SearchResult {
+	result := SearchResult{
+		ResourceName: c.ResourceName,
+		DisplayName:  c.DisplayName,
+		Company:      c.Company,
+		Position:     c.Position,
+		Notes:        c.Notes,
+	}
+	if len(c.Phones) > 0 {
+		result.Phone = c.Phones[0].Value
+	}
+	if len(c.Emails) > 0 {
+		result.Email = c.Emails[0].Value
+	}
+	return result
+}
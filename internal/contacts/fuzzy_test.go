@@ -0,0 +1,137 @@
+package contacts
+
+import (
+	"testing"
+)
+
+func TestFoldText(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"François", "francois"},
+		{"ÉLODIE", "elodie"},
+		{"Straße", "strasse"},
+		{"Œuvre", "oeuvre"},
+		{"Łódź", "lodz"},
+		{"Zoë Müller", "zoe muller"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := FoldText(tt.input); got != tt.want {
+				t.Errorf("FoldText(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		limit int
+		want  int
+	}{
+		{"john", "john", 2, 0},
+		{"jonh", "john", 2, 1}, // Transposition
+		{"jon", "john", 2, 1},
+		{"smith", "smyth", 2, 1},
+		{"martin", "morton", 2, 2},
+		{"abc", "xyzxyz", 2, 3}, // Exceeds limit
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := editDistance(tt.a, tt.b, tt.limit); got != tt.want {
+				t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSoundex(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"robert", "R163"},
+		{"rupert", "R163"},
+		{"ashcraft", "A261"},
+		{"tymczak", "T522"},
+		{"lee", "L000"},
+		{"123", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := soundex(tt.input); got != tt.want {
+				t.Errorf("soundex(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFuzzySearch(t *testing.T) {
+	list := []ContactDetails{
+		{ResourceName: "people/c1", DisplayName: "François DUPONT", FirstName: "François", LastName: "DUPONT", Phones: []PhoneEntry{{Value: "+33612345678"}}},
+		{ResourceName: "people/c2", DisplayName: "John SMITH", FirstName: "John", LastName: "SMITH", Company: "Acme Corp"},
+		{ResourceName: "people/c3", DisplayName: "Françoise MARTIN", FirstName: "Françoise", LastName: "MARTIN", Emails: []EmailEntry{{Value: "fmartin@example.com"}}},
+		{ResourceName: "people/c4", DisplayName: "Zoë LEFÈVRE", FirstName: "Zoë", LastName: "LEFÈVRE"},
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		wantIDs []string // Expected result order
+	}{
+		{"accent-insensitive", "francois dupont", []string{"people/c1"}},
+		{"prefix ranks exact first", "francois", []string{"people/c1", "people/c3"}},
+		{"one-letter typo", "jonh", []string{"people/c2"}},
+		{"phonetic", "smyth", []string{"people/c2"}},
+		{"company", "acme", []string{"people/c2"}},
+		{"email local part", "fmartin", []string{"people/c3"}},
+		{"accented query", "LEFEVRE zoe", []string{"people/c4"}},
+		{"local phone number", "06 12 34", []string{"people/c1"}},
+		{"international phone number", "+33612345678", []string{"people/c1"}},
+		{"no match", "xavier", nil},
+		{"every word must match", "john dupont", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := FuzzySearch(list, tt.query, 0)
+			if len(results) != len(tt.wantIDs) {
+				var got []string
+				for _, r := range results {
+					got = append(got, r.Contact.ResourceName)
+				}
+				t.Fatalf("FuzzySearch(%q) = %v, want %v", tt.query, got, tt.wantIDs)
+			}
+			for i, id := range tt.wantIDs {
+				if results[i].Contact.ResourceName != id {
+					t.Errorf("FuzzySearch(%q)[%d] = %s, want %s", tt.query, i, results[i].Contact.ResourceName, id)
+				}
+				if results[i].Score <= 0 || results[i].Score > 1 {
+					t.Errorf("FuzzySearch(%q)[%d].Score = %v, want (0, 1]", tt.query, i, results[i].Score)
+				}
+			}
+		})
+	}
+}
+
+func TestFuzzySearch_Limit(t *testing.T) {
+	list := []ContactDetails{
+		{ResourceName: "people/c1", DisplayName: "Anne A", FirstName: "Anne"},
+		{ResourceName: "people/c2", DisplayName: "Anne B", FirstName: "Anne"},
+		{ResourceName: "people/c3", DisplayName: "Anne C", FirstName: "Anne"},
+	}
+
+	results := FuzzySearch(list, "anne", 2)
+	if len(results) != 2 {
+		t.Fatalf("FuzzySearch() returned %d results, want 2", len(results))
+	}
+	// Equal scores are ordered by name
+	if results[0].Contact.ResourceName != "people/c1" || results[1].Contact.ResourceName != "people/c2" {
+		t.Errorf("FuzzySearch() order = %s, %s", results[0].Contact.ResourceName, results[1].Contact.ResourceName)
+	}
+}
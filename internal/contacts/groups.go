@@ -0,0 +1,74 @@
+package contacts
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	people "google.golang.org/api/people/v1"
+)
+
+// ContactGroup represents a contact group (label) of the address book.
+type ContactGroup struct {
+	ResourceName string // contactGroups/<id>
+	Name         string // Formatted name, localized for system groups
+	System       bool   // Predefined group (myContacts, starred, ...)
+	MemberCount  int
+}
+
+// ListContactGroups retrieves all contact groups, user groups first, each
+// sorted by name.
+func (s *Service) ListContactGroups(ctx context.Context) ([]ContactGroup, error) {
+	var groups []ContactGroup
+
+	err := s.ContactGroups.List().
+		GroupFields("name,groupType,memberCount").
+		PageSize(1000).
+		Context(ctx).
+		Pages(ctx, func(resp *people.ListContactGroupsResponse) error {
+			for _, g := range resp.ContactGroups {
+				groups = append(groups, contactGroupFromAPI(g))
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list contact groups: %w", err)
+	}
+
+	sortContactGroups(groups)
+	return groups, nil
+}
+
+// contactGroupFromAPI converts a People API contact group into ContactGroup.
+func contactGroupFromAPI(g *people.ContactGroup) ContactGroup {
+	name := g.FormattedName
+	if name == "" {
+		name = g.Name
+	}
+	return ContactGroup{
+		ResourceName: g.ResourceName,
+		Name:         name,
+		System:       g.GroupType == "SYSTEM_CONTACT_GROUP",
+		MemberCount:  int(g.MemberCount),
+	}
+}
+
+// sortContactGroups orders user groups before system groups, then by folded name.
+func sortContactGroups(groups []ContactGroup) {
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].System != groups[j].System {
+			return !groups[i].System
+		}
+		return FoldText(groups[i].Name) < FoldText(groups[j].Name)
+	})
+}
+
+// InGroup reports whether the contact is a member of the given contact group.
+func (c *ContactDetails) InGroup(resourceName string) bool {
+	for _, g := range c.Groups {
+		if g == resourceName {
+			return true
+		}
+	}
+	return false
+}
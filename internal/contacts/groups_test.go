@@ -0,0 +1,64 @@
+package contacts
+
+import (
+	"testing"
+
+	people "google.golang.org/api/people/v1"
+)
+
+func TestContactGroupFromAPI(t *testing.T) {
+	g := contactGroupFromAPI(&people.ContactGroup{
+		ResourceName:  "contactGroups/starred",
+		Name:          "starred",
+		FormattedName: "Starred",
+		GroupType:     "SYSTEM_CONTACT_GROUP",
+		MemberCount:   3,
+	})
+	if g.Name != "Starred" || !g.System || g.MemberCount != 3 {
+		t.Errorf("contactGroupFromAPI() = %+v", g)
+	}
+
+	g = contactGroupFromAPI(&people.ContactGroup{ResourceName: "contactGroups/abc", Name: "Climbing", GroupType: "USER_CONTACT_GROUP"})
+	if g.Name != "Climbing" || g.System {
+		t.Errorf("contactGroupFromAPI() = %+v", g)
+	}
+}
+
+func TestSortContactGroups(t *testing.T) {
+	groups := []ContactGroup{
+		{Name: "Starred", System: true},
+		{Name: "Work"},
+		{Name: "Équipe"},
+		{Name: "Family", System: true},
+		{Name: "climbing"},
+	}
+	sortContactGroups(groups)
+
+	expected := []string{"climbing", "Équipe", "Work", "Family", "Starred"}
+	for i, name := range expected {
+		if groups[i].Name != name {
+			t.Errorf("groups[%d] = %q, want %q", i, groups[i].Name, name)
+		}
+	}
+}
+
+func TestContactDetailsFromPerson_Memberships(t *testing.T) {
+	details := contactDetailsFromPerson(&people.Person{
+		ResourceName: "people/c1",
+		Memberships: []*people.Membership{
+			{ContactGroupMembership: &people.ContactGroupMembership{ContactGroupResourceName: "contactGroups/myContacts"}},
+			{DomainMembership: &people.DomainMembership{InViewerDomain: true}},
+			{ContactGroupMembership: &people.ContactGroupMembership{ContactGroupResourceName: "contactGroups/abc"}},
+		},
+	})
+
+	if len(details.Groups) != 2 {
+		t.Fatalf("expected 2 groups, got %v", details.Groups)
+	}
+	if !details.InGroup("contactGroups/abc") {
+		t.Error("InGroup(contactGroups/abc) = false, want true")
+	}
+	if details.InGroup("contactGroups/starred") {
+		t.Error("InGroup(contactGroups/starred) = true, want false")
+	}
+}
@@ -0,0 +1,206 @@
+package contacts
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ICalOptions controls the generation of an iCalendar (RFC 5545) feed.
+type ICalOptions struct {
+	CalendarName string    // Calendar display name (X-WR-CALNAME), optional
+	AlarmDays    []int     // Reminders in days before the event (0 = on the day), optional
+	Now          time.Time // Timestamp used for DTSTAMP (defaults to time.Now)
+}
+
+// CalendarEvent represents a yearly recurring event derived from a contact.
+type CalendarEvent struct {
+	UID     string // Stable identifier derived from the contact resource name
+	Summary string
+	Year    int // 0 if unknown
+	Month   int
+	Day     int
+}
+
+// leapYear is used as DTSTART year when the real year is unknown, so that
+// February 29 events remain valid dates.
+const leapYear = 2000
+
+// CalendarEvents builds the yearly recurring events (birthdays and other
+// significant dates) for the given contacts. Contacts without dates are skipped.
+// UIDs are derived from the resource name so that re-imports update existing
+// events instead of creating duplicates, even when a date is corrected. Other
+// dates are identified by their type and their occurrence within that type
+// (the second anniversary of a contact is "<id>-anniversary-2").
+func CalendarEvents(contacts []ContactDetails) []CalendarEvent {
+	var events []CalendarEvent
+	for _, c := range contacts {
+		id := extractID(c.ResourceName)
+		name := c.DisplayName
+		if name == "" {
+			name = strings.TrimSpace(c.FirstName + " " + c.LastName)
+		}
+
+		if year, month, day, ok := splitDate(c.Birthday); ok {
+			events = append(events, CalendarEvent{
+				UID:     fmt.Sprintf("%s-birthday@google-contacts", id),
+				Summary: fmt.Sprintf("Birthday: %s", name),
+				Year:    year,
+				Month:   month,
+				Day:     day,
+			})
+		}
+
+		occurrences := make(map[string]int)
+		for _, event := range c.Events {
+			year, month, day, ok := splitDate(event.Date)
+			if !ok {
+				continue
+			}
+			eventType := strings.ToLower(event.Type)
+			if eventType == "" {
+				eventType = "other"
+			}
+			slug := uidSlug(eventType)
+			occurrences[slug]++
+			uid := fmt.Sprintf("%s-%s@google-contacts", id, slug)
+			if n := occurrences[slug]; n > 1 {
+				uid = fmt.Sprintf("%s-%s-%d@google-contacts", id, slug, n)
+			}
+			label := capitalize(eventType)
+			events = append(events, CalendarEvent{
+				UID:     uid,
+				Summary: fmt.Sprintf("%s: %s", label, name),
+				Year:    year,
+				Month:   month,
+				Day:     day,
+			})
+		}
+	}
+	return events
+}
+
+// WriteICal writes an RFC 5545 calendar with one yearly recurring all-day event
+// per contact birthday or significant date.
+func WriteICal(w io.Writer, contacts []ContactDetails, opts ICalOptions) error {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	stamp := now.UTC().Format("20060102T150405Z")
+
+	var b strings.Builder
+	writeICalLine(&b, "BEGIN:VCALENDAR")
+	writeICalLine(&b, "VERSION:2.0")
+	writeICalLine(&b, "PRODID:-//google-contacts//Contacts Calendar//EN")
+	writeICalLine(&b, "CALSCALE:GREGORIAN")
+	writeICalLine(&b, "METHOD:PUBLISH")
+	if opts.CalendarName != "" {
+		writeICalLine(&b, "X-WR-CALNAME:"+escapeICalText(opts.CalendarName))
+	}
+
+	for _, event := range CalendarEvents(contacts) {
+		year := event.Year
+		if year == 0 {
+			year = leapYear
+		}
+		start := time.Date(year, time.Month(event.Month), event.Day, 0, 0, 0, 0, time.UTC)
+		if start.Day() != event.Day {
+			// Skip impossible dates such as February 31
+			continue
+		}
+		end := start.AddDate(0, 0, 1)
+
+		writeICalLine(&b, "BEGIN:VEVENT")
+		writeICalLine(&b, "UID:"+event.UID)
+		writeICalLine(&b, "DTSTAMP:"+stamp)
+		writeICalLine(&b, "DTSTART;VALUE=DATE:"+start.Format("20060102"))
+		writeICalLine(&b, "DTEND;VALUE=DATE:"+end.Format("20060102"))
+		if event.Month == 2 && event.Day == 29 {
+			// Fall back to the last day of February in non-leap years
+			writeICalLine(&b, "RRULE:FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1")
+		} else {
+			writeICalLine(&b, "RRULE:FREQ=YEARLY")
+		}
+		writeICalLine(&b, "SUMMARY:"+escapeICalText(event.Summary))
+		if event.Year != 0 {
+			writeICalLine(&b, "DESCRIPTION:"+escapeICalText(fmt.Sprintf("Since %d", event.Year)))
+		}
+		writeICalLine(&b, "TRANSP:TRANSPARENT")
+		for _, days := range opts.AlarmDays {
+			writeICalLine(&b, "BEGIN:VALARM")
+			writeICalLine(&b, "ACTION:DISPLAY")
+			writeICalLine(&b, "DESCRIPTION:"+escapeICalText(event.Summary))
+			if days <= 0 {
+				writeICalLine(&b, "TRIGGER:PT0S")
+			} else {
+				writeICalLine(&b, fmt.Sprintf("TRIGGER:-P%dD", days))
+			}
+			writeICalLine(&b, "END:VALARM")
+		}
+		writeICalLine(&b, "END:VEVENT")
+	}
+
+	writeICalLine(&b, "END:VCALENDAR")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// splitDate splits a "YYYY-MM-DD" or "--MM-DD" date into its components.
+// Returns ok=false if the date is empty or invalid.
+func splitDate(date string) (year, month, day int, ok bool) {
+	d := parseBirthday(date)
+	if d == nil {
+		return 0, 0, 0, false
+	}
+	return int(d.Date.Year), int(d.Date.Month), int(d.Date.Day), true
+}
+
+// uidSlug reduces an event type to characters safe for use in a UID.
+func uidSlug(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+			b.WriteRune(c)
+		default:
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+// escapeICalText escapes a TEXT value as per RFC 5545 section 3.3.11.
+func escapeICalText(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return r.Replace(s)
+}
+
+// writeICalLine writes a content line terminated by CRLF, folding lines longer
+// than 75 octets as per RFC 5545 section 3.1 without splitting UTF-8 characters.
+func writeICalLine(b *strings.Builder, line string) {
+	const maxOctets = 75
+	limit := maxOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards the limit
+		limit = maxOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
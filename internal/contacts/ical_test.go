@@ -0,0 +1,179 @@
+package contacts
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalendarEvents(t *testing.T) {
+	list := []ContactDetails{
+		{
+			ResourceName: "people/c111",
+			DisplayName:  "Jane Doe",
+			Birthday:     "1985-03-15",
+			Events: []EventEntry{
+				{Date: "2010-06-20", Type: "anniversary"},
+				{Date: "invalid", Type: "other"},
+			},
+		},
+		{
+			ResourceName: "people/c222",
+			DisplayName:  "John Smith",
+			Birthday:     "--12-01",
+		},
+		{
+			ResourceName: "people/c333",
+			DisplayName:  "No Dates",
+		},
+	}
+
+	events := CalendarEvents(list)
+	if len(events) != 3 {
+		t.Fatalf("CalendarEvents() returned %d events, want 3", len(events))
+	}
+
+	tests := []struct {
+		uid     string
+		summary string
+		year    int
+		month   int
+		day     int
+	}{
+		{"c111-birthday@google-contacts", "Birthday: Jane Doe", 1985, 3, 15},
+		{"c111-anniversary@google-contacts", "Anniversary: Jane Doe", 2010, 6, 20},
+		{"c222-birthday@google-contacts", "Birthday: John Smith", 0, 12, 1},
+	}
+	for i, tc := range tests {
+		e := events[i]
+		if e.UID != tc.uid {
+			t.Errorf("events[%d].UID = %q, want %q", i, e.UID, tc.uid)
+		}
+		if e.Summary != tc.summary {
+			t.Errorf("events[%d].Summary = %q, want %q", i, e.Summary, tc.summary)
+		}
+		if e.Year != tc.year || e.Month != tc.month || e.Day != tc.day {
+			t.Errorf("events[%d] date = %d-%d-%d, want %d-%d-%d", i, e.Year, e.Month, e.Day, tc.year, tc.month, tc.day)
+		}
+	}
+}
+
+func TestCalendarEvents_StableUIDs(t *testing.T) {
+	before := []ContactDetails{{ResourceName: "people/c111", DisplayName: "Jane", Birthday: "1985-03-15"}}
+	after := []ContactDetails{{ResourceName: "people/c111", DisplayName: "Jane Doe", Birthday: "1985-03-16"}}
+
+	if CalendarEvents(before)[0].UID != CalendarEvents(after)[0].UID {
+		t.Error("UID changed when contact fields changed, re-imports would duplicate events")
+	}
+
+	// Other dates keep their UID when their date is corrected or another
+	// type of date is removed
+	before = []ContactDetails{{ResourceName: "people/c111", Events: []EventEntry{
+		{Date: "2015-01-02", Type: "other"}, {Date: "2010-06-20", Type: "anniversary"}}}}
+	after = []ContactDetails{{ResourceName: "people/c111", Events: []EventEntry{
+		{Date: "2010-06-21", Type: "Anniversary"}, {Date: "2012-09-01", Type: "anniversary"}}}}
+	first, second := CalendarEvents(before), CalendarEvents(after)
+	if first[1].UID != second[0].UID {
+		t.Errorf("UID changed from %q to %q when the date was corrected", first[1].UID, second[0].UID)
+	}
+	if second[1].UID != "c111-anniversary-2@google-contacts" {
+		t.Errorf("second anniversary UID = %q, want c111-anniversary-2@google-contacts", second[1].UID)
+	}
+}
+
+func TestWriteICal(t *testing.T) {
+	list := []ContactDetails{
+		{ResourceName: "people/c111", DisplayName: "Doe, Jane; Jr.", Birthday: "1985-03-15"},
+		{ResourceName: "people/c222", DisplayName: "Leap Day", Birthday: "--02-29"},
+		{ResourceName: "people/c333", DisplayName: "Impossible", Birthday: "--02-31"},
+	}
+
+	var b strings.Builder
+	err := WriteICal(&b, list, ICalOptions{
+		CalendarName: "Birthdays",
+		AlarmDays:    []int{1},
+		Now:          time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("WriteICal() error: %v", err)
+	}
+	out := b.String()
+
+	expected := []string{
+		"BEGIN:VCALENDAR\r\n",
+		"VERSION:2.0\r\n",
+		"X-WR-CALNAME:Birthdays\r\n",
+		"UID:c111-birthday@google-contacts\r\n",
+		"DTSTAMP:20260102T030405Z\r\n",
+		"DTSTART;VALUE=DATE:19850315\r\n",
+		"DTEND;VALUE=DATE:19850316\r\n",
+		"RRULE:FREQ=YEARLY\r\n",
+		`SUMMARY:Birthday: Doe\, Jane\; Jr.` + "\r\n",
+		"DESCRIPTION:Since 1985\r\n",
+		"TRIGGER:-P1D\r\n",
+		"DTSTART;VALUE=DATE:20000229\r\n",
+		"RRULE:FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1\r\n",
+		"END:VCALENDAR\r\n",
+	}
+	for _, want := range expected {
+		if !strings.Contains(out, want) {
+			t.Errorf("WriteICal() output missing %q", want)
+		}
+	}
+	if strings.Contains(out, "c333") {
+		t.Error("WriteICal() should skip impossible dates")
+	}
+	if strings.Count(out, "BEGIN:VEVENT") != 2 {
+		t.Errorf("expected 2 events, got %d", strings.Count(out, "BEGIN:VEVENT"))
+	}
+}
+
+func TestWriteICalLine_Folding(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"short line", "SUMMARY:Hello"},
+		{"ascii long line", "SUMMARY:" + strings.Repeat("a", 200)},
+		{"multibyte long line", "SUMMARY:" + strings.Repeat("é", 100)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var b strings.Builder
+			writeICalLine(&b, tc.line)
+			out := b.String()
+
+			for _, physical := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+				if len(physical) > 75 {
+					t.Errorf("physical line has %d octets, want <= 75", len(physical))
+				}
+			}
+
+			unfolded := strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", "")
+			if unfolded != tc.line {
+				t.Errorf("unfolded line = %q, want %q", unfolded, tc.line)
+			}
+		})
+	}
+}
+
+func TestEscapeICalText(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"plain", "plain"},
+		{"a,b", `a\,b`},
+		{"a;b", `a\;b`},
+		{`a\b`, `a\\b`},
+		{"line1\nline2", `line1\nline2`},
+	}
+
+	for _, tc := range tests {
+		result := escapeICalText(tc.input)
+		if result != tc.expected {
+			t.Errorf("escapeICalText(%q) = %q, want %q", tc.input, result, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,431 @@
+package contacts
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Query is a parsed contact filter expression such as
+// `company:acme has:phone -has:email birthday:10 city:Paris updated:>2025-01-01`.
+//
+// Terms separated by spaces must all match; the OR keyword separates
+// alternatives (AND binds tighter than OR). A leading '-' negates a term.
+// Values containing spaces are quoted: company:"acme corp".
+type Query struct {
+	groups [][]queryTerm // OR of ANDs
+}
+
+// queryTerm is a single compiled condition.
+type queryTerm struct {
+	negate bool
+	match  func(c *ContactDetails) bool
+}
+
+// QuerySyntaxError reports an invalid query with the position of the problem.
+type QuerySyntaxError struct {
+	Pos int // 1-based character position in the query
+	Msg string
+}
+
+func (e *QuerySyntaxError) Error() string {
+	return fmt.Sprintf("query syntax error at position %d: %s", e.Pos, e.Msg)
+}
+
+// QueryFields lists the supported field names.
+var QueryFields = []string{
+	"name", "first", "last", "company", "position", "email", "phone", "notes",
+	"address", "city", "postal", "country", "birthday", "updated", "has",
+}
+
+// hasTargets maps has:<target> to a presence check.
+var hasTargets = map[string]func(c *ContactDetails) bool{
+	"name":     func(c *ContactDetails) bool { return c.FirstName != "" || c.LastName != "" },
+	"phone":    func(c *ContactDetails) bool { return len(c.Phones) > 0 },
+	"email":    func(c *ContactDetails) bool { return len(c.Emails) > 0 },
+	"address":  func(c *ContactDetails) bool { return len(c.Addresses) > 0 },
+	"company":  func(c *ContactDetails) bool { return c.Company != "" },
+	"position": func(c *ContactDetails) bool { return c.Position != "" },
+	"notes":    func(c *ContactDetails) bool { return c.Notes != "" },
+	"birthday": func(c *ContactDetails) bool { return c.Birthday != "" },
+	"event":    func(c *ContactDetails) bool { return len(c.Events) > 0 },
+}
+
+// Date value formats accepted by updated: and birthday:.
+var (
+	queryDateRegex     = regexp.MustCompile(`^\d{4}(-\d{2}(-\d{2})?)?$`)
+	queryMonthRegex    = regexp.MustCompile(`^\d{1,2}$`)
+	queryMonthDayRegex = regexp.MustCompile(`^(\d{1,2})-(\d{1,2})$`)
+)
+
+// rawTerm is a term as read by the tokenizer.
+type rawTerm struct {
+	pos    int
+	negate bool
+	field  string // Empty for free text
+	value  string
+	quoted bool
+}
+
+// ParseQuery parses a filter expression. An empty query matches every contact.
+func ParseQuery(input string) (*Query, error) {
+	raws, err := tokenizeQuery(input)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Query{}
+	var group []queryTerm
+	for i, raw := range raws {
+		if raw.field == "" && !raw.quoted && !raw.negate && raw.value == "OR" {
+			if len(group) == 0 || i == len(raws)-1 {
+				return nil, &QuerySyntaxError{Pos: raw.pos, Msg: "OR must be between two terms"}
+			}
+			q.groups = append(q.groups, group)
+			group = nil
+			continue
+		}
+		term, err := compileTerm(raw)
+		if err != nil {
+			return nil, err
+		}
+		group = append(group, term)
+	}
+	if len(group) > 0 {
+		q.groups = append(q.groups, group)
+	}
+	return q, nil
+}
+
+// NewTextQuery returns a query matching contacts whose names, company or
+// emails contain the given text (accent and case insensitive).
+func NewTextQuery(text string) *Query {
+	term, _ := compileTerm(rawTerm{value: text})
+	return &Query{groups: [][]queryTerm{{term}}}
+}
+
+// Match reports whether the contact satisfies the query.
+func (q *Query) Match(c *ContactDetails) bool {
+	if len(q.groups) == 0 {
+		return true
+	}
+	for _, group := range q.groups {
+		if matchAll(group, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAll reports whether every term of a group matches.
+func matchAll(group []queryTerm, c *ContactDetails) bool {
+	for _, term := range group {
+		if term.match(c) == term.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterContacts returns the contacts matching the query, sorted by display name.
+func FilterContacts(list []ContactDetails, q *Query) []ContactDetails {
+	var matched []ContactDetails
+	for i := range list {
+		if q.Match(&list[i]) {
+			matched = append(matched, list[i])
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return FoldText(matched[i].DisplayName) < FoldText(matched[j].DisplayName)
+	})
+	return matched
+}
+
+// tokenizeQuery splits the input into terms, handling '-' prefixes and quotes.
+func tokenizeQuery(input string) ([]rawTerm, error) {
+	runes := []rune(input)
+	var terms []rawTerm
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		term := rawTerm{pos: i + 1}
+		if runes[i] == '-' {
+			term.negate = true
+			i++
+		}
+
+		// Read the field name or free text up to ':' or a space
+		start := i
+		for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != ':' && runes[i] != '"' {
+			i++
+		}
+		word := string(runes[start:i])
+
+		if i < len(runes) && runes[i] == ':' {
+			if word == "" {
+				return nil, &QuerySyntaxError{Pos: i + 1, Msg: "missing field name before ':'"}
+			}
+			term.field = strings.ToLower(word)
+			i++
+			value, next, quoted, err := readQueryValue(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			if value == "" {
+				return nil, &QuerySyntaxError{Pos: i + 1, Msg: fmt.Sprintf("missing value after '%s:'", word)}
+			}
+			term.value, term.quoted = value, quoted
+			i = next
+		} else if i < len(runes) && runes[i] == '"' && word == "" {
+			value, next, _, err := readQueryValue(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			term.value, term.quoted = value, true
+			i = next
+		} else {
+			if i < len(runes) && runes[i] == '"' {
+				return nil, &QuerySyntaxError{Pos: i + 1, Msg: "unexpected quote inside a word"}
+			}
+			if word == "" {
+				return nil, &QuerySyntaxError{Pos: term.pos, Msg: "'-' must be followed by a term"}
+			}
+			term.value = word
+		}
+		terms = append(terms, term)
+	}
+	return terms, nil
+}
+
+// readQueryValue reads a plain or double-quoted value starting at i.
+// Returns the value, the next position, and whether it was quoted.
+func readQueryValue(runes []rune, i int) (string, int, bool, error) {
+	if i < len(runes) && runes[i] == '"' {
+		start := i
+		i++
+		var b strings.Builder
+		for i < len(runes) && runes[i] != '"' {
+			if runes[i] == '\\' && i+1 < len(runes) {
+				i++
+			}
+			b.WriteRune(runes[i])
+			i++
+		}
+		if i >= len(runes) {
+			return "", 0, false, &QuerySyntaxError{Pos: start + 1, Msg: "unterminated quote"}
+		}
+		i++ // Closing quote
+		if i < len(runes) && !unicode.IsSpace(runes[i]) {
+			return "", 0, false, &QuerySyntaxError{Pos: i + 1, Msg: "expected a space after closing quote"}
+		}
+		return b.String(), i, true, nil
+	}
+
+	start := i
+	for i < len(runes) && !unicode.IsSpace(runes[i]) {
+		if runes[i] == '"' {
+			return "", 0, false, &QuerySyntaxError{Pos: i + 1, Msg: "unexpected quote inside a value"}
+		}
+		i++
+	}
+	return string(runes[start:i]), i, false, nil
+}
+
+// compileTerm validates a raw term and builds its matcher.
+func compileTerm(raw rawTerm) (queryTerm, error) {
+	term := queryTerm{negate: raw.negate}
+	folded := FoldText(raw.value)
+	fail := func(format string, args ...any) (queryTerm, error) {
+		return queryTerm{}, &QuerySyntaxError{Pos: raw.pos, Msg: fmt.Sprintf(format, args...)}
+	}
+
+	switch raw.field {
+	case "":
+		// Free text: names, company, emails
+		term.match = func(c *ContactDetails) bool {
+			return containsFolded(c.DisplayName+" "+c.FirstName+" "+c.LastName+" "+c.Company, folded) ||
+				anyEmailContains(c, folded)
+		}
+	case "name":
+		term.match = func(c *ContactDetails) bool {
+			return containsFolded(c.DisplayName+" "+c.FirstName+" "+c.LastName, folded)
+		}
+	case "first":
+		term.match = func(c *ContactDetails) bool { return containsFolded(c.FirstName, folded) }
+	case "last":
+		term.match = func(c *ContactDetails) bool { return containsFolded(c.LastName, folded) }
+	case "company":
+		term.match = func(c *ContactDetails) bool { return containsFolded(c.Company, folded) }
+	case "position":
+		term.match = func(c *ContactDetails) bool { return containsFolded(c.Position, folded) }
+	case "notes":
+		term.match = func(c *ContactDetails) bool { return containsFolded(c.Notes, folded) }
+	case "email":
+		term.match = func(c *ContactDetails) bool { return anyEmailContains(c, folded) }
+	case "phone":
+		digits := phoneDigits(raw.value)
+		if digits == "" {
+			return fail("phone value '%s' contains no digits", raw.value)
+		}
+		term.match = func(c *ContactDetails) bool { return phoneScore(*c, digits) > 0 }
+	case "address":
+		term.match = func(c *ContactDetails) bool {
+			return anyAddress(c, func(value string, _ *StructuredAddress) bool { return containsFolded(value, folded) })
+		}
+	case "city":
+		term.match = func(c *ContactDetails) bool {
+			return anyAddress(c, func(_ string, a *StructuredAddress) bool { return a != nil && containsFolded(a.City, folded) })
+		}
+	case "postal":
+		term.match = func(c *ContactDetails) bool {
+			return anyAddress(c, func(_ string, a *StructuredAddress) bool {
+				return a != nil && a.PostalCode != "" && strings.HasPrefix(FoldText(a.PostalCode), folded)
+			})
+		}
+	case "country":
+		term.match = func(c *ContactDetails) bool {
+			return anyAddress(c, func(_ string, a *StructuredAddress) bool { return a != nil && containsFolded(a.Country, folded) })
+		}
+	case "has":
+		check, ok := hasTargets[strings.ToLower(raw.value)]
+		if !ok {
+			return fail("unknown has: target '%s', valid targets: %s", raw.value, strings.Join(sortedKeys(hasTargets), ", "))
+		}
+		term.match = check
+	case "updated":
+		op, value := splitQueryOperator(raw.value)
+		if !queryDateRegex.MatchString(value) {
+			return fail("invalid updated: date '%s', expected YYYY, YYYY-MM or YYYY-MM-DD with optional >, >=, <, <=", raw.value)
+		}
+		term.match = func(c *ContactDetails) bool {
+			return c.UpdatedAt != "" && compareDatePrefix(c.UpdatedAt, value, op)
+		}
+	case "birthday":
+		match, err := compileBirthday(raw.value)
+		if err != nil {
+			return fail("%v", err)
+		}
+		term.match = match
+	default:
+		return fail("unknown field '%s', valid fields: %s", raw.field, strings.Join(QueryFields, ", "))
+	}
+	return term, nil
+}
+
+// compileBirthday builds a matcher for birthday:<spec>.
+// Specs: MM (month), MM-DD (day of year), YYYY or YYYY-MM-DD with optional
+// comparison operator (requires a known birth year).
+func compileBirthday(spec string) (func(c *ContactDetails) bool, error) {
+	op, value := splitQueryOperator(spec)
+
+	if op == "=" && queryMonthRegex.MatchString(value) {
+		month, _ := strconv.Atoi(value)
+		if month < 1 || month > 12 {
+			return nil, fmt.Errorf("invalid birthday month '%s'", value)
+		}
+		return func(c *ContactDetails) bool {
+			_, m, _, ok := splitDate(c.Birthday)
+			return ok && m == month
+		}, nil
+	}
+
+	if op == "=" {
+		if m := queryMonthDayRegex.FindStringSubmatch(value); m != nil {
+			month, _ := strconv.Atoi(m[1])
+			day, _ := strconv.Atoi(m[2])
+			if month < 1 || month > 12 || day < 1 || day > 31 {
+				return nil, fmt.Errorf("invalid birthday month-day '%s'", value)
+			}
+			return func(c *ContactDetails) bool {
+				_, bm, bd, ok := splitDate(c.Birthday)
+				return ok && bm == month && bd == day
+			}, nil
+		}
+	}
+
+	if len(value) == 4 || len(value) == 10 {
+		if queryDateRegex.MatchString(value) {
+			return func(c *ContactDetails) bool {
+				// Birthdays without a year (--MM-DD) cannot be compared
+				return c.Birthday != "" && !strings.HasPrefix(c.Birthday, "--") &&
+					compareDatePrefix(c.Birthday, value, op)
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("invalid birthday '%s', expected MM, MM-DD, YYYY or YYYY-MM-DD (years accept >, >=, <, <=)", spec)
+}
+
+// splitQueryOperator separates a leading comparison operator from a value.
+// Returns "=" when no operator is present.
+func splitQueryOperator(value string) (op, rest string) {
+	for _, candidate := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(value, candidate) {
+			return candidate, value[len(candidate):]
+		}
+	}
+	return "=", value
+}
+
+// compareDatePrefix compares the leading part of an ISO date or timestamp
+// with a YYYY[-MM[-DD]] value, at the value's precision.
+func compareDatePrefix(date, value, op string) bool {
+	if len(date) < len(value) {
+		return false
+	}
+	prefix := date[:len(value)]
+	switch op {
+	case ">":
+		return prefix > value
+	case ">=":
+		return prefix >= value
+	case "<":
+		return prefix < value
+	case "<=":
+		return prefix <= value
+	default:
+		return prefix == value
+	}
+}
+
+// containsFolded reports whether the folded text contains the folded needle.
+func containsFolded(text, foldedNeedle string) bool {
+	return strings.Contains(FoldText(text), foldedNeedle)
+}
+
+// anyEmailContains reports whether any email contains the folded needle.
+func anyEmailContains(c *ContactDetails, foldedNeedle string) bool {
+	for _, email := range c.Emails {
+		if containsFolded(email.Value, foldedNeedle) {
+			return true
+		}
+	}
+	return false
+}
+
+// anyAddress reports whether any address satisfies fn.
+func anyAddress(c *ContactDetails, fn func(value string, parsed *StructuredAddress) bool) bool {
+	for _, addr := range c.Addresses {
+		if fn(addr.Value, ParseAddress(addr.Value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys returns the keys of a map in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
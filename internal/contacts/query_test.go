@@ -0,0 +1,165 @@
+package contacts
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseQuery_Match(t *testing.T) {
+	contact := ContactDetails{
+		ResourceName: "people/c1",
+		DisplayName:  "François DUPONT",
+		FirstName:    "François",
+		LastName:     "DUPONT",
+		Company:      "Acme Corp",
+		Position:     "CTO",
+		Phones:       []PhoneEntry{{Value: "+33612345678"}},
+		Addresses:    []AddressEntry{{Value: "10 rue de la Paix, 75002 Paris, France"}},
+		Birthday:     "1980-10-15",
+		Notes:        "Met at GopherCon",
+		UpdatedAt:    "2025-03-04T10:00:00Z",
+	}
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"", true},
+		{"francois", true},
+		{"company:acme", true},
+		{"company:\"acme corp\"", true},
+		{"company:globex", false},
+		{"name:dupont first:franc", true},
+		{"last:francois", false},
+		{"position:cto", true},
+		{"notes:gophercon", true},
+		{"has:phone", true},
+		{"has:email", false},
+		{"-has:email", true},
+		{"has:phone -has:email", true},
+		{"phone:0612", true},
+		{"phone:\"+33 6 12\"", true},
+		{"phone:0700", false},
+		{"city:paris", true},
+		{"city:lyon", false},
+		{"postal:75", true},
+		{"country:france", true},
+		{"address:\"rue de la paix\"", true},
+		{"birthday:10", true},
+		{"birthday:11", false},
+		{"birthday:10-15", true},
+		{"birthday:10-16", false},
+		{"birthday:1980", true},
+		{"birthday:>1979", true},
+		{"birthday:<1980", false},
+		{"birthday:<=1980-10-15", true},
+		{"updated:>2025-01-01", true},
+		{"updated:<2025-01-01", false},
+		{"updated:2025-03", true},
+		{"updated:>=2025", true},
+		{"company:acme has:phone -has:email birthday:10 city:Paris updated:>2025-01-01", true},
+		{"company:globex OR city:paris", true},
+		{"company:globex OR city:lyon", false},
+		{"company:acme city:lyon OR name:dupont", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q) unexpected error: %v", tt.query, err)
+			}
+			if got := q.Match(&contact); got != tt.want {
+				t.Errorf("ParseQuery(%q).Match() = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQuery_YearlessBirthday(t *testing.T) {
+	contact := ContactDetails{Birthday: "--10-15", FirstName: "John"}
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"birthday:10", true},
+		{"birthday:10-15", true},
+		{"birthday:>1900", false},
+		{"updated:>2000", false},
+		{"-updated:>2000", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q) unexpected error: %v", tt.query, err)
+			}
+			if got := q.Match(&contact); got != tt.want {
+				t.Errorf("ParseQuery(%q).Match() = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	tests := []struct {
+		query       string
+		wantPos     int
+		errContains string
+	}{
+		{"foo:bar", 1, "unknown field 'foo'"},
+		{"company:acme bogus:1", 14, "unknown field 'bogus'"},
+		{"company:", 9, "missing value after 'company:'"},
+		{":acme", 1, "missing field name"},
+		{"company:\"acme", 9, "unterminated quote"},
+		{"has:fax", 1, "unknown has: target 'fax'"},
+		{"updated:yesterday", 1, "invalid updated: date"},
+		{"birthday:13", 1, "invalid birthday month"},
+		{"birthday:>10", 1, "invalid birthday"},
+		{"phone:abc", 1, "contains no digits"},
+		{"OR company:acme", 1, "OR must be between two terms"},
+		{"company:acme OR", 14, "OR must be between two terms"},
+		{"a OR OR b", 6, "OR must be between two terms"},
+		{"- acme", 1, "'-' must be followed by a term"},
+		{"ac\"me", 3, "unexpected quote"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := ParseQuery(tt.query)
+			if err == nil {
+				t.Fatalf("ParseQuery(%q) expected error, got nil", tt.query)
+			}
+			var syntaxErr *QuerySyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("ParseQuery(%q) error type = %T, want *QuerySyntaxError", tt.query, err)
+			}
+			if syntaxErr.Pos != tt.wantPos {
+				t.Errorf("ParseQuery(%q) error position = %d, want %d", tt.query, syntaxErr.Pos, tt.wantPos)
+			}
+			if !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("ParseQuery(%q) error = %q, want error containing %q", tt.query, err.Error(), tt.errContains)
+			}
+		})
+	}
+}
+
+func TestFilterContacts(t *testing.T) {
+	list := []ContactDetails{
+		{DisplayName: "Zoé B", Company: "Acme"},
+		{DisplayName: "Alice A", Company: "Globex"},
+		{DisplayName: "Émile C", Company: "ACME Inc"},
+	}
+
+	q, err := ParseQuery("company:acme")
+	if err != nil {
+		t.Fatalf("ParseQuery() unexpected error: %v", err)
+	}
+	got := FilterContacts(list, q)
+	if len(got) != 2 || got[0].DisplayName != "Émile C" || got[1].DisplayName != "Zoé B" {
+		t.Errorf("FilterContacts() = %+v", got)
+	}
+}
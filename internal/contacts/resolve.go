@@ -0,0 +1,135 @@
+package contacts
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// contactIDPattern matches bare People API contact IDs (e.g. "c123456789").
+var contactIDPattern = regexp.MustCompile(`^c[0-9]+$`)
+
+// IsContactID reports whether ref is a contact ID or resource name rather
+// than a name, email or phone number to search for.
+func IsContactID(ref string) bool {
+	return strings.HasPrefix(ref, "people/") || contactIDPattern.MatchString(ref)
+}
+
+// AmbiguousContactError is returned by ResolveContact when a reference
+// matches several contacts, or only matches contacts partially. Candidates
+// lists them so that the caller can ask the user to choose or report them.
+type AmbiguousContactError struct {
+	Query      string
+	Candidates []SearchResult
+	Partial    bool // No candidate matches the reference exactly
+}
+
+func (e *AmbiguousContactError) Error() string {
+	var b strings.Builder
+	if e.Partial {
+		fmt.Fprintf(&b, "no contact matches %q exactly, use one of the IDs:", e.Query)
+	} else {
+		fmt.Fprintf(&b, "%q matches %d contacts, use one of the IDs:", e.Query, len(e.Candidates))
+	}
+	for _, c := range e.Candidates {
+		name := c.DisplayName
+		if name == "" {
+			name = "(no name)"
+		}
+		fmt.Fprintf(&b, "\n  %s  %s", extractID(c.ResourceName), name)
+		if detail := candidateDetail(c); detail != "" {
+			fmt.Fprintf(&b, " (%s)", detail)
+		}
+	}
+	return b.String()
+}
+
+// candidateDetail returns the email, phone or company that helps telling
+// homonyms apart.
+func candidateDetail(c SearchResult) string {
+	var parts []string
+	for _, v := range []string{c.Email, c.Phone, c.Company} {
+		if v != "" {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ResolveContact returns the resource name of the contact designated by ref,
+// which is either a contact ID ("c123", "people/c123") or a name, email or
+// phone number. Non-ID references are searched with SearchContacts and must
+// match a single contact exactly: several exact matches, or search results
+// without exact match (even a single one), return an *AmbiguousContactError
+// so that a contact is never picked from a partial match.
+func (s *Service) ResolveContact(ctx context.Context, ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", fmt.Errorf("contact is required")
+	}
+	if IsContactID(ref) {
+		if !strings.HasPrefix(ref, "people/") {
+			ref = "people/" + ref
+		}
+		return ref, nil
+	}
+
+	results, err := s.SearchContacts(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+
+	if len(results) == 0 {
+		return "", fmt.Errorf("no contact matches %q", ref)
+	}
+	candidates := MatchContacts(results, ref)
+	switch len(candidates) {
+	case 0:
+		return "", &AmbiguousContactError{Query: ref, Candidates: results, Partial: true}
+	case 1:
+		return candidates[0].ResourceName, nil
+	}
+	return "", &AmbiguousContactError{Query: ref, Candidates: candidates}
+}
+
+// MatchContacts narrows search results to the exact matches of ref: same
+// email (case-insensitive), same normalized phone number, or same name
+// ignoring case and accents.
+func MatchContacts(results []SearchResult, ref string) []SearchResult {
+	var exact []SearchResult
+	for _, r := range results {
+		if isExactMatch(r, ref) {
+			exact = append(exact, r)
+		}
+	}
+	return exact
+}
+
+// isExactMatch reports whether ref is the email, phone number or name of r.
+func isExactMatch(r SearchResult, ref string) bool {
+	if strings.Contains(ref, "@") {
+		// Gmail dots and +aliases deliver to the same mailbox
+		return r.Email != "" && (strings.EqualFold(r.Email, ref) || SameEmail(r.Email, ref))
+	}
+	if isPhoneReference(ref) {
+		return r.Phone != "" && phoneDigits(NormalizePhoneNumber(r.Phone)) == phoneDigits(NormalizePhoneNumber(ref))
+	}
+	return r.DisplayName != "" && FoldText(r.DisplayName) == FoldText(ref)
+}
+
+// isPhoneReference reports whether ref looks like a phone number: digits
+// with optional separators and a leading "+".
+func isPhoneReference(ref string) bool {
+	digits := 0
+	for _, r := range ref {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case strings.ContainsRune("+ .-()", r):
+		default:
+			return false
+		}
+	}
+	return digits >= 4
+}
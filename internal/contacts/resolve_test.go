@@ -0,0 +1,87 @@
+package contacts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsContactID(t *testing.T) {
+	tests := []struct {
+		ref      string
+		expected bool
+	}{
+		{"c123456789", true},
+		{"people/c123456789", true},
+		{"Jane Doe", false},
+		{"carl", false},
+		{"jane@example.com", false},
+		{"+33612345678", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.ref, func(t *testing.T) {
+			if got := IsContactID(tc.ref); got != tc.expected {
+				t.Errorf("IsContactID(%q) = %v, want %v", tc.ref, got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestMatchContacts(t *testing.T) {
+	results := []SearchResult{
+		{ResourceName: "people/c1", DisplayName: "Jane Doe", Email: "jane@example.com", Phone: "06 12 34 56 78"},
+		{ResourceName: "people/c2", DisplayName: "Jane Doerr", Email: "jdoerr@example.com"},
+		{ResourceName: "people/c3", DisplayName: "Hélène Martin", Phone: "+33 6 99 88 77 66"},
+		{ResourceName: "people/c4", DisplayName: "John Doerr", Email: "jdoerr@gmail.com"},
+	}
+
+	tests := []struct {
+		name     string
+		ref      string
+		expected []string
+	}{
+		{name: "exact name", ref: "jane doe", expected: []string{"people/c1"}},
+		{name: "name without accents", ref: "Helene Martin", expected: []string{"people/c3"}},
+		{name: "email", ref: "JANE@example.com", expected: []string{"people/c1"}},
+		{name: "gmail alias", ref: "J.Doerr+work@googlemail.com", expected: []string{"people/c4"}},
+		{name: "local phone", ref: "0699887766", expected: []string{"people/c3"}},
+		{name: "international phone", ref: "+33 6 12 34 56 78", expected: []string{"people/c1"}},
+		{name: "no exact match", ref: "Jane", expected: nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := MatchContacts(results, tc.ref)
+			if len(got) != len(tc.expected) {
+				t.Fatalf("MatchContacts(%q) returned %d results, want %d", tc.ref, len(got), len(tc.expected))
+			}
+			for i, r := range got {
+				if r.ResourceName != tc.expected[i] {
+					t.Errorf("MatchContacts(%q)[%d] = %s, want %s", tc.ref, i, r.ResourceName, tc.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAmbiguousContactError(t *testing.T) {
+	err := &AmbiguousContactError{
+		Query: "Jane",
+		Candidates: []SearchResult{
+			{ResourceName: "people/c1", DisplayName: "Jane Doe", Email: "jane@example.com"},
+			{ResourceName: "people/c2"},
+		},
+	}
+
+	msg := err.Error()
+	for _, want := range []string{`"Jane" matches 2 contacts`, "c1  Jane Doe (jane@example.com)", "c2  (no name)"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Error() = %q, missing %q", msg, want)
+		}
+	}
+
+	err = &AmbiguousContactError{Query: "Jan", Candidates: err.Candidates[:1], Partial: true}
+	if msg := err.Error(); !strings.Contains(msg, `no contact matches "Jan" exactly`) || !strings.Contains(msg, "c1  Jane Doe") {
+		t.Errorf("Error() = %q, want the partial candidates", msg)
+	}
+}
@@ -92,8 +92,9 @@ type EmailEntry struct {
 
 // AddressEntry represents a postal address with its label.
 type AddressEntry struct {
-	Value string // Formatted address string
-	Type  string // home, work, other
+	Value      string // Formatted address string
+	Type       string // home, work, other
+	Structured bool   // True if city or postal code are stored as separate fields
 }
 
 // StructuredAddress represents a parsed postal address with structured fields.
@@ -120,10 +121,21 @@ type ContactDetails struct {
 	Position     string
 	Notes        string
 	Birthday     string // Format: YYYY-MM-DD or --MM-DD (if year unknown)
+	Events       []EventEntry
+	Groups       []string // Contact group resource names (contactGroups/<id>)
 	CreatedAt    string
 	UpdatedAt    string
 }
 
+// EventEntry represents a significant date (anniversary, etc.) with its label.
+type EventEntry struct {
+	Date string // Format: YYYY-MM-DD or --MM-DD (if year unknown)
+	Type string // anniversary, other, or a custom label
+}
+
+// detailPersonFields lists the person fields fetched for full contact details.
+const detailPersonFields = "names,phoneNumbers,emailAddresses,addresses,organizations,biographies,birthdays,events,memberships,metadata"
+
 // extractID extracts the contact ID from a resource name (e.g., "people/c123" -> "c123")
 func extractID(resourceName string) string {
 	if len(resourceName) > 7 && resourceName[:7] == "people/" {
@@ -183,13 +195,29 @@ func parseBirthday(birthday string) *people.Birthday {
 	return &people.Birthday{Date: date}
 }
 
+// ValidateBirthday checks that a birthday is in YYYY-MM-DD or --MM-DD format.
+// An empty birthday is valid.
+func ValidateBirthday(birthday string) error {
+	if birthday != "" && parseBirthday(birthday) == nil {
+		return fmt.Errorf("invalid birthday '%s': expected YYYY-MM-DD or --MM-DD", birthday)
+	}
+	return nil
+}
+
 // formatBirthday formats a birthday from People API to a display string.
 // Returns format: "YYYY-MM-DD" or "--MM-DD" (if year is 0/unknown)
 func formatBirthday(birthday *people.Birthday) string {
-	if birthday == nil || birthday.Date == nil {
+	if birthday == nil {
+		return ""
+	}
+	return formatDate(birthday.Date)
+}
+
+// formatDate formats a People API date to "YYYY-MM-DD" or "--MM-DD" (if year is 0/unknown).
+func formatDate(d *people.Date) string {
+	if d == nil {
 		return ""
 	}
-	d := birthday.Date
 	if d.Year == 0 {
 		return fmt.Sprintf("--%02d-%02d", d.Month, d.Day)
 	}
@@ -447,10 +475,11 @@ func isPostalCode(s string) bool {
 
 // NormalizePhoneNumber converts a phone number to international format with country code.
 // Rules:
-// - Phone starting with "0" (French local format): prepends "+33" and removes leading "0"
-// - Phone starting with "00" (international prefix): replaces "00" with "+"
-// - Phone already starting with "+": keeps as-is
-// - Removes spaces, dashes, dots, and parentheses for consistency
+//   - Phone starting with the trunk prefix of the phone region (national format,
+//     "0" in France, see SetPhoneRegion): replaces it with the country calling code
+//   - Phone starting with "00" (international prefix): replaces "00" with "+"
+//   - Phone already starting with "+": keeps as-is
+//   - Removes spaces, dashes, dots, and parentheses for consistency
 //
 // Examples:
 //   - "0612345678" → "+33612345678"
@@ -487,9 +516,9 @@ func NormalizePhoneNumber(phone string) string {
 		// International prefix "00" → "+"
 		return "+" + result[2:]
 
-	case strings.HasPrefix(result, "0"):
-		// French local format, prepend +33 and remove leading 0
-		return "+33" + result[1:]
+	case strings.HasPrefix(result, currentPhoneRegion.trunkPrefix):
+		// National format, replace the trunk prefix with the calling code
+		return "+" + currentPhoneRegion.callingCode + result[len(currentPhoneRegion.trunkPrefix):]
 
 	default:
 		// No prefix, return as-is (might be just digits)
@@ -500,6 +529,11 @@ func NormalizePhoneNumber(phone string) string {
 // CreateContact creates a new contact in Google Contacts.
 // Returns the created contact's resource name and display name.
 func (s *Service) CreateContact(ctx context.Context, input ContactInput) (*CreatedContact, error) {
+	// Reject malformed email addresses before calling the API
+	if err := validateEmailEntries(input.Emails); err != nil {
+		return nil, err
+	}
+
 	person := &people.Person{
 		Names: []*people.Name{
 			{
@@ -514,7 +548,7 @@ func (s *Service) CreateContact(ctx context.Context, input ContactInput) (*Creat
 	for _, phone := range input.Phones {
 		phoneType := phone.Type
 		if phoneType == "" {
-			phoneType = "mobile"
+			phoneType = DefaultPhoneType
 		}
 		person.PhoneNumbers = append(person.PhoneNumbers, &people.PhoneNumber{
 			Value: NormalizePhoneNumber(phone.Value),
@@ -526,7 +560,7 @@ func (s *Service) CreateContact(ctx context.Context, input ContactInput) (*Creat
 	for _, email := range input.Emails {
 		emailType := email.Type
 		if emailType == "" {
-			emailType = "work"
+			emailType = DefaultEmailType
 		}
 		person.EmailAddresses = append(person.EmailAddresses, &people.EmailAddress{
 			Value: email.Value,
@@ -564,7 +598,7 @@ func (s *Service) CreateContact(ctx context.Context, input ContactInput) (*Creat
 	for _, addr := range input.Addresses {
 		addrType := addr.Type
 		if addrType == "" {
-			addrType = "home"
+			addrType = DefaultAddressType
 		}
 
 		// Parse address to extract structured fields
@@ -777,13 +811,31 @@ func (s *Service) UpdateContact(ctx context.Context, resourceName string, input
 
 	// First, fetch the current contact to get etag and merge changes
 	current, err := s.People.Get(resourceName).
-		PersonFields("names,phoneNumbers,emailAddresses,addresses,organizations,biographies,birthdays,metadata").
+		PersonFields(detailPersonFields).
 		Context(ctx).
 		Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get contact: %w", err)
 	}
 
+	// Reject malformed email addresses before updating, except those the
+	// contact already has, so that they can be kept or restored
+	var existingEmails []string
+	for _, email := range current.EmailAddresses {
+		existingEmails = append(existingEmails, email.Value)
+	}
+	if input.Email != nil {
+		if err := ValidateNewEmails([]EmailEntry{{Value: *input.Email}}, existingEmails); err != nil {
+			return nil, err
+		}
+	}
+	if err := ValidateNewEmails(input.Emails, existingEmails); err != nil {
+		return nil, err
+	}
+	if err := validateEmailEntries(input.AddEmails); err != nil {
+		return nil, err
+	}
+
 	// Build the update mask for only the fields we're updating
 	var updateFields []string
 
@@ -808,7 +860,7 @@ func (s *Service) UpdateContact(ctx context.Context, resourceName string, input
 	// Normalize phone number to international format
 	if input.Phone != nil {
 		if len(current.PhoneNumbers) == 0 {
-			current.PhoneNumbers = []*people.PhoneNumber{{Type: "mobile"}}
+			current.PhoneNumbers = []*people.PhoneNumber{{Type: DefaultPhoneType}}
 		}
 		current.PhoneNumbers[0].Value = NormalizePhoneNumber(*input.Phone)
 		phoneUpdated = true
@@ -821,7 +873,7 @@ func (s *Service) UpdateContact(ctx context.Context, resourceName string, input
 		for _, phone := range input.Phones {
 			phoneType := phone.Type
 			if phoneType == "" {
-				phoneType = "mobile"
+				phoneType = DefaultPhoneType
 			}
 			current.PhoneNumbers = append(current.PhoneNumbers, &people.PhoneNumber{
 				Value: NormalizePhoneNumber(phone.Value),
@@ -837,7 +889,7 @@ func (s *Service) UpdateContact(ctx context.Context, resourceName string, input
 		for _, phone := range input.AddPhones {
 			phoneType := phone.Type
 			if phoneType == "" {
-				phoneType = "mobile"
+				phoneType = DefaultPhoneType
 			}
 			current.PhoneNumbers = append(current.PhoneNumbers, &people.PhoneNumber{
 				Value: NormalizePhoneNumber(phone.Value),
@@ -878,7 +930,7 @@ func (s *Service) UpdateContact(ctx context.Context, resourceName string, input
 	// Option 1: --email flag replaces first email (backward compatibility)
 	if input.Email != nil {
 		if len(current.EmailAddresses) == 0 {
-			current.EmailAddresses = []*people.EmailAddress{{Type: "work"}}
+			current.EmailAddresses = []*people.EmailAddress{{Type: DefaultEmailType}}
 		}
 		current.EmailAddresses[0].Value = *input.Email
 		emailUpdated = true
@@ -890,7 +942,7 @@ func (s *Service) UpdateContact(ctx context.Context, resourceName string, input
 		for _, email := range input.Emails {
 			emailType := email.Type
 			if emailType == "" {
-				emailType = "work"
+				emailType = DefaultEmailType
 			}
 			current.EmailAddresses = append(current.EmailAddresses, &people.EmailAddress{
 				Value: email.Value,
@@ -905,7 +957,7 @@ func (s *Service) UpdateContact(ctx context.Context, resourceName string, input
 		for _, email := range input.AddEmails {
 			emailType := email.Type
 			if emailType == "" {
-				emailType = "work"
+				emailType = DefaultEmailType
 			}
 			current.EmailAddresses = append(current.EmailAddresses, &people.EmailAddress{
 				Value: email.Value,
@@ -921,6 +973,7 @@ func (s *Service) UpdateContact(ctx context.Context, resourceName string, input
 		for _, email := range current.EmailAddresses {
 			shouldRemove := false
 			for _, removeValue := range input.RemoveEmails {
+				// Exact match: Gmail aliases are distinct entries of the contact
 				if email.Value == removeValue {
 					shouldRemove = true
 					break
@@ -947,7 +1000,7 @@ func (s *Service) UpdateContact(ctx context.Context, resourceName string, input
 		for _, addr := range input.Addresses {
 			addrType := addr.Type
 			if addrType == "" {
-				addrType = "home"
+				addrType = DefaultAddressType
 			}
 
 			// Parse address to extract structured fields
@@ -978,7 +1031,7 @@ func (s *Service) UpdateContact(ctx context.Context, resourceName string, input
 		for _, addr := range input.AddAddresses {
 			addrType := addr.Type
 			if addrType == "" {
-				addrType = "home"
+				addrType = DefaultAddressType
 			}
 
 			// Parse address to extract structured fields
@@ -1072,91 +1125,14 @@ func (s *Service) UpdateContact(ctx context.Context, resourceName string, input
 	// Perform the update
 	updated, err := s.People.UpdateContact(resourceName, current).
 		UpdatePersonFields(strings.Join(updateFields, ",")).
-		PersonFields("names,phoneNumbers,emailAddresses,addresses,organizations,biographies,birthdays,metadata").
+		PersonFields(detailPersonFields).
 		Context(ctx).
 		Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to update contact: %w", err)
 	}
 
-	// Convert to ContactDetails
-	details := &ContactDetails{
-		ResourceName: updated.ResourceName,
-	}
-
-	// Extract names
-	if len(updated.Names) > 0 {
-		name := updated.Names[0]
-		details.FirstName = name.GivenName
-		details.LastName = name.FamilyName
-		details.DisplayName = name.DisplayName
-	}
-
-	// Extract all phone numbers with labels
-	for _, phone := range updated.PhoneNumbers {
-		entry := PhoneEntry{
-			Value: phone.Value,
-			Type:  phone.Type,
-		}
-		if entry.Type == "" {
-			entry.Type = "other"
-		}
-		details.Phones = append(details.Phones, entry)
-	}
-
-	// Extract all email addresses with labels
-	for _, email := range updated.EmailAddresses {
-		entry := EmailEntry{
-			Value: email.Value,
-			Type:  email.Type,
-		}
-		if entry.Type == "" {
-			entry.Type = "other"
-		}
-		details.Emails = append(details.Emails, entry)
-	}
-
-	// Extract all addresses with labels
-	for _, addr := range updated.Addresses {
-		entry := AddressEntry{
-			Value: addr.FormattedValue,
-			Type:  addr.Type,
-		}
-		if entry.Type == "" {
-			entry.Type = "other"
-		}
-		details.Addresses = append(details.Addresses, entry)
-	}
-
-	// Extract company and position
-	if len(updated.Organizations) > 0 {
-		org := updated.Organizations[0]
-		details.Company = org.Name
-		details.Position = org.Title
-	}
-
-	// Extract notes
-	if len(updated.Biographies) > 0 {
-		details.Notes = updated.Biographies[0].Value
-	}
-
-	// Extract birthday
-	if len(updated.Birthdays) > 0 {
-		details.Birthday = formatBirthday(updated.Birthdays[0])
-	}
-
-	// Extract metadata (creation/update times)
-	if updated.Metadata != nil {
-		for _, source := range updated.Metadata.Sources {
-			if source.Type == "CONTACT" {
-				if source.UpdateTime != "" {
-					details.UpdatedAt = source.UpdateTime
-				}
-			}
-		}
-	}
-
-	return details, nil
+	return contactDetailsFromPerson(updated), nil
 }
 
 // GetContactDetails retrieves full details for a single contact by its resource name.
@@ -1169,13 +1145,40 @@ func (s *Service) GetContactDetails(ctx context.Context, resourceName string) (*
 	}
 
 	p, err := s.People.Get(resourceName).
-		PersonFields("names,phoneNumbers,emailAddresses,addresses,organizations,biographies,birthdays,metadata").
+		PersonFields(detailPersonFields).
 		Context(ctx).
 		Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get contact: %w", err)
 	}
 
+	return contactDetailsFromPerson(p), nil
+}
+
+// ListContacts retrieves full details for every contact in the address book.
+// Pages through connections.list until all contacts have been fetched.
+func (s *Service) ListContacts(ctx context.Context) ([]ContactDetails, error) {
+	var results []ContactDetails
+
+	err := s.People.Connections.List("people/me").
+		PersonFields(detailPersonFields).
+		PageSize(1000).
+		Context(ctx).
+		Pages(ctx, func(resp *people.ListConnectionsResponse) error {
+			for _, p := range resp.Connections {
+				results = append(results, *contactDetailsFromPerson(p))
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list contacts: %w", err)
+	}
+
+	return results, nil
+}
+
+// contactDetailsFromPerson converts a People API person into ContactDetails.
+func contactDetailsFromPerson(p *people.Person) *ContactDetails {
 	details := &ContactDetails{
 		ResourceName: p.ResourceName,
 	}
@@ -1215,8 +1218,9 @@ func (s *Service) GetContactDetails(ctx context.Context, resourceName string) (*
 	// Extract all addresses with labels
 	for _, addr := range p.Addresses {
 		entry := AddressEntry{
-			Value: addr.FormattedValue,
-			Type:  addr.Type,
+			Value:      addr.FormattedValue,
+			Type:       addr.Type,
+			Structured: addr.City != "" || addr.PostalCode != "",
 		}
 		if entry.Type == "" {
 			entry.Type = "other"
@@ -1241,6 +1245,29 @@ func (s *Service) GetContactDetails(ctx context.Context, resourceName string) (*
 		details.Birthday = formatBirthday(p.Birthdays[0])
 	}
 
+	// Extract events (anniversaries and other significant dates)
+	for _, event := range p.Events {
+		date := formatDate(event.Date)
+		if date == "" {
+			continue
+		}
+		entry := EventEntry{
+			Date: date,
+			Type: event.Type,
+		}
+		if entry.Type == "" {
+			entry.Type = "other"
+		}
+		details.Events = append(details.Events, entry)
+	}
+
+	// Extract contact group memberships
+	for _, m := range p.Memberships {
+		if m.ContactGroupMembership != nil {
+			details.Groups = append(details.Groups, m.ContactGroupMembership.ContactGroupResourceName)
+		}
+	}
+
 	// Extract metadata (creation/update times)
 	if p.Metadata != nil {
 		for _, source := range p.Metadata.Sources {
@@ -1252,5 +1279,5 @@ func (s *Service) GetContactDetails(ctx context.Context, resourceName string) (*
 		}
 	}
 
-	return details, nil
+	return details
 }
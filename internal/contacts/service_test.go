@@ -681,3 +681,26 @@ func TestNormalizePhoneNumber(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateBirthday(t *testing.T) {
+	tests := []struct {
+		birthday string
+		wantErr  bool
+	}{
+		{"", false},
+		{"1985-03-15", false},
+		{"--03-15", false},
+		{"15/03/1985", true},
+		{"1985-13-01", true},
+		{"--3", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.birthday, func(t *testing.T) {
+			err := ValidateBirthday(tc.birthday)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ValidateBirthday(%q) error = %v, wantErr %v", tc.birthday, err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package contacts
+
+import (
+	"io"
+	"strings"
+)
+
+// vCardPhoneTypes maps phone labels to vCard 3.0 TEL types.
+var vCardPhoneTypes = map[string]string{
+	"mobile": "CELL",
+	"work":   "WORK",
+	"home":   "HOME",
+	"main":   "PREF",
+}
+
+// WriteVCard writes contacts as vCard 3.0 (RFC 2426) cards, the format
+// accepted by Google Contacts, Apple Contacts and most address books.
+// Content lines use the same CRLF, escaping and folding rules as iCalendar.
+func WriteVCard(w io.Writer, list []ContactDetails) error {
+	var b strings.Builder
+	for _, c := range list {
+		writeICalLine(&b, "BEGIN:VCARD")
+		writeICalLine(&b, "VERSION:3.0")
+
+		name := c.DisplayName
+		if name == "" {
+			name = strings.TrimSpace(c.FirstName + " " + c.LastName)
+		}
+		writeICalLine(&b, "FN:"+escapeICalText(name))
+		writeICalLine(&b, "N:"+escapeICalText(c.LastName)+";"+escapeICalText(c.FirstName)+";;;")
+
+		for _, phone := range c.Phones {
+			telType := vCardPhoneTypes[phone.Type]
+			if telType == "" {
+				telType = "VOICE"
+			}
+			writeICalLine(&b, "TEL;TYPE="+telType+":"+escapeICalText(phone.Value))
+		}
+
+		for _, email := range c.Emails {
+			emailType := "INTERNET"
+			if email.Type == "work" || email.Type == "home" {
+				emailType += "," + strings.ToUpper(email.Type)
+			}
+			writeICalLine(&b, "EMAIL;TYPE="+emailType+":"+escapeICalText(email.Value))
+		}
+
+		for _, addr := range c.Addresses {
+			writeICalLine(&b, vCardAddress(addr))
+		}
+
+		if c.Company != "" {
+			writeICalLine(&b, "ORG:"+escapeICalText(c.Company))
+		}
+		if c.Position != "" {
+			writeICalLine(&b, "TITLE:"+escapeICalText(c.Position))
+		}
+		if c.Birthday != "" && !strings.HasPrefix(c.Birthday, "--") {
+			// vCard 3.0 has no yearless dates: --MM-DD birthdays are left out
+			writeICalLine(&b, "BDAY:"+c.Birthday)
+		}
+		if c.Notes != "" {
+			writeICalLine(&b, "NOTE:"+escapeICalText(c.Notes))
+		}
+		if c.ResourceName != "" {
+			writeICalLine(&b, "UID:"+escapeICalText(c.ResourceName))
+		}
+		if c.UpdatedAt != "" {
+			writeICalLine(&b, "REV:"+c.UpdatedAt)
+		}
+
+		writeICalLine(&b, "END:VCARD")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// vCardAddress builds an ADR line, splitting the address into structured
+// components when it can be parsed.
+func vCardAddress(addr AddressEntry) string {
+	adrType := "OTHER"
+	if addr.Type == "home" || addr.Type == "work" {
+		adrType = strings.ToUpper(addr.Type)
+	}
+
+	// ADR components: PO box; extended; street; locality; region; postal code; country
+	street, city, region, postal, country := addr.Value, "", "", "", ""
+	if parsed := ParseAddress(addr.Value); parsed != nil && (parsed.City != "" || parsed.PostalCode != "") {
+		street, city, region, postal, country = parsed.StreetAddress, parsed.City, parsed.Region, parsed.PostalCode, parsed.Country
+	}
+	parts := []string{"", "", street, city, region, postal, country}
+	for i, part := range parts {
+		parts[i] = escapeICalText(part)
+	}
+	return "ADR;TYPE=" + adrType + ":" + strings.Join(parts, ";")
+}
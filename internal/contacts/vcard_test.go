@@ -0,0 +1,67 @@
+package contacts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteVCard(t *testing.T) {
+	list := []ContactDetails{
+		{
+			ResourceName: "people/c111",
+			FirstName:    "Jane",
+			LastName:     "DOE",
+			DisplayName:  "Jane DOE",
+			Phones:       []PhoneEntry{{Value: "+33612345678", Type: "mobile"}, {Value: "+33100000000", Type: "fax"}},
+			Emails:       []EmailEntry{{Value: "jane@example.com", Type: "work"}},
+			Addresses:    []AddressEntry{{Value: "10 Rue Example, 75001 Paris, France", Type: "home"}},
+			Company:      "Acme, Inc.",
+			Position:     "CTO",
+			Notes:        "Met at conference; likes tea",
+			Birthday:     "1985-03-15",
+		},
+		{ResourceName: "people/c222", FirstName: "John", Birthday: "--12-01"},
+	}
+
+	var b strings.Builder
+	if err := WriteVCard(&b, list); err != nil {
+		t.Fatalf("WriteVCard() error: %v", err)
+	}
+	out := b.String()
+
+	expected := []string{
+		"BEGIN:VCARD\r\nVERSION:3.0\r\n",
+		"FN:Jane DOE\r\n",
+		"N:DOE;Jane;;;\r\n",
+		"TEL;TYPE=CELL:+33612345678\r\n",
+		"TEL;TYPE=VOICE:+33100000000\r\n",
+		"EMAIL;TYPE=INTERNET,WORK:jane@example.com\r\n",
+		"ADR;TYPE=HOME:;;10 Rue Example;Paris;;75001;France\r\n",
+		`ORG:Acme\, Inc.` + "\r\n",
+		"TITLE:CTO\r\n",
+		"BDAY:1985-03-15\r\n",
+		`NOTE:Met at conference\; likes tea` + "\r\n",
+		"UID:people/c111\r\n",
+		"FN:John\r\n",
+		"END:VCARD\r\n",
+	}
+	for _, want := range expected {
+		if !strings.Contains(out, want) {
+			t.Errorf("WriteVCard() output missing %q", want)
+		}
+	}
+	if strings.Contains(out, "BDAY:--") {
+		t.Error("WriteVCard() wrote a yearless BDAY, invalid in vCard 3.0")
+	}
+	if strings.Count(out, "BEGIN:VCARD") != 2 {
+		t.Errorf("expected 2 cards, got %d", strings.Count(out, "BEGIN:VCARD"))
+	}
+}
+
+func TestVCardAddress_Unparsed(t *testing.T) {
+	got := vCardAddress(AddressEntry{Value: "Somewhere", Type: "other"})
+	want := "ADR;TYPE=OTHER:;;Somewhere;;;;"
+	if got != want {
+		t.Errorf("vCardAddress() = %q, want %q", got, want)
+	}
+}
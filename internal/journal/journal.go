@@ -0,0 +1,217 @@
+// Package journal records contact modifications in a local append-only
+// JSON Lines file, with the contact state before and after each operation,
+// so that they can be listed and undone.
+package journal
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google-contacts/internal/contacts"
+)
+
+// Operation is the kind of modification recorded in an entry.
+type Operation string
+
+// Recorded operations.
+const (
+	OpCreate Operation = "create"
+	OpUpdate Operation = "update"
+	OpDelete Operation = "delete"
+)
+
+// Sources of the recorded operations.
+const (
+	SourceCLI = "cli"
+	SourceMCP = "mcp"
+)
+
+// Entry is one recorded operation. Before is nil for creations and After
+// is nil for deletions. Undoes holds the ID of the entry reverted by this
+// operation, if any. Account is the email address of the account the
+// contact belongs to, empty if it could not be read.
+type Entry struct {
+	ID           string                   `json:"id"`
+	Time         time.Time                `json:"time"`
+	Operation    Operation                `json:"operation"`
+	Source       string                   `json:"source"`
+	Account      string                   `json:"account,omitempty"`
+	Command      string                   `json:"command,omitempty"`
+	ResourceName string                   `json:"resourceName"`
+	DisplayName  string                   `json:"displayName"`
+	Before       *contacts.ContactDetails `json:"before,omitempty"`
+	After        *contacts.ContactDetails `json:"after,omitempty"`
+	Undoes       string                   `json:"undoes,omitempty"`
+}
+
+// NewEntry returns the entry of an operation made by command from source.
+// The contact is identified by after, or by before for deletions.
+func NewEntry(op Operation, source, command string, before, after *contacts.ContactDetails) Entry {
+	e := Entry{
+		Operation: op,
+		Source:    source,
+		Command:   command,
+		Before:    before,
+		After:     after,
+	}
+	c := after
+	if c == nil {
+		c = before
+	}
+	if c != nil {
+		e.ResourceName = c.ResourceName
+		e.DisplayName = c.DisplayName
+	}
+	return e
+}
+
+// Journal is the append-only operation log stored at Path.
+// The file contains personal data and is written with 0600 permissions.
+type Journal struct {
+	Path string
+}
+
+// DefaultPath returns the journal location in the user config directory.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "google-contacts", "journal.jsonl"), nil
+}
+
+// Append records an entry, assigning its ID and time when unset.
+// It returns the recorded entry.
+func (j *Journal) Append(e Entry) (Entry, error) {
+	if e.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return Entry{}, err
+		}
+		e.ID = id
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(j.Path), 0700); err != nil {
+		return Entry{}, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	f, err := os.OpenFile(j.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+
+	// A single write keeps concurrent appends from interleaving lines
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return Entry{}, fmt.Errorf("failed to write journal: %w", err)
+	}
+	return e, nil
+}
+
+// Entries returns all recorded entries, oldest first. A missing journal is
+// empty. Unreadable lines (e.g. a write interrupted by a crash) are skipped.
+func (j *Journal) Entries() ([]Entry, error) {
+	f, err := os.Open(j.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil || e.ID == "" {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	return entries, nil
+}
+
+// Find returns the entry whose ID starts with id. The prefix must be unambiguous.
+func Find(entries []Entry, id string) (Entry, error) {
+	var found []Entry
+	for _, e := range entries {
+		if e.ID == id {
+			return e, nil
+		}
+		if id != "" && strings.HasPrefix(e.ID, id) {
+			found = append(found, e)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return Entry{}, fmt.Errorf("no operation %q in the journal", id)
+	case 1:
+		return found[0], nil
+	}
+	return Entry{}, fmt.Errorf("operation ID %q is ambiguous (%d matches)", id, len(found))
+}
+
+// ForAccount returns the entries of account. Entries without an account
+// were recorded by older versions or when the account could not be read:
+// those of the CLI are kept, as the journal belongs to the profile, but those
+// of the MCP server are dropped since any caller may have made them.
+func ForAccount(entries []Entry, account string) []Entry {
+	var owned []Entry
+	for _, e := range entries {
+		if e.Account == "" && e.Source == SourceCLI ||
+			e.Account != "" && strings.EqualFold(e.Account, account) {
+			owned = append(owned, e)
+		}
+	}
+	return owned
+}
+
+// UndoneBy returns the ID of the entry that reverted id, or "" if it has
+// not been undone.
+func UndoneBy(entries []Entry, id string) string {
+	for _, e := range entries {
+		if e.Undoes == id {
+			return e.ID
+		}
+	}
+	return ""
+}
+
+// LastUndoable returns the most recent entry that is neither an undo nor
+// already undone, so that repeated undos walk back through the history.
+func LastUndoable(entries []Entry) (Entry, bool) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.Undoes == "" && UndoneBy(entries, e.ID) == "" {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// newID returns a short random operation ID.
+func newID() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate operation ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,156 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google-contacts/internal/contacts"
+)
+
+func TestJournal_AppendEntries(t *testing.T) {
+	j := &Journal{Path: filepath.Join(t.TempDir(), "sub", "journal.jsonl")}
+
+	entries, err := j.Entries()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Entries() on missing journal = %v, %v; want empty", entries, err)
+	}
+
+	first, err := j.Append(Entry{
+		Operation:    OpUpdate,
+		Source:       SourceCLI,
+		ResourceName: "people/c1",
+		Before:       &contacts.ContactDetails{Phones: []contacts.PhoneEntry{{Value: "+33612345678", Type: "mobile"}}},
+		After:        &contacts.ContactDetails{},
+	})
+	if err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if first.ID == "" || first.Time.IsZero() {
+		t.Errorf("Append() did not assign ID and time: %+v", first)
+	}
+	if _, err := j.Append(Entry{Operation: OpUpdate, Source: SourceCLI, Undoes: first.ID}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	// Interrupted writes are skipped
+	f, err := os.OpenFile(j.Path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"id":"trunc`)
+	f.Close()
+
+	info, err := os.Stat(j.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("journal permissions = %o, want 600", info.Mode().Perm())
+	}
+
+	entries, err = j.Entries()
+	if err != nil {
+		t.Fatalf("Entries() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Entries() returned %d entries, want 2", len(entries))
+	}
+	if got := entries[0].Before.Phones[0].Value; got != "+33612345678" {
+		t.Errorf("Before phone = %q, want +33612345678", got)
+	}
+	if got := UndoneBy(entries, first.ID); got != entries[1].ID {
+		t.Errorf("UndoneBy() = %q, want %q", got, entries[1].ID)
+	}
+}
+
+func TestFind(t *testing.T) {
+	entries := []Entry{{ID: "ab12cd34"}, {ID: "ab99ef00"}, {ID: "ff000000"}}
+
+	tests := []struct {
+		id      string
+		want    string
+		wantErr bool
+	}{
+		{id: "ab12cd34", want: "ab12cd34"},
+		{id: "ff", want: "ff000000"},
+		{id: "ab", wantErr: true},
+		{id: "00", wantErr: true},
+		{id: "", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.id, func(t *testing.T) {
+			got, err := Find(entries, tc.id)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Find(%q) error = %v, wantErr %v", tc.id, err, tc.wantErr)
+			}
+			if got.ID != tc.want {
+				t.Errorf("Find(%q) = %q, want %q", tc.id, got.ID, tc.want)
+			}
+		})
+	}
+}
+
+func TestLastUndoable(t *testing.T) {
+	entries := []Entry{
+		{ID: "a"},
+		{ID: "b"},
+		{ID: "c"},
+		{ID: "d", Undoes: "c"},
+	}
+	if got, ok := LastUndoable(entries); !ok || got.ID != "b" {
+		t.Errorf("LastUndoable() = %q, %v; want b", got.ID, ok)
+	}
+	if _, ok := LastUndoable([]Entry{{ID: "a"}, {ID: "b", Undoes: "a"}}); ok {
+		t.Error("LastUndoable() found an entry, want none")
+	}
+}
+
+func TestForAccount(t *testing.T) {
+	entries := []Entry{
+		{ID: "a", Source: SourceCLI, Account: "jane@example.com"},
+		{ID: "b", Source: SourceMCP, Account: "John@Example.com"},
+		{ID: "c", Source: SourceMCP, Account: "Jane@Example.com"},
+		{ID: "d", Source: SourceCLI},
+		{ID: "e", Source: SourceMCP},
+	}
+
+	var ids []string
+	for _, e := range ForAccount(entries, "jane@example.com") {
+		ids = append(ids, e.ID)
+	}
+	if got := strings.Join(ids, ","); got != "a,c,d" {
+		t.Errorf("ForAccount() = %s, want a,c,d", got)
+	}
+}
+
+func TestNewEntry(t *testing.T) {
+	before := &contacts.ContactDetails{ResourceName: "people/c1", DisplayName: "Jane DOE"}
+	after := &contacts.ContactDetails{ResourceName: "people/c1", DisplayName: "Janet DOE"}
+
+	tests := []struct {
+		name     string
+		op       Operation
+		before   *contacts.ContactDetails
+		after    *contacts.ContactDetails
+		wantName string
+	}{
+		{name: "create", op: OpCreate, after: after, wantName: "Janet DOE"},
+		{name: "update", op: OpUpdate, before: before, after: after, wantName: "Janet DOE"},
+		{name: "delete", op: OpDelete, before: before, wantName: "Jane DOE"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := NewEntry(tc.op, SourceMCP, "contacts_"+tc.name, tc.before, tc.after)
+			if e.ResourceName != "people/c1" || e.DisplayName != tc.wantName {
+				t.Errorf("NewEntry() contact = %s %q, want people/c1 %q", e.ResourceName, e.DisplayName, tc.wantName)
+			}
+			if e.Operation != tc.op || e.Source != SourceMCP || e.Command != "contacts_"+tc.name {
+				t.Errorf("NewEntry() = %+v", e)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package journal
+
+import (
+	"slices"
+
+	"google-contacts/internal/contacts"
+)
+
+// RestoreInput returns the update that brings current back to target, with
+// the field-level changes it makes. Lists that differ are replaced as a
+// whole, or emptied by removing every current value. Only the fields
+// managed by UpdateContact are restored (not events or group memberships).
+func RestoreInput(current, target *contacts.ContactDetails) (contacts.UpdateInput, []contacts.FixChange) {
+	var input contacts.UpdateInput
+	var changes []contacts.FixChange
+
+	setString := func(field, want, have string, dst **string) {
+		if want != have {
+			value := want
+			*dst = &value
+			changes = append(changes, contacts.FixChange{Field: field, Before: have, After: want})
+		}
+	}
+	setString("firstName", target.FirstName, current.FirstName, &input.FirstName)
+	setString("lastName", target.LastName, current.LastName, &input.LastName)
+	setString("company", target.Company, current.Company, &input.Company)
+	setString("position", target.Position, current.Position, &input.Position)
+	setString("notes", target.Notes, current.Notes, &input.Notes)
+
+	if target.Birthday != current.Birthday {
+		if target.Birthday == "" {
+			input.ClearBirthday = true
+		} else {
+			birthday := target.Birthday
+			input.Birthday = &birthday
+		}
+		changes = append(changes, contacts.FixChange{Field: "birthday", Before: current.Birthday, After: target.Birthday})
+	}
+
+	if have, want := contacts.PhoneValues(current.Phones), contacts.PhoneValues(target.Phones); !slices.Equal(have, want) {
+		if len(target.Phones) > 0 {
+			input.Phones = target.Phones
+		} else {
+			for _, p := range current.Phones {
+				input.RemovePhones = append(input.RemovePhones, p.Value)
+			}
+		}
+		changes = append(changes, contacts.ListChanges("phones", have, want)...)
+	}
+	if have, want := contacts.EmailValues(current.Emails), contacts.EmailValues(target.Emails); !slices.Equal(have, want) {
+		if len(target.Emails) > 0 {
+			input.Emails = target.Emails
+		} else {
+			for _, e := range current.Emails {
+				input.RemoveEmails = append(input.RemoveEmails, e.Value)
+			}
+		}
+		changes = append(changes, contacts.ListChanges("emails", have, want)...)
+	}
+	if have, want := contacts.AddressValues(current.Addresses), contacts.AddressValues(target.Addresses); !slices.Equal(have, want) {
+		if len(target.Addresses) > 0 {
+			input.Addresses = target.Addresses
+		} else {
+			for _, a := range current.Addresses {
+				input.RemoveAddresses = append(input.RemoveAddresses, a.Value)
+			}
+		}
+		changes = append(changes, contacts.ListChanges("addresses", have, want)...)
+	}
+
+	return input, changes
+}
+
+// RecreateInput returns the creation input of a deleted contact.
+// Events and group memberships are not recreated.
+func RecreateInput(before *contacts.ContactDetails) contacts.ContactInput {
+	return contacts.ContactInput{
+		FirstName: before.FirstName,
+		LastName:  before.LastName,
+		Phones:    before.Phones,
+		Emails:    before.Emails,
+		Addresses: before.Addresses,
+		Company:   before.Company,
+		Position:  before.Position,
+		Notes:     before.Notes,
+		Birthday:  before.Birthday,
+	}
+}
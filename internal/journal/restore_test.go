@@ -0,0 +1,69 @@
+package journal
+
+import (
+	"slices"
+	"testing"
+
+	"google-contacts/internal/contacts"
+)
+
+func TestRestoreInput(t *testing.T) {
+	target := &contacts.ContactDetails{
+		FirstName: "Jane",
+		LastName:  "DOE",
+		Phones: []contacts.PhoneEntry{
+			{Value: "+33612345678", Type: "mobile"},
+			{Value: "+33145678901", Type: "fax"},
+		},
+		Company:  "Acme",
+		Birthday: "1985-03-15",
+	}
+	current := &contacts.ContactDetails{
+		FirstName: "Jane",
+		LastName:  "DOE",
+		Phones:    []contacts.PhoneEntry{{Value: "+33700000000", Type: "mobile"}},
+		Emails:    []contacts.EmailEntry{{Value: "jane@example.com", Type: "work"}},
+		Notes:     "added",
+	}
+
+	input, changes := RestoreInput(current, target)
+
+	if input.FirstName != nil || input.LastName != nil {
+		t.Error("unchanged names should not be updated")
+	}
+	if input.Company == nil || *input.Company != "Acme" {
+		t.Errorf("Company = %v, want Acme", input.Company)
+	}
+	if input.Notes == nil || *input.Notes != "" {
+		t.Errorf("Notes = %v, want cleared", input.Notes)
+	}
+	if input.Birthday == nil || *input.Birthday != "1985-03-15" {
+		t.Errorf("Birthday = %v, want 1985-03-15", input.Birthday)
+	}
+	// Custom labels are restored as is
+	if !slices.Equal(input.Phones, target.Phones) {
+		t.Errorf("Phones = %v, want %v", input.Phones, target.Phones)
+	}
+	if !slices.Equal(input.RemoveEmails, []string{"jane@example.com"}) {
+		t.Errorf("RemoveEmails = %v, want the current emails", input.RemoveEmails)
+	}
+	if input.Emails != nil || input.Addresses != nil || input.RemoveAddresses != nil {
+		t.Error("unchanged or emptied lists should not be replaced")
+	}
+
+	// company, notes, birthday, 3 phone changes, 1 email removal
+	if len(changes) != 7 {
+		t.Errorf("RestoreInput() returned %d changes, want 7: %+v", len(changes), changes)
+	}
+
+	if _, changes := RestoreInput(target, target); len(changes) != 0 {
+		t.Errorf("RestoreInput() on identical contacts returned %+v", changes)
+	}
+}
+
+func TestRestoreInput_ClearBirthday(t *testing.T) {
+	input, _ := RestoreInput(&contacts.ContactDetails{Birthday: "--03-15"}, &contacts.ContactDetails{})
+	if !input.ClearBirthday || input.Birthday != nil {
+		t.Errorf("RestoreInput() = %+v, want ClearBirthday", input)
+	}
+}
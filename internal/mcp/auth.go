@@ -21,9 +21,10 @@ import (
 	"github.com/google/uuid"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
-	gmail "google.golang.org/api/gmail/v1"
 	"google.golang.org/api/option"
 	people "google.golang.org/api/people/v1"
+
+	"google-contacts/pkg/auth"
 )
 
 //go:embed templates/success.html
@@ -40,16 +41,6 @@ func init() {
 	}
 }
 
-// OAuthScopes contains all OAuth2 scopes for Gmail and People APIs.
-// Matches the scopes in pkg/auth/auth.go for consistency.
-var OAuthScopes = []string{
-	gmail.GmailModifyScope,
-	gmail.GmailSendScope,
-	gmail.GmailLabelsScope,
-	people.ContactsScope,
-	people.ContactsOtherReadonlyScope,
-}
-
 // stateEntry stores OAuth state parameters with expiration.
 type stateEntry struct {
 	CreatedAt time.Time
@@ -70,6 +61,7 @@ type AuthHandler struct {
 	secretProject  string                // GCP project for Secret Manager
 	secretName     string                // Secret name for OAuth credentials
 	credentialFile string                // Local credential file path (fallback)
+	scopeSet       string                // Google scope set requested (auth.ScopeSet*)
 }
 
 // AuthHandlerConfig holds configuration for creating an AuthHandler.
@@ -79,6 +71,7 @@ type AuthHandlerConfig struct {
 	SecretProject  string // GCP project for Secret Manager
 	SecretName     string // Secret Manager secret name for OAuth credentials
 	CredentialFile string // Fallback: local credential file path
+	ScopeSet       string // Google scope set requested (auth.ScopeSet*, defaults to unified)
 }
 
 // NewAuthHandler creates a new AuthHandler with the given configuration.
@@ -91,6 +84,10 @@ func NewAuthHandler(cfg *AuthHandlerConfig) *AuthHandler {
 		secretProject:  cfg.SecretProject,
 		secretName:     cfg.SecretName,
 		credentialFile: cfg.CredentialFile,
+		scopeSet:       cfg.ScopeSet,
+	}
+	if h.scopeSet == "" {
+		h.scopeSet = auth.DefaultScopeSet
 	}
 
 	// Start background goroutine to clean up expired states
@@ -155,7 +152,11 @@ func (h *AuthHandler) loadOAuthCredentials(ctx context.Context) error {
 	}
 
 	// Parse credentials
-	config, err := google.ConfigFromJSON(credentialsJSON, OAuthScopes...)
+	scopes, err := auth.ScopesFor(h.scopeSet)
+	if err != nil {
+		return err
+	}
+	config, err := google.ConfigFromJSON(credentialsJSON, scopes...)
 	if err != nil {
 		return fmt.Errorf("failed to parse OAuth credentials: %w", err)
 	}
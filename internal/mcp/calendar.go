@@ -0,0 +1,107 @@
+// Package mcp provides the MCP (Model Context Protocol) server implementation
+// for google-contacts, enabling AI assistants to manage contacts remotely.
+// This file implements the iCalendar subscription feed of contact birthdays and events.
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"google-contacts/internal/contacts"
+	"google-contacts/pkg/auth"
+)
+
+// calendarFeedTTL is how long a generated feed is served from memory before
+// contacts are fetched again. Calendar clients typically poll every few hours.
+const calendarFeedTTL = 15 * time.Minute
+
+// CalendarFeed serves an iCalendar feed of contact birthdays and events
+// at a tokenized URL (/calendar/<token>.ics) for calendar subscriptions.
+type CalendarFeed struct {
+	token     string
+	alarmDays []int
+	fetch     func(ctx context.Context) ([]contacts.ContactDetails, error)
+
+	mu       sync.Mutex
+	cached   []byte
+	cachedAt time.Time
+}
+
+// NewCalendarFeed creates a calendar feed protected by the given token.
+// Contacts are fetched with the server's local credentials (CLI token file).
+func NewCalendarFeed(token string, alarmDays []int) *CalendarFeed {
+	return &CalendarFeed{
+		token:     token,
+		alarmDays: alarmDays,
+		fetch: func(ctx context.Context) ([]contacts.ContactDetails, error) {
+			srv, err := contacts.GetPeopleService(auth.WithNonInteractive(ctx))
+			if err != nil {
+				return nil, err
+			}
+			return srv.ListContacts(ctx)
+		},
+	}
+}
+
+// ServeHTTP serves the feed.
+// GET /calendar/<token>.ics
+func (f *CalendarFeed) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Unknown tokens get a 404 so the feed URL cannot be probed
+	token := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/calendar/"), ".ics")
+	if f.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(f.token)) != 1 {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, err := f.render(r.Context())
+	if err != nil {
+		log.Printf("Failed to render calendar feed: %v", err)
+		http.Error(w, "Failed to generate calendar", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, max-age=900")
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write(body)
+}
+
+// render returns the cached feed or regenerates it when the cache has expired.
+func (f *CalendarFeed) render(ctx context.Context) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.cached != nil && time.Since(f.cachedAt) < calendarFeedTTL {
+		return f.cached, nil
+	}
+
+	list, err := f.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := contacts.WriteICal(&buf, list, contacts.ICalOptions{
+		CalendarName: "Contacts birthdays",
+		AlarmDays:    f.alarmDays,
+	}); err != nil {
+		return nil, err
+	}
+
+	f.cached = buf.Bytes()
+	f.cachedAt = time.Now()
+	return f.cached, nil
+}
@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google-contacts/internal/contacts"
+)
+
+func newTestCalendarFeed(calls *int) *CalendarFeed {
+	feed := NewCalendarFeed("secret-token", []int{1})
+	feed.fetch = func(ctx context.Context) ([]contacts.ContactDetails, error) {
+		*calls++
+		return []contacts.ContactDetails{
+			{ResourceName: "people/c123", DisplayName: "Jane Doe", Birthday: "1985-03-15"},
+		}, nil
+	}
+	return feed
+}
+
+func TestCalendarFeed_InvalidToken(t *testing.T) {
+	calls := 0
+	feed := newTestCalendarFeed(&calls)
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"wrong token", "/calendar/wrong-token.ics"},
+		{"empty token", "/calendar/.ics"},
+		{"prefix of token", "/calendar/secret.ics"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			rec := httptest.NewRecorder()
+			feed.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("expected status 404, got %d", rec.Code)
+			}
+		})
+	}
+
+	if calls != 0 {
+		t.Errorf("contacts fetched %d times for invalid tokens, want 0", calls)
+	}
+}
+
+func TestCalendarFeed_ValidToken(t *testing.T) {
+	calls := 0
+	feed := newTestCalendarFeed(&calls)
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/calendar/secret-token.ics", nil)
+		rec := httptest.NewRecorder()
+		feed.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+			t.Errorf("Content-Type = %q, want text/calendar", ct)
+		}
+		body := rec.Body.String()
+		if !strings.Contains(body, "UID:c123-birthday@google-contacts") {
+			t.Error("feed missing birthday event")
+		}
+		if !strings.Contains(body, "TRIGGER:-P1D") {
+			t.Error("feed missing alarm")
+		}
+	}
+
+	// Second request must be served from cache
+	if calls != 1 {
+		t.Errorf("contacts fetched %d times, want 1", calls)
+	}
+}
+
+func TestCalendarFeed_MethodNotAllowed(t *testing.T) {
+	calls := 0
+	feed := newTestCalendarFeed(&calls)
+
+	req := httptest.NewRequest(http.MethodPost, "/calendar/secret-token.ics", nil)
+	rec := httptest.NewRecorder()
+	feed.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405, got %d", rec.Code)
+	}
+}
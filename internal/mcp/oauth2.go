@@ -8,20 +8,24 @@
 // 3. Client registers via /oauth/register (Dynamic Client Registration)
 // 4. Client redirects user to /oauth/authorize → we redirect to Google OAuth
 // 5. Google returns code to /oauth/callback → we exchange with Google
-// 6. Client exchanges code at /oauth/token → we return Google tokens
-// 7. Client sends Bearer token on MCP requests → we validate and use for People API
+// 6. Client exchanges code at /oauth/token → we issue opaque tokens, the Google token stays in the vault
+// 7. Client sends Bearer token on MCP requests → we look it up in the vault and use its Google token
+// 8. Client inspects tokens at /oauth/introspect and revokes them at /oauth/revoke (with the Google token)
 package mcp
 
 import (
 	"context"
+	"crypto/cipher"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -31,13 +35,17 @@ import (
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	people "google.golang.org/api/people/v1"
+
+	"google-contacts/pkg/auth"
 )
 
-// OAuth2Scopes defines the scopes required for Google Contacts access.
-var OAuth2Scopes = []string{
-	people.ContactsScope,
-	people.ContactsOtherReadonlyScope,
-}
+// Scopes offered to MCP clients. They are mapped to Google scopes within the
+// scope set of the server: contacts:read to contacts-readonly, contacts:write
+// to the server scope set.
+const (
+	ScopeContactsRead  = "contacts:read"
+	ScopeContactsWrite = "contacts:write"
+)
 
 // ProtectedResourceMetadata represents RFC 9728 protected resource metadata.
 type ProtectedResourceMetadata struct {
@@ -58,6 +66,10 @@ type AuthorizationServerMetadata struct {
 	GrantTypesSupported               []string `json:"grant_types_supported"`
 	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
 	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	RevocationEndpoint                string   `json:"revocation_endpoint,omitempty"`
+	RevocationAuthMethodsSupported    []string `json:"revocation_endpoint_auth_methods_supported,omitempty"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint,omitempty"`
+	IntrospectionAuthMethodsSupported []string `json:"introspection_endpoint_auth_methods_supported,omitempty"`
 }
 
 // ClientRegistrationRequest represents RFC 7591 dynamic client registration request.
@@ -107,32 +119,55 @@ type TokenErrorResponse struct {
 	ErrorDescription string `json:"error_description,omitempty"`
 }
 
-// registeredClient stores registered OAuth client information.
+// Lifetimes of the OAuth2 server entries. Clients are kept while they keep
+// authorizing; states and codes only live for one authorization.
+const (
+	clientTTL        = 90 * 24 * time.Hour
+	authorizationTTL = 10 * time.Minute
+)
+
+// registeredClient stores registered OAuth client information. Only a hash
+// of the client secret is stored, like the issued tokens.
 type registeredClient struct {
-	ClientID     string
-	ClientSecret string
-	RedirectURIs []string
-	CreatedAt    time.Time
+	ClientID     string    `json:"client_id"`
+	SecretHash   string    `json:"client_secret_hash,omitempty"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// LegacySecret is the plaintext secret of the clients registered before
+	// secrets were hashed, replaced by its hash when the client is saved
+	LegacySecret string `json:"client_secret,omitempty"`
+}
+
+// secretHash returns the hash of the client secret, empty for public clients.
+func (c *registeredClient) secretHash() string {
+	if c.LegacySecret != "" {
+		return tokenHash(c.LegacySecret)
+	}
+	return c.SecretHash
 }
 
 // authorizationState stores OAuth authorization state.
 type authorizationState struct {
-	ClientID      string
-	RedirectURI   string
-	CodeChallenge string
-	CodeMethod    string
-	CreatedAt     time.Time
+	ClientID      string    `json:"client_id"`
+	RedirectURI   string    `json:"redirect_uri"`
+	CodeChallenge string    `json:"code_challenge,omitempty"`
+	CodeMethod    string    `json:"code_method,omitempty"`
+	Scopes        []string  `json:"scopes"` // Requested MCP scopes
+	CreatedAt     time.Time `json:"created_at"`
 }
 
-// authorizationCode stores issued authorization codes.
+// authorizationCode stores issued authorization codes, by hash.
 type authorizationCode struct {
-	Code          string
-	ClientID      string
-	RedirectURI   string
-	CodeChallenge string
-	CodeMethod    string
-	GoogleToken   *oauth2.Token // The actual Google OAuth token
-	CreatedAt     time.Time
+	ClientID      string    `json:"client_id"`
+	RedirectURI   string    `json:"redirect_uri"`
+	CodeChallenge string    `json:"code_challenge,omitempty"`
+	CodeMethod    string    `json:"code_method,omitempty"`
+	Scopes        []string  `json:"scopes"`              // Granted MCP scopes
+	SealedToken   []byte    `json:"sealed_google_token"` // The Google OAuth token, encrypted by the vault
+	Subject       string    `json:"subject"`             // Email of the Google account
+	EmailVerified bool      `json:"email_verified"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 // OAuth2Server handles OAuth 2.1 authorization server endpoints.
@@ -142,21 +177,19 @@ type OAuth2Server struct {
 	googleSecret   string
 	oauthConfig    *oauth2.Config
 	oauthConfigMu  sync.RWMutex
+	revokeURL      string // Google token revocation endpoint
 
-	// In-memory stores (could be replaced with Redis for production)
-	clientsMu sync.RWMutex
-	clients   map[string]*registeredClient
-
-	statesMu sync.RWMutex
-	states   map[string]*authorizationState
-
-	codesMu sync.RWMutex
-	codes   map[string]*authorizationCode
+	// Clients, authorization states and codes, with their TTL
+	store OAuthStore
+	// Grants and the tokens issued to clients
+	vault *tokenVault
 
 	// Configuration
 	secretProject  string
 	secretName     string
 	credentialFile string
+	scopeSet       string
+	identityOnly   bool
 }
 
 // OAuth2ServerConfig holds configuration for the OAuth2 server.
@@ -165,58 +198,38 @@ type OAuth2ServerConfig struct {
 	SecretProject  string // GCP project for Secret Manager
 	SecretName     string // Secret name for OAuth credentials
 	CredentialFile string // Local credential file (fallback)
+	ScopeSet       string // Widest Google scope set granted (auth.ScopeSet*, defaults to contacts)
+	// IdentityOnly only asks Google for the caller identity: the contacts
+	// are accessed with a service account acting as the caller.
+	IdentityOnly bool
+	// Store keeps the server state (defaults to an in-memory store)
+	Store OAuthStore
+	// TokenCipher encrypts the Google tokens kept in Store (see
+	// NewTokenCipher). It defaults to a random key, which only suits stores
+	// that do not outlive the process.
+	TokenCipher cipher.AEAD
 }
 
 // NewOAuth2Server creates a new OAuth2 authorization server.
 func NewOAuth2Server(cfg *OAuth2ServerConfig) *OAuth2Server {
-	s := &OAuth2Server{
+	scopeSet := cfg.ScopeSet
+	if scopeSet == "" {
+		scopeSet = auth.ScopeSetContacts
+	}
+	store := cfg.Store
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &OAuth2Server{
 		baseURL:        cfg.BaseURL,
 		secretProject:  cfg.SecretProject,
 		secretName:     cfg.SecretName,
 		credentialFile: cfg.CredentialFile,
-		clients:        make(map[string]*registeredClient),
-		states:         make(map[string]*authorizationState),
-		codes:          make(map[string]*authorizationCode),
-	}
-
-	// Start cleanup goroutines
-	go s.cleanupExpiredStates()
-	go s.cleanupExpiredCodes()
-
-	return s
-}
-
-// cleanupExpiredStates removes expired authorization states.
-func (s *OAuth2Server) cleanupExpiredStates() {
-	ticker := time.NewTicker(1 * time.Minute)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		s.statesMu.Lock()
-		now := time.Now()
-		for state, entry := range s.states {
-			if now.Sub(entry.CreatedAt) > 10*time.Minute {
-				delete(s.states, state)
-			}
-		}
-		s.statesMu.Unlock()
-	}
-}
-
-// cleanupExpiredCodes removes expired authorization codes.
-func (s *OAuth2Server) cleanupExpiredCodes() {
-	ticker := time.NewTicker(1 * time.Minute)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		s.codesMu.Lock()
-		now := time.Now()
-		for code, entry := range s.codes {
-			if now.Sub(entry.CreatedAt) > 10*time.Minute {
-				delete(s.codes, code)
-			}
-		}
-		s.codesMu.Unlock()
+		scopeSet:       scopeSet,
+		identityOnly:   cfg.IdentityOnly,
+		revokeURL:      googleRevokeURL,
+		store:          store,
+		vault:          newTokenVault(store, cfg.TokenCipher),
 	}
 }
 
@@ -256,7 +269,14 @@ func (s *OAuth2Server) LoadCredentials(ctx context.Context) error {
 	}
 
 	// Parse credentials - redirect to our callback
-	config, err := google.ConfigFromJSON(credentialsJSON, OAuth2Scopes...)
+	scopes, err := auth.ScopesFor(s.scopeSet)
+	if err != nil {
+		return err
+	}
+	if s.identityOnly {
+		scopes = identityScopes
+	}
+	config, err := google.ConfigFromJSON(credentialsJSON, scopes...)
 	if err != nil {
 		return fmt.Errorf("failed to parse OAuth credentials: %w", err)
 	}
@@ -301,6 +321,8 @@ func (s *OAuth2Server) SetupRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/oauth/authorize", s.HandleAuthorize)
 	mux.HandleFunc("/oauth/callback", s.HandleCallback)
 	mux.HandleFunc("/oauth/token", s.HandleToken)
+	mux.HandleFunc("/oauth/introspect", s.HandleIntrospect)
+	mux.HandleFunc("/oauth/revoke", s.HandleRevoke)
 }
 
 // HandleProtectedResourceMetadata serves RFC 9728 protected resource metadata.
@@ -315,10 +337,7 @@ func (s *OAuth2Server) HandleProtectedResourceMetadata(w http.ResponseWriter, r
 		Resource:               s.baseURL,
 		AuthorizationServers:   []string{s.baseURL},
 		BearerMethodsSupported: []string{"header"},
-		ScopesSupported: []string{
-			"contacts:read",
-			"contacts:write",
-		},
+		ScopesSupported:        s.supportedScopes(),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -334,18 +353,19 @@ func (s *OAuth2Server) HandleAuthorizationServerMetadata(w http.ResponseWriter,
 	}
 
 	metadata := AuthorizationServerMetadata{
-		Issuer:                s.baseURL,
-		AuthorizationEndpoint: s.baseURL + "/oauth/authorize",
-		TokenEndpoint:         s.baseURL + "/oauth/token",
-		RegistrationEndpoint:  s.baseURL + "/oauth/register",
-		ScopesSupported: []string{
-			"contacts:read",
-			"contacts:write",
-		},
+		Issuer:                            s.baseURL,
+		AuthorizationEndpoint:             s.baseURL + "/oauth/authorize",
+		TokenEndpoint:                     s.baseURL + "/oauth/token",
+		RegistrationEndpoint:              s.baseURL + "/oauth/register",
+		ScopesSupported:                   s.supportedScopes(),
 		ResponseTypesSupported:            []string{"code"},
 		GrantTypesSupported:               []string{"authorization_code", "refresh_token"},
 		CodeChallengeMethodsSupported:     []string{"S256"},
 		TokenEndpointAuthMethodsSupported: []string{"none", "client_secret_basic", "client_secret_post"},
+		RevocationEndpoint:                s.baseURL + "/oauth/revoke",
+		RevocationAuthMethodsSupported:    clientAuthMethods,
+		IntrospectionEndpoint:             s.baseURL + "/oauth/introspect",
+		IntrospectionAuthMethodsSupported: clientAuthMethods,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -379,14 +399,16 @@ func (s *OAuth2Server) HandleClientRegistration(w http.ResponseWriter, r *http.R
 	// Store client
 	client := &registeredClient{
 		ClientID:     clientID,
-		ClientSecret: clientSecret,
+		SecretHash:   tokenHash(clientSecret),
 		RedirectURIs: req.RedirectURIs,
 		CreatedAt:    time.Now(),
 	}
 
-	s.clientsMu.Lock()
-	s.clients[clientID] = client
-	s.clientsMu.Unlock()
+	if err := s.store.Put(r.Context(), kindClients, clientID, client, time.Now().Add(clientTTL)); err != nil {
+		log.Printf("Failed to store OAuth client: %v", err)
+		writeOAuthError(w, "server_error", "Failed to register client", http.StatusInternalServerError)
+		return
+	}
 
 	log.Printf("Registered new OAuth client: %s (name: %s)", clientID, req.ClientName)
 
@@ -438,26 +460,31 @@ func (s *OAuth2Server) HandleAuthorize(w http.ResponseWriter, r *http.Request) {
 		writeOAuthError(w, "unsupported_response_type", "Only 'code' response type is supported", http.StatusBadRequest)
 		return
 	}
+	scopes, err := s.requestedScopes(r.URL.Query().Get("scope"))
+	if err != nil {
+		writeOAuthError(w, "invalid_scope", err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	// Check if client exists, auto-register if not
 	// This allows MCP clients like Claude to use the OAuth flow without
 	// explicit Dynamic Client Registration
-	s.clientsMu.RLock()
-	client, exists := s.clients[clientID]
-	s.clientsMu.RUnlock()
-
-	if !exists {
+	var client registeredClient
+	err = s.store.Get(ctx, kindClients, clientID, &client)
+	switch {
+	case errors.Is(err, ErrNotFound):
 		// Auto-register the client with the provided redirect_uri
-		client = &registeredClient{
+		client = registeredClient{
 			ClientID:     clientID,
-			ClientSecret: "", // Not needed for authorization code flow with PKCE
+			SecretHash:   "", // Not needed for authorization code flow with PKCE
 			RedirectURIs: []string{redirectURI},
 			CreatedAt:    time.Now(),
 		}
-		s.clientsMu.Lock()
-		s.clients[clientID] = client
-		s.clientsMu.Unlock()
 		log.Printf("Auto-registered OAuth client: %s with redirect_uri: %s", clientID, redirectURI)
+	case err != nil:
+		log.Printf("Failed to load OAuth client: %v", err)
+		writeOAuthError(w, "server_error", "Failed to load client", http.StatusInternalServerError)
+		return
 	}
 
 	// Validate redirect_uri (for auto-registered clients, we add new URIs dynamically)
@@ -470,12 +497,18 @@ func (s *OAuth2Server) HandleAuthorize(w http.ResponseWriter, r *http.Request) {
 	}
 	if !validRedirect {
 		// Add the new redirect_uri for this client
-		s.clientsMu.Lock()
 		client.RedirectURIs = append(client.RedirectURIs, redirectURI)
-		s.clientsMu.Unlock()
 		log.Printf("Added redirect_uri %s for client %s", redirectURI, clientID)
 	}
 
+	// Saving the client also extends its registration
+	client.SecretHash, client.LegacySecret = client.secretHash(), ""
+	if err := s.store.Put(ctx, kindClients, clientID, &client, time.Now().Add(clientTTL)); err != nil {
+		log.Printf("Failed to store OAuth client: %v", err)
+		writeOAuthError(w, "server_error", "Failed to register client", http.StatusInternalServerError)
+		return
+	}
+
 	// Generate internal state that maps to the client's request
 	internalState := generateSecureToken(32)
 
@@ -485,12 +518,15 @@ func (s *OAuth2Server) HandleAuthorize(w http.ResponseWriter, r *http.Request) {
 		RedirectURI:   redirectURI,
 		CodeChallenge: codeChallenge,
 		CodeMethod:    codeChallengeMethod,
+		Scopes:        scopes,
 		CreatedAt:     time.Now(),
 	}
 
-	s.statesMu.Lock()
-	s.states[internalState] = authState
-	s.statesMu.Unlock()
+	if err := s.store.Put(ctx, kindStates, internalState, authState, time.Now().Add(authorizationTTL)); err != nil {
+		log.Printf("Failed to store authorization state: %v", err)
+		writeOAuthError(w, "server_error", "Failed to start authorization", http.StatusInternalServerError)
+		return
+	}
 
 	// Also store the client's state so we can return it
 	if state != "" {
@@ -506,10 +542,12 @@ func (s *OAuth2Server) HandleAuthorize(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Redirect to Google OAuth
-	authURL := config.AuthCodeURL(internalState, oauth2.AccessTypeOffline, oauth2.ApprovalForce)
+	// Redirect to Google OAuth, keeping the scopes granted earlier so that
+	// a client asking for write access after read access gets both
+	authURL := config.AuthCodeURL(internalState, oauth2.AccessTypeOffline, oauth2.ApprovalForce,
+		oauth2.SetAuthURLParam("scope", strings.Join(s.googleScopes(scopes), " ")), auth.IncludeGrantedScopes)
 
-	log.Printf("Authorization request: client=%s, redirecting to Google OAuth", clientID)
+	log.Printf("Authorization request: client=%s, scopes=%v, redirecting to Google OAuth", clientID, scopes)
 
 	http.Redirect(w, r, authURL, http.StatusFound)
 }
@@ -546,14 +584,11 @@ func (s *OAuth2Server) HandleCallback(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate internal state
-	s.statesMu.Lock()
-	authState, exists := s.states[internalState]
-	if exists {
-		delete(s.states, internalState)
-	}
-	s.statesMu.Unlock()
-
-	if !exists {
+	var authState authorizationState
+	if err := s.store.Take(ctx, kindStates, internalState, &authState); err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			log.Printf("Failed to load authorization state: %v", err)
+		}
 		writeOAuthError(w, "invalid_request", "Invalid or expired state", http.StatusBadRequest)
 		return
 	}
@@ -575,23 +610,41 @@ func (s *OAuth2Server) HandleCallback(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Google OAuth exchange successful, refresh_token_present=%v", googleToken.RefreshToken != "")
 
+	// The tokens issued to the client are bound to the Google account
+	claims, err := parseIDToken(googleToken, config.ClientID)
+	if err != nil {
+		log.Printf("Failed to identify the Google account: %v", err)
+		writeOAuthError(w, "server_error", "Unable to identify the Google account", http.StatusInternalServerError)
+		return
+	}
+
 	// Generate our own authorization code
 	ourCode := generateSecureToken(32)
+	sealed, err := s.vault.sealToken(googleToken, tokenHash(ourCode))
+	if err != nil {
+		log.Printf("Failed to encrypt Google token: %v", err)
+		writeOAuthError(w, "server_error", "Failed to issue authorization code", http.StatusInternalServerError)
+		return
+	}
 
 	// Store the code with the Google token
 	codeEntry := &authorizationCode{
-		Code:          ourCode,
 		ClientID:      authState.ClientID,
 		RedirectURI:   authState.RedirectURI,
 		CodeChallenge: authState.CodeChallenge,
 		CodeMethod:    authState.CodeMethod,
-		GoogleToken:   googleToken,
+		Scopes:        s.grantedScopes(googleToken, authState.Scopes),
+		SealedToken:   sealed,
+		Subject:       claims.Email,
+		EmailVerified: claims.EmailVerified,
 		CreatedAt:     time.Now(),
 	}
 
-	s.codesMu.Lock()
-	s.codes[ourCode] = codeEntry
-	s.codesMu.Unlock()
+	if err := s.store.Put(ctx, kindCodes, tokenHash(ourCode), codeEntry, time.Now().Add(authorizationTTL)); err != nil {
+		log.Printf("Failed to store authorization code: %v", err)
+		writeOAuthError(w, "server_error", "Failed to issue authorization code", http.StatusInternalServerError)
+		return
+	}
 
 	// Redirect back to the client with our code
 	redirectURL := authState.RedirectURI + "?code=" + ourCode
@@ -622,15 +675,19 @@ func (s *OAuth2Server) HandleToken(w http.ResponseWriter, r *http.Request) {
 
 	grantType := r.FormValue("grant_type")
 	code := r.FormValue("code")
-	clientID := r.FormValue("client_id")
 	codeVerifier := r.FormValue("code_verifier")
 	refreshToken := r.FormValue("refresh_token")
 
-	// Also check for client credentials in Authorization header (Basic auth)
-	if clientID == "" {
-		if username, _, ok := r.BasicAuth(); ok {
-			clientID = username
-		}
+	// Tokens are only valid for this server (RFC 8707)
+	if resource := r.FormValue("resource"); resource != "" && strings.TrimSuffix(resource, "/") != s.baseURL {
+		writeOAuthError(w, "invalid_target", "Tokens are only issued for "+s.baseURL, http.StatusBadRequest)
+		return
+	}
+
+	// Clients registered with a secret must present it, as when revoking
+	clientID, ok := s.requireClient(w, r)
+	if !ok {
+		return
 	}
 
 	switch grantType {
@@ -651,20 +708,23 @@ func (s *OAuth2Server) handleAuthorizationCodeGrant(ctx context.Context, w http.
 	}
 
 	// Look up the authorization code
-	s.codesMu.Lock()
-	codeEntry, exists := s.codes[code]
-	if exists {
-		delete(s.codes, code) // Single use
+	var codeEntry authorizationCode
+	if err := s.store.Take(ctx, kindCodes, tokenHash(code), &codeEntry); err != nil { // Single use
+		if !errors.Is(err, ErrNotFound) {
+			log.Printf("Failed to load authorization code: %v", err)
+		}
+		writeOAuthError(w, "invalid_grant", "Invalid or expired authorization code", http.StatusBadRequest)
+		return
 	}
-	s.codesMu.Unlock()
-
-	if !exists {
+	googleToken, err := s.vault.openToken(codeEntry.SealedToken, tokenHash(code))
+	if err != nil {
+		log.Printf("Failed to load authorization code: %v", err)
 		writeOAuthError(w, "invalid_grant", "Invalid or expired authorization code", http.StatusBadRequest)
 		return
 	}
 
 	// Validate client_id matches
-	if clientID != "" && clientID != codeEntry.ClientID {
+	if clientID != codeEntry.ClientID {
 		writeOAuthError(w, "invalid_client", "client_id mismatch", http.StatusUnauthorized)
 		return
 	}
@@ -681,21 +741,25 @@ func (s *OAuth2Server) handleAuthorizationCodeGrant(ctx context.Context, w http.
 		}
 	}
 
-	// Return the Google tokens
-	googleToken := codeEntry.GoogleToken
-
-	resp := TokenResponse{
-		AccessToken:  googleToken.AccessToken,
-		TokenType:    "Bearer",
-		ExpiresIn:    int64(time.Until(googleToken.Expiry).Seconds()),
-		RefreshToken: googleToken.RefreshToken,
-		Scope:        "contacts:read contacts:write",
+	// Keep the Google token in the vault, issue our own tokens
+	tokens, err := s.vault.issue(ctx, &vaultGrant{
+		ClientID:      codeEntry.ClientID,
+		Subject:       codeEntry.Subject,
+		EmailVerified: codeEntry.EmailVerified,
+		Audience:      s.baseURL,
+		Scopes:        codeEntry.Scopes,
+		Refreshable:   s.identityOnly || googleToken.RefreshToken != "",
+		CreatedAt:     time.Now(),
+		GoogleToken:   googleToken,
+	})
+	if err != nil {
+		log.Printf("Failed to store grant: %v", err)
+		writeOAuthError(w, "server_error", "Failed to issue tokens", http.StatusInternalServerError)
+		return
 	}
 
 	log.Printf("Token issued for client: %s", codeEntry.ClientID)
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
+	writeTokenResponse(w, tokens, codeEntry.Scopes)
 }
 
 // handleRefreshTokenGrant handles the refresh_token grant type.
@@ -705,63 +769,199 @@ func (s *OAuth2Server) handleRefreshTokenGrant(ctx context.Context, w http.Respo
 		return
 	}
 
-	// Load Google OAuth config
-	config, err := s.getOAuthConfig(ctx)
-	if err != nil {
-		log.Printf("Failed to load OAuth config: %v", err)
-		writeOAuthError(w, "server_error", "OAuth configuration error", http.StatusInternalServerError)
+	// The Google token is refreshed when used, only our tokens are rotated
+	grant, tokens, err := s.vault.refresh(ctx, refreshToken, clientID)
+	switch {
+	case errors.Is(err, errInvalidToken), errors.Is(err, errClientMismatch):
+		log.Printf("Refresh rejected for client %s: %v", clientID, err)
+		writeOAuthError(w, "invalid_grant", "Invalid or expired refresh token", http.StatusBadRequest)
+		return
+	case err != nil:
+		log.Printf("Failed to refresh tokens: %v", err)
+		writeOAuthError(w, "server_error", "Failed to refresh tokens", http.StatusInternalServerError)
 		return
 	}
 
-	// Create a token source from the refresh token
-	token := &oauth2.Token{
-		RefreshToken: refreshToken,
+	log.Printf("Token refreshed for client: %s", clientID)
+	writeTokenResponse(w, tokens, grant.Scopes)
+}
+
+// writeTokenResponse writes the tokens issued to a client.
+func writeTokenResponse(w http.ResponseWriter, tokens issuedTokens, scopes []string) {
+	resp := TokenResponse{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(time.Until(tokens.Expiry).Seconds()),
+		RefreshToken: tokens.RefreshToken,
+		Scope:        strings.Join(scopes, " "),
 	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(resp)
+}
 
-	tokenSource := config.TokenSource(ctx, token)
+// ValidatedToken is an access token accepted by ValidateAccessToken.
+type ValidatedToken struct {
+	ClientID      string
+	Subject       string // Email of the Google account
+	EmailVerified bool
+	Scopes        []string // Granted MCP scopes
+
+	// Google OAuth config and current token for API calls (nil in
+	// identity-only mode, where the service account makes the calls)
+	Config      *oauth2.Config
+	GoogleToken *oauth2.Token
+}
+
+// ValidateAccessToken validates a Bearer token issued by this server and
+// returns its grant, with a valid Google token for API calls. Unknown,
+// expired and revoked tokens are rejected. This is used by the auth
+// middleware.
+func (s *OAuth2Server) ValidateAccessToken(ctx context.Context, accessToken string) (*ValidatedToken, error) {
+	grant, err := s.vault.access(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if grant.Audience != s.baseURL {
+		return nil, fmt.Errorf("%w: issued for %s", errInvalidToken, grant.Audience)
+	}
+	validated := &ValidatedToken{
+		ClientID:      grant.ClientID,
+		Subject:       grant.Subject,
+		EmailVerified: grant.EmailVerified,
+		Scopes:        grant.Scopes,
+	}
+	if s.identityOnly {
+		return validated, nil
+	}
 
-	// Get a new token (this will refresh if needed)
-	newToken, err := tokenSource.Token()
+	config, err := s.getOAuthConfig(ctx)
 	if err != nil {
-		log.Printf("Failed to refresh token: %v", err)
-		writeOAuthError(w, "invalid_grant", "Failed to refresh token", http.StatusBadRequest)
-		return
+		return nil, fmt.Errorf("failed to load OAuth config: %w", err)
+	}
+	token, err := s.vault.googleToken(ctx, grant, config)
+	if err != nil {
+		// A revoked Google grant invalidates the tokens issued for it
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant" {
+			if err := s.vault.revokeGrant(ctx, grant.ID); err != nil {
+				log.Printf("Failed to revoke grant: %v", err)
+			}
+			return nil, fmt.Errorf("%w: Google authorization revoked", errInvalidToken)
+		}
+		return nil, fmt.Errorf("failed to refresh Google token: %w", err)
 	}
+	validated.Config, validated.GoogleToken = config, token
+	return validated, nil
+}
 
-	resp := TokenResponse{
-		AccessToken:  newToken.AccessToken,
-		TokenType:    "Bearer",
-		ExpiresIn:    int64(time.Until(newToken.Expiry).Seconds()),
-		RefreshToken: newToken.RefreshToken,
-		Scope:        "contacts:read contacts:write",
+// supportedScopes returns the MCP scopes allowed by the server scope set.
+func (s *OAuth2Server) supportedScopes() []string {
+	if s.scopeSet == auth.ScopeSetContactsReadonly {
+		return []string{ScopeContactsRead}
 	}
+	return []string{ScopeContactsRead, ScopeContactsWrite}
+}
 
-	// RefreshToken may be empty if Google didn't rotate it
-	if resp.RefreshToken == "" {
-		resp.RefreshToken = refreshToken
+// requestedScopes parses the scope parameter of an authorization request.
+// No scope requests all the supported ones.
+func (s *OAuth2Server) requestedScopes(param string) ([]string, error) {
+	supported := s.supportedScopes()
+	requested := strings.Fields(param)
+	if len(requested) == 0 {
+		return supported, nil
+	}
+	for _, scope := range requested {
+		if !slices.Contains(supported, scope) {
+			return nil, fmt.Errorf("unsupported scope %q, supported: %s", scope, strings.Join(supported, " "))
+		}
 	}
+	return requested, nil
+}
 
-	log.Printf("Token refreshed for client: %s", clientID)
+// identityScopes are the Google scopes identifying the user, the only ones
+// requested in identity-only mode.
+var identityScopes = []string{"openid", "email"}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
+// googleScopes returns the Google scopes to request for MCP scopes: the
+// server scope set for write access, read-only contact scopes otherwise,
+// with the identity scopes binding the grant to the user.
+func (s *OAuth2Server) googleScopes(scopes []string) []string {
+	if s.identityOnly {
+		return identityScopes
+	}
+	set := auth.ScopeSetContactsReadonly
+	if slices.Contains(scopes, ScopeContactsWrite) {
+		set = s.scopeSet
+	}
+	googleScopes, _ := auth.ScopesFor(set)
+	return append(googleScopes, identityScopes...)
 }
 
-// ValidateAccessToken validates a Bearer token and returns the Google OAuth config for API calls.
-// This is used by the auth middleware.
-func (s *OAuth2Server) ValidateAccessToken(ctx context.Context, accessToken string) (*oauth2.Config, *oauth2.Token, error) {
-	config, err := s.getOAuthConfig(ctx)
+// googleIssuers are the issuers of Google ID tokens.
+var googleIssuers = []string{"https://accounts.google.com", "accounts.google.com"}
+
+// idTokenClaims are the claims of a Google ID token identifying the user.
+type idTokenClaims struct {
+	Issuer        string `json:"iss"`
+	Audience      string `json:"aud"`
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+// parseIDToken reads the ID token of a Google token response. Its signature
+// is not checked: it was received directly from the Google token endpoint
+// over TLS (OpenID Connect Core, section 3.1.3.7).
+func parseIDToken(token *oauth2.Token, clientID string) (*idTokenClaims, error) {
+	raw, _ := token.Extra("id_token").(string)
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("no ID token in the Google response")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to load OAuth config: %w", err)
+		return nil, fmt.Errorf("invalid ID token: %w", err)
 	}
+	var claims idTokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+	if !slices.Contains(googleIssuers, claims.Issuer) || claims.Audience != clientID {
+		return nil, fmt.Errorf("ID token issued by %s to %s", claims.Issuer, claims.Audience)
+	}
+	if claims.Email == "" {
+		return nil, fmt.Errorf("ID token without email")
+	}
+	return &claims, nil
+}
 
-	// Create a token with the access token
-	// Note: We don't have the expiry, so the token source will refresh if needed
-	token := &oauth2.Token{
-		AccessToken: accessToken,
+// grantedScopes returns the MCP scopes of a Google token. In identity-only
+// mode the token grants no contact scope, the requested ones (fallback) are
+// returned.
+func (s *OAuth2Server) grantedScopes(token *oauth2.Token, fallback []string) []string {
+	if s.identityOnly {
+		return fallback
 	}
+	return grantedScopes(token, fallback)
+}
 
-	return config, token, nil
+// grantedScopes returns the MCP scopes of a Google token from the scopes it
+// was granted, or fallback when Google does not report them.
+func grantedScopes(token *oauth2.Token, fallback []string) []string {
+	scope, _ := token.Extra("scope").(string)
+	granted := strings.Fields(scope)
+	if len(granted) == 0 {
+		return fallback
+	}
+	var scopes []string
+	if slices.Contains(granted, people.ContactsScope) || slices.Contains(granted, people.ContactsReadonlyScope) {
+		scopes = append(scopes, ScopeContactsRead)
+	}
+	if slices.Contains(granted, people.ContactsScope) {
+		scopes = append(scopes, ScopeContactsWrite)
+	}
+	return scopes
 }
 
 // Helper functions
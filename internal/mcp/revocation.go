@@ -0,0 +1,222 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// googleRevokeURL is the Google OAuth token revocation endpoint.
+const googleRevokeURL = "https://oauth2.googleapis.com/revoke"
+
+// clientAuthMethods are the client authentication methods of the
+// introspection and revocation endpoints.
+var clientAuthMethods = []string{"none", "client_secret_basic", "client_secret_post"}
+
+// IntrospectionResponse represents an RFC 7662 token introspection response.
+type IntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Aud       string `json:"aud,omitempty"`
+	Iss       string `json:"iss,omitempty"`
+}
+
+// HandleIntrospect implements RFC 7662 token introspection. A client only
+// sees its own tokens: tokens of other clients are reported inactive, like
+// unknown, expired and revoked ones.
+// POST /oauth/introspect (token=xxx&token_type_hint=access_token)
+func (s *OAuth2Server) HandleIntrospect(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	clientID, token, ok := s.parseTokenRequest(w, r)
+	if !ok {
+		return
+	}
+
+	resp := IntrospectionResponse{}
+	grant, entry, kind, err := s.vault.find(ctx, token, r.FormValue("token_type_hint"))
+	switch {
+	case errors.Is(err, errInvalidToken):
+	case err != nil:
+		log.Printf("Failed to introspect token: %v", err)
+		writeOAuthError(w, "server_error", "Failed to introspect token", http.StatusInternalServerError)
+		return
+	case grant.ClientID == clientID && grant.Audience == s.baseURL:
+		resp = IntrospectionResponse{
+			Active:   true,
+			Scope:    strings.Join(grant.Scopes, " "),
+			ClientID: grant.ClientID,
+			Username: grant.Subject,
+			Exp:      entry.Expiry.Unix(),
+			Sub:      grant.Subject,
+			Aud:      grant.Audience,
+			Iss:      s.baseURL,
+		}
+		if kind == kindAccessTokens {
+			resp.TokenType = "Bearer"
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// HandleRevoke implements RFC 7009 token revocation. Revoking an access or
+// refresh token revokes its whole grant, and the Google token of the grant:
+// the assistant has to be authorized again. Unknown tokens, and tokens issued
+// to another client, are not an error and are left untouched.
+// POST /oauth/revoke (token=xxx&token_type_hint=refresh_token)
+func (s *OAuth2Server) HandleRevoke(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	clientID, token, ok := s.parseTokenRequest(w, r)
+	if !ok {
+		return
+	}
+
+	grant, _, _, err := s.vault.find(ctx, token, r.FormValue("token_type_hint"))
+	switch {
+	case errors.Is(err, errInvalidToken), err == nil && grant.ClientID != clientID:
+		// Tokens of other clients are treated as unknown: nothing is revoked
+		// and nothing tells that the token exists
+		w.WriteHeader(http.StatusOK)
+		return
+	case err != nil:
+		log.Printf("Failed to look up token to revoke: %v", err)
+		writeOAuthError(w, "server_error", "Failed to revoke token", http.StatusInternalServerError)
+		return
+	}
+
+	if err := s.vault.revokeGrant(ctx, grant.ID); err != nil {
+		log.Printf("Failed to revoke grant: %v", err)
+		writeOAuthError(w, "server_error", "Failed to revoke token", http.StatusInternalServerError)
+		return
+	}
+	// Our tokens are already unusable: a Google failure is only logged
+	if err := s.revokeGoogleToken(ctx, grant.GoogleToken); err != nil {
+		log.Printf("Failed to revoke Google token of client %s: %v", clientID, err)
+	}
+
+	log.Printf("Tokens revoked for client: %s", clientID)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+}
+
+// parseTokenRequest reads an introspection or revocation request: the
+// authenticated client and the token. It writes the error response and
+// returns false on failure.
+func (s *OAuth2Server) parseTokenRequest(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return "", "", false
+	}
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, "invalid_request", "Invalid form data", http.StatusBadRequest)
+		return "", "", false
+	}
+
+	clientID, ok := s.requireClient(w, r)
+	if !ok {
+		return "", "", false
+	}
+
+	token := r.FormValue("token")
+	if token == "" {
+		writeOAuthError(w, "invalid_request", "token is required", http.StatusBadRequest)
+		return "", "", false
+	}
+	return clientID, token, true
+}
+
+// requireClient returns the authenticated client of a token, introspection
+// or revocation request. It writes the error response and returns false on
+// failure.
+func (s *OAuth2Server) requireClient(w http.ResponseWriter, r *http.Request) (string, bool) {
+	clientID, err := s.authenticateClient(r)
+	if err != nil {
+		if !errors.Is(err, errClientAuth) {
+			log.Printf("Failed to load OAuth client: %v", err)
+			writeOAuthError(w, "server_error", "Failed to load client", http.StatusInternalServerError)
+			return "", false
+		}
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+		writeOAuthError(w, "invalid_client", err.Error(), http.StatusUnauthorized)
+		return "", false
+	}
+	return clientID, true
+}
+
+// errClientAuth is returned for missing or wrong client credentials.
+var errClientAuth = errors.New("client authentication failed")
+
+// authenticateClient returns the client of a request (client_secret_basic,
+// client_secret_post or none). The client must be registered: clients
+// registered with a secret must present it; public clients, auto-registered
+// by /oauth/authorize, are identified by their client_id.
+func (s *OAuth2Server) authenticateClient(r *http.Request) (string, error) {
+	clientID, secret, ok := r.BasicAuth()
+	if !ok {
+		clientID, secret = r.FormValue("client_id"), r.FormValue("client_secret")
+	}
+	if clientID == "" {
+		return "", fmt.Errorf("%w: client_id is required", errClientAuth)
+	}
+
+	var client registeredClient
+	err := s.store.Get(r.Context(), kindClients, clientID, &client)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return "", fmt.Errorf("%w: unknown client", errClientAuth)
+	case err != nil:
+		return "", err
+	case client.secretHash() != "" && subtle.ConstantTimeCompare([]byte(tokenHash(secret)), []byte(client.secretHash())) != 1:
+		return "", fmt.Errorf("%w: invalid client credentials", errClientAuth)
+	}
+	return clientID, nil
+}
+
+// revokeGoogleToken revokes a Google token at Google: revoking the refresh
+// token also revokes its access tokens. It ends the authorization of the
+// Google account for the server OAuth client, so other grants of the same
+// account are dropped on their next Google refresh.
+func (s *OAuth2Server) revokeGoogleToken(ctx context.Context, token *oauth2.Token) error {
+	if token == nil {
+		return nil
+	}
+	value := token.RefreshToken
+	if value == "" {
+		value = token.AccessToken
+	}
+	if value == "" {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.revokeURL, strings.NewReader(url.Values{"token": {value}}.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Google revocation failed: %s", resp.Status)
+	}
+	return nil
+}
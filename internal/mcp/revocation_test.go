@@ -0,0 +1,218 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// postForm calls an OAuth2 server handler with a form, and optional basic
+// auth credentials.
+func postForm(handler http.HandlerFunc, form url.Values, basicUser, basicPassword string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if basicUser != "" {
+		req.SetBasicAuth(basicUser, basicPassword)
+	}
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec
+}
+
+// registerClients registers public clients, as /oauth/authorize does.
+func registerClients(t *testing.T, s *OAuth2Server, clientIDs ...string) {
+	t.Helper()
+	for _, id := range clientIDs {
+		client := registeredClient{ClientID: id, CreatedAt: time.Now()}
+		if err := s.store.Put(context.Background(), kindClients, id, client, time.Now().Add(clientTTL)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// introspect returns the introspection of token by clientID.
+func introspect(t *testing.T, s *OAuth2Server, token, clientID string) IntrospectionResponse {
+	t.Helper()
+	rec := postForm(s.HandleIntrospect, url.Values{"token": {token}, "client_id": {clientID}}, "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("introspect status = %d: %s", rec.Code, rec.Body)
+	}
+	var resp IntrospectionResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	return resp
+}
+
+func TestHandleIntrospect(t *testing.T) {
+	s, code := tokenTestServer(t, http.StatusOK, `{}`)
+	registerClients(t, s, "client", "other-client")
+	_, tokens := postToken(t, s, url.Values{"grant_type": {"authorization_code"}, "code": {code}, "client_id": {"client"}})
+
+	got := introspect(t, s, tokens.AccessToken, "client")
+	if !got.Active || got.ClientID != "client" || got.Username != "jane@example.com" || got.Scope != ScopeContactsRead ||
+		got.TokenType != "Bearer" || got.Aud != "https://mcp.example.com" || got.Exp < time.Now().Unix() {
+		t.Errorf("introspect(access token) = %+v", got)
+	}
+	if got := introspect(t, s, tokens.RefreshToken, "client"); !got.Active || got.TokenType != "" {
+		t.Errorf("introspect(refresh token) = %+v", got)
+	}
+
+	// Tokens of other clients look like unknown ones
+	for name, got := range map[string]IntrospectionResponse{
+		"other client": introspect(t, s, tokens.AccessToken, "other-client"),
+		"unknown":      introspect(t, s, "forged", "client"),
+	} {
+		if got != (IntrospectionResponse{}) {
+			t.Errorf("introspect(%s) = %+v, want inactive only", name, got)
+		}
+	}
+
+	if rec := postForm(s.HandleIntrospect, url.Values{"token": {tokens.AccessToken}}, "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("introspect without client status = %d, want 401", rec.Code)
+	}
+	if rec := postForm(s.HandleIntrospect, url.Values{"token": {tokens.AccessToken}, "client_id": {"unknown"}}, "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("introspect by unknown client status = %d, want 401", rec.Code)
+	}
+}
+
+func TestHandleIntrospect_ConfidentialClient(t *testing.T) {
+	s, code := tokenTestServer(t, http.StatusOK, `{}`)
+	_, tokens := postToken(t, s, url.Values{"grant_type": {"authorization_code"}, "code": {code}, "client_id": {"client"}})
+	client := registeredClient{ClientID: "client", SecretHash: tokenHash("s3cret")}
+	if err := s.store.Put(context.Background(), kindClients, "client", client, time.Now().Add(clientTTL)); err != nil {
+		t.Fatal(err)
+	}
+
+	form := url.Values{"token": {tokens.AccessToken}}
+	if rec := postForm(s.HandleIntrospect, url.Values{"token": {tokens.AccessToken}, "client_id": {"client"}}, "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("introspect without secret status = %d, want 401", rec.Code)
+	}
+	if rec := postForm(s.HandleIntrospect, form, "client", "wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("introspect with wrong secret status = %d, want 401", rec.Code)
+	}
+	if rec := postForm(s.HandleIntrospect, form, "client", "s3cret"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"active":true`) {
+		t.Errorf("introspect with secret = %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestHandleRevoke(t *testing.T) {
+	s, code := tokenTestServer(t, http.StatusOK, `{"access_token":"google-fresh","token_type":"Bearer","expires_in":3600}`)
+	var revoked []string
+	google := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		revoked = append(revoked, r.FormValue("token"))
+	}))
+	defer google.Close()
+	s.revokeURL = google.URL
+	registerClients(t, s, "client", "other-client")
+	_, tokens := postToken(t, s, url.Values{"grant_type": {"authorization_code"}, "code": {code}, "client_id": {"client"}})
+
+	// Only the client of a token can revoke it: for others it is unknown
+	form := url.Values{"token": {tokens.RefreshToken}, "token_type_hint": {"refresh_token"}}
+	if rec := postForm(s.HandleRevoke, form, "other-client", ""); rec.Code != http.StatusOK {
+		t.Errorf("revoke by other client status = %d, want 200", rec.Code)
+	}
+	if len(revoked) != 0 {
+		t.Fatalf("Google token revoked for another client: %v", revoked)
+	}
+	if got := introspect(t, s, tokens.AccessToken, "client"); !got.Active {
+		t.Fatal("token revoked by another client")
+	}
+	if rec := postForm(s.HandleRevoke, form, "unknown", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("revoke by unknown client status = %d, want 401", rec.Code)
+	}
+
+	// Revoking the refresh token revokes the grant and the Google token
+	if rec := postForm(s.HandleRevoke, form, "client", ""); rec.Code != http.StatusOK {
+		t.Fatalf("revoke status = %d: %s", rec.Code, rec.Body)
+	}
+	if len(revoked) != 1 || revoked[0] != "google-refresh" {
+		t.Errorf("Google revocations = %v, want [google-refresh]", revoked)
+	}
+	if _, err := s.ValidateAccessToken(context.Background(), tokens.AccessToken); !errors.Is(err, errInvalidToken) {
+		t.Errorf("ValidateAccessToken() after revoke error = %v, want errInvalidToken", err)
+	}
+	if status, _ := postToken(t, s, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {tokens.RefreshToken}, "client_id": {"client"}}); status != http.StatusBadRequest {
+		t.Errorf("refresh after revoke status = %d, want 400", status)
+	}
+	if got := introspect(t, s, tokens.AccessToken, "client"); got.Active {
+		t.Error("access token still active after revoke")
+	}
+
+	// Revoking an unknown or revoked token succeeds
+	if rec := postForm(s.HandleRevoke, url.Values{"token": {tokens.AccessToken}, "client_id": {"client"}}, "", ""); rec.Code != http.StatusOK {
+		t.Errorf("second revoke status = %d, want 200", rec.Code)
+	}
+}
+
+func TestHandleRevoke_Validation(t *testing.T) {
+	s := NewOAuth2Server(&OAuth2ServerConfig{BaseURL: "https://mcp.example.com"})
+	registerClients(t, s, "client")
+	tests := []struct {
+		name   string
+		method string
+		form   url.Values
+		want   int
+	}{
+		{"get", http.MethodGet, url.Values{"token": {"t"}, "client_id": {"client"}}, http.StatusMethodNotAllowed},
+		{"no client", http.MethodPost, url.Values{"token": {"t"}}, http.StatusUnauthorized},
+		{"no token", http.MethodPost, url.Values{"client_id": {"client"}}, http.StatusBadRequest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/oauth/revoke", strings.NewReader(tc.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rec := httptest.NewRecorder()
+			s.HandleRevoke(rec, req)
+			if rec.Code != tc.want {
+				t.Errorf("status = %d, want %d", rec.Code, tc.want)
+			}
+		})
+	}
+}
+
+func TestClientSecretHashed(t *testing.T) {
+	s := NewOAuth2Server(&OAuth2ServerConfig{BaseURL: "https://mcp.example.com"})
+	req := httptest.NewRequest(http.MethodPost, "/oauth/register", strings.NewReader(`{"redirect_uris":["https://app.example.com/cb"]}`))
+	rec := httptest.NewRecorder()
+	s.HandleClientRegistration(rec, req)
+	var registered ClientRegistrationResponse
+	if err := json.NewDecoder(rec.Body).Decode(&registered); err != nil || registered.ClientSecret == "" {
+		t.Fatalf("registration = %d: %+v, %v", rec.Code, registered, err)
+	}
+
+	// The store only has the hash of the secret
+	var stored map[string]any
+	if err := s.store.Get(context.Background(), kindClients, registered.ClientID, &stored); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := json.Marshal(stored); strings.Contains(string(data), registered.ClientSecret) {
+		t.Errorf("client secret stored in plaintext: %s", data)
+	}
+
+	form := url.Values{"token": {"unknown"}}
+	if rec := postForm(s.HandleIntrospect, form, registered.ClientID, registered.ClientSecret); rec.Code != http.StatusOK {
+		t.Errorf("introspect with secret status = %d, want 200", rec.Code)
+	}
+	if rec := postForm(s.HandleIntrospect, form, registered.ClientID, tokenHash(registered.ClientSecret)); rec.Code != http.StatusUnauthorized {
+		t.Errorf("introspect with the stored hash status = %d, want 401", rec.Code)
+	}
+
+	// Clients stored with a plaintext secret keep authenticating with it
+	legacy := registeredClient{ClientID: "legacy", LegacySecret: "s3cret"}
+	if err := s.store.Put(context.Background(), kindClients, "legacy", legacy, time.Now().Add(clientTTL)); err != nil {
+		t.Fatal(err)
+	}
+	if rec := postForm(s.HandleIntrospect, form, "legacy", "s3cret"); rec.Code != http.StatusOK {
+		t.Errorf("introspect with legacy secret status = %d, want 200", rec.Code)
+	}
+	if rec := postForm(s.HandleIntrospect, form, "legacy", "wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("introspect with wrong legacy secret status = %d, want 401", rec.Code)
+	}
+}
@@ -4,11 +4,14 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
@@ -16,6 +19,8 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"google-contacts/internal/contacts"
+	"google-contacts/internal/journal"
+	"google-contacts/internal/trash"
 	"google-contacts/pkg/auth"
 )
 
@@ -27,6 +32,43 @@ type Config struct {
 	SecretName     string // Secret Manager secret name for OAuth credentials
 	SecretProject  string // GCP project for Secret Manager
 	CredentialFile string // Local credential file path (fallback)
+
+	// Calendar subscription feed (served with the local CLI credentials)
+	CalendarFeed      bool   // Enable the /calendar/<token>.ics feed
+	CalendarToken     string // Secret token in the feed URL (required)
+	CalendarAlarmDays []int  // Reminders in days before each event
+
+	// LastNameCase is the casing applied to last names (contacts.LastName*,
+	// defaults to upper)
+	LastNameCase string
+
+	// ScopeSet is the widest Google scope set granted to MCP clients
+	// (auth.ScopeSet*, defaults to contacts)
+	ScopeSet string
+
+	// ServiceAccountFile enables the service-account mode: each caller only
+	// proves its identity, and its contacts are accessed by the service
+	// account acting as the caller (domain-wide delegation).
+	ServiceAccountFile string
+	// ImpersonateDomain restricts the service-account mode to the callers of
+	// a Workspace domain (empty allows any verified caller)
+	ImpersonateDomain string
+
+	// OAuthStore is where the OAuth clients, codes and tokens are kept
+	// (Store*; memory when empty, the mcp command defaults to file): file
+	// keeps them in OAuthStorePath, firestore in FirestoreDatabase of
+	// FirestoreProject. The Google tokens are encrypted with the key of
+	// OAuthKeyFile (see OpenTokenCipher).
+	OAuthStore        string
+	OAuthStorePath    string
+	OAuthKeyFile      string
+	FirestoreProject  string
+	FirestoreDatabase string
+
+	// Journal records create, update and delete operations (nil disables it)
+	Journal *journal.Journal
+	// Trash archives deleted contacts for contacts_restore (nil disables it)
+	Trash *trash.Store
 }
 
 // Server wraps the MCP server and HTTP server.
@@ -35,6 +77,9 @@ type Server struct {
 	mcpServer    *mcp.Server
 	httpServer   *http.Server
 	oauth2Server *OAuth2Server
+
+	// Service-account mode
+	serviceAccount *auth.ServiceAccount
 }
 
 // NewServer creates a new MCP server with the given configuration.
@@ -94,7 +139,7 @@ func (s *Server) authMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		oauthConfig, token, err := s.oauth2Server.ValidateAccessToken(ctx, accessToken)
+		validated, err := s.oauth2Server.ValidateAccessToken(ctx, accessToken)
 		if err != nil {
 			log.Printf("Token validation error: %v", err)
 			w.Header().Set("WWW-Authenticate", fmt.Sprintf(
@@ -105,15 +150,52 @@ func (s *Server) authMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		// Service-account mode: act as the caller the token identifies
+		if s.serviceAccount != nil {
+			subject, err := s.callerSubject(validated)
+			if err != nil {
+				log.Printf("Caller rejected: %v", err)
+				http.Error(w, "Forbidden: account not allowed on this server", http.StatusForbidden)
+				return
+			}
+			ctx = withGrantedScopes(ctx, validated.Scopes)
+			ctx = auth.WithServiceAccount(ctx, s.callerServiceAccount(validated.Scopes), subject)
+			r = r.WithContext(ctx)
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		// Inject OAuth config and token into context for People API
-		ctx = auth.WithOAuthConfig(ctx, oauthConfig)
-		ctx = auth.WithAccessToken(ctx, token)
+		ctx = withGrantedScopes(ctx, validated.Scopes)
+		ctx = auth.WithOAuthConfig(ctx, validated.Config)
+		ctx = auth.WithAccessToken(ctx, validated.GoogleToken)
 
 		r = r.WithContext(ctx)
 		next.ServeHTTP(w, r)
 	})
 }
 
+// grantedScopesKey is the context key of the MCP scopes granted to the
+// caller.
+type grantedScopesKey struct{}
+
+// withGrantedScopes returns a context carrying the MCP scopes of the caller
+// token.
+func withGrantedScopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, grantedScopesKey{}, scopes)
+}
+
+// requireWriteScope fails unless the caller token was granted contacts:write.
+// Requests that did not go through authMiddleware carry no scopes and are
+// rejected too.
+func requireWriteScope(ctx context.Context) error {
+	scopes, _ := ctx.Value(grantedScopesKey{}).([]string)
+	if !slices.Contains(scopes, ScopeContactsWrite) {
+		return &auth.ScopeError{}
+	}
+	return nil
+}
+
 // isInternationalPhoneFormat validates that a phone number starts with '+'.
 func isInternationalPhoneFormat(phone string) bool {
 	return strings.HasPrefix(phone, "+")
@@ -129,6 +211,16 @@ func validatePhones(phones []PhoneInput) error {
 	return nil
 }
 
+// validateEmails checks that all email addresses are syntactically valid.
+func validateEmails(emails []EmailInput) error {
+	for _, email := range emails {
+		if err := contacts.ValidateEmail(email.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // PhoneInput represents a phone number with type for MCP tools.
 type PhoneInput struct {
 	Value string `json:"value" jsonschema:"Phone number in international format starting with + (e.g. +33612345678)"`
@@ -137,7 +229,7 @@ type PhoneInput struct {
 
 // EmailInput represents an email address with type for MCP tools.
 type EmailInput struct {
-	Value string `json:"value" jsonschema:"Email address"`
+	Value string `json:"value" jsonschema:"Email address (e.g. john.doe@example.com, IDN domains allowed)"`
 	Type  string `json:"type,omitempty" jsonschema:"Email type: work home other. Default: work"`
 }
 
@@ -150,7 +242,7 @@ type AddressInput struct {
 // CreateInput is the input schema for contacts_create tool.
 type CreateInput struct {
 	FirstName string         `json:"firstName" jsonschema:"First name of the contact"`
-	LastName  string         `json:"lastName" jsonschema:"Last name of the contact (will be stored in UPPERCASE)"`
+	LastName  string         `json:"lastName" jsonschema:"Last name of the contact (stored in UPPERCASE unless configured otherwise)"`
 	Phones    []PhoneInput   `json:"phones" jsonschema:"Phone numbers with optional types (required)"`
 	Emails    []EmailInput   `json:"emails,omitempty" jsonschema:"Email addresses with optional types"`
 	Addresses []AddressInput `json:"addresses,omitempty" jsonschema:"Postal addresses with optional types"`
@@ -169,17 +261,21 @@ type CreateOutput struct {
 
 // SearchInput is the input schema for contacts_search tool.
 type SearchInput struct {
-	Query string `json:"query" jsonschema:"Search query (matches name phone email company)"`
+	Query  string `json:"query,omitempty" jsonschema:"Search query (matches name phone email company). Required unless filter is set"`
+	Mode   string `json:"mode,omitempty" jsonschema:"Search mode: prefix (Google search, default) or fuzzy (accent/typo/phonetic tolerant, ranked)"`
+	Filter string `json:"filter,omitempty" jsonschema:"Structured filter (e.g. company:acme has:phone -has:email birthday:10 city:Paris updated:>2025-01-01). Fields: name first last company position email phone notes address city postal country has birthday updated. Terms are ANDed, OR separates alternatives, - negates"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"Maximum number of results in fuzzy or filter mode. Default: 20"`
 }
 
 // SearchResultItem represents a single search result for MCP output.
 type SearchResultItem struct {
-	ResourceName string `json:"resourceName" jsonschema:"Google Contact ID"`
-	DisplayName  string `json:"displayName" jsonschema:"Full display name"`
-	Phone        string `json:"phone,omitempty" jsonschema:"Primary phone number"`
-	Email        string `json:"email,omitempty" jsonschema:"Primary email address"`
-	Company      string `json:"company,omitempty" jsonschema:"Company name"`
-	Position     string `json:"position,omitempty" jsonschema:"Job title"`
+	ResourceName string  `json:"resourceName" jsonschema:"Google Contact ID"`
+	DisplayName  string  `json:"displayName" jsonschema:"Full display name"`
+	Phone        string  `json:"phone,omitempty" jsonschema:"Primary phone number"`
+	Email        string  `json:"email,omitempty" jsonschema:"Primary email address"`
+	Company      string  `json:"company,omitempty" jsonschema:"Company name"`
+	Position     string  `json:"position,omitempty" jsonschema:"Job title"`
+	Score        float64 `json:"score,omitempty" jsonschema:"Relevance score from 0 to 1 (fuzzy mode only)"`
 }
 
 // SearchOutput is the output schema for contacts_search tool.
@@ -190,7 +286,7 @@ type SearchOutput struct {
 
 // ShowInput is the input schema for contacts_show tool.
 type ShowInput struct {
-	ContactID string `json:"contactId" jsonschema:"Contact ID (e.g. c123456789 or people/c123456789)"`
+	ContactID string `json:"contactId" jsonschema:"Contact ID (e.g. c123456789 or people/c123456789), or an exact name, email or phone number"`
 }
 
 // PhoneOutput represents a phone number in contact details output.
@@ -201,7 +297,7 @@ type PhoneOutput struct {
 
 // EmailOutput represents an email address in contact details output.
 type EmailOutput struct {
-	Value string `json:"value" jsonschema:"Email address"`
+	Value string `json:"value" jsonschema:"Email address (e.g. john.doe@example.com, IDN domains allowed)"`
 	Type  string `json:"type" jsonschema:"Email type (work home etc)"`
 }
 
@@ -229,15 +325,15 @@ type ShowOutput struct {
 
 // UpdateInput is the input schema for contacts_update tool.
 type UpdateInput struct {
-	ContactID       string         `json:"contactId" jsonschema:"Contact ID to update"`
+	ContactID       string         `json:"contactId" jsonschema:"Contact ID, or an exact name, email or phone number, of the contact to update"`
 	FirstName       string         `json:"firstName,omitempty" jsonschema:"New first name"`
-	LastName        string         `json:"lastName,omitempty" jsonschema:"New last name (will be stored in UPPERCASE)"`
+	LastName        string         `json:"lastName,omitempty" jsonschema:"New last name (stored in UPPERCASE unless configured otherwise)"`
 	Phones          []PhoneInput   `json:"phones,omitempty" jsonschema:"Replace ALL phones with these"`
 	AddPhones       []PhoneInput   `json:"addPhones,omitempty" jsonschema:"Add phones without removing existing"`
 	RemovePhones    []string       `json:"removePhones,omitempty" jsonschema:"Remove phones by value"`
 	Emails          []EmailInput   `json:"emails,omitempty" jsonschema:"Replace ALL emails with these"`
 	AddEmails       []EmailInput   `json:"addEmails,omitempty" jsonschema:"Add emails without removing existing"`
-	RemoveEmails    []string       `json:"removeEmails,omitempty" jsonschema:"Remove emails by value"`
+	RemoveEmails    []string       `json:"removeEmails,omitempty" jsonschema:"Remove emails by exact value"`
 	Addresses       []AddressInput `json:"addresses,omitempty" jsonschema:"Replace ALL addresses with these"`
 	AddAddresses    []AddressInput `json:"addAddresses,omitempty" jsonschema:"Add addresses without removing existing"`
 	RemoveAddresses []string       `json:"removeAddresses,omitempty" jsonschema:"Remove addresses by street content"`
@@ -256,7 +352,7 @@ type UpdateOutput struct {
 
 // DeleteInput is the input schema for contacts_delete tool.
 type DeleteInput struct {
-	ContactID string `json:"contactId" jsonschema:"Contact ID to delete"`
+	ContactID string `json:"contactId" jsonschema:"Contact ID, or an exact name, email or phone number, of the contact to delete"`
 }
 
 // DeleteOutput is the output schema for contacts_delete tool.
@@ -264,6 +360,159 @@ type DeleteOutput struct {
 	Message     string `json:"message" jsonschema:"Success message"`
 	DeletedID   string `json:"deletedId" jsonschema:"ID of deleted contact"`
 	DisplayName string `json:"displayName,omitempty" jsonschema:"Name of deleted contact"`
+	TrashID     string `json:"trashId,omitempty" jsonschema:"Trash ID to pass to contacts_restore to undo the deletion"`
+}
+
+// RestoreInput is the input schema for contacts_restore tool.
+type RestoreInput struct {
+	ID string `json:"id" jsonschema:"Trash ID returned by contacts_delete, or the ID of the deleted contact"`
+}
+
+// RestoreOutput is the output schema for contacts_restore tool.
+type RestoreOutput struct {
+	ResourceName string   `json:"resourceName" jsonschema:"New Google Contact ID of the restored contact"`
+	DisplayName  string   `json:"displayName" jsonschema:"Name of the restored contact"`
+	Message      string   `json:"message" jsonschema:"Success message"`
+	Warnings     []string `json:"warnings" jsonschema:"Parts of the contact that could not be restored (groups, photo)"`
+}
+
+// NewCreateOutput converts a created contact to the contacts_create output schema.
+func NewCreateOutput(created *contacts.CreatedContact) CreateOutput {
+	return CreateOutput{
+		ResourceName: created.ResourceName,
+		DisplayName:  created.DisplayName,
+		Message:      fmt.Sprintf("Contact '%s' created successfully", created.DisplayName),
+	}
+}
+
+// NewDeleteOutput converts the details of a deleted contact to the contacts_delete output schema.
+func NewDeleteOutput(details *contacts.ContactDetails) DeleteOutput {
+	return DeleteOutput{
+		Message:     fmt.Sprintf("Contact '%s' deleted successfully", details.DisplayName),
+		DeletedID:   details.ResourceName,
+		DisplayName: details.DisplayName,
+	}
+}
+
+// NewUpdateOutput converts updated contact details to the contacts_update output schema.
+func NewUpdateOutput(details *contacts.ContactDetails) UpdateOutput {
+	return UpdateOutput{
+		ShowOutput: NewShowOutput(details),
+		Message:    fmt.Sprintf("Contact '%s' updated successfully", details.DisplayName),
+	}
+}
+
+// NewSearchOutput converts search results to the contacts_search output schema.
+func NewSearchOutput(results []contacts.SearchResult) SearchOutput {
+	// Always initialize Results to empty slice to avoid null in JSON
+	output := SearchOutput{
+		Count:   len(results),
+		Results: []SearchResultItem{},
+	}
+	for _, r := range results {
+		output.Results = append(output.Results, SearchResultItem{
+			ResourceName: r.ResourceName,
+			DisplayName:  r.DisplayName,
+			Phone:        r.Phone,
+			Email:        r.Email,
+			Company:      r.Company,
+			Position:     r.Position,
+		})
+	}
+	return output
+}
+
+// NewShowOutput converts contact details to the contacts_show output schema.
+func NewShowOutput(details *contacts.ContactDetails) ShowOutput {
+	// Always initialize slices to empty to avoid null in JSON
+	output := ShowOutput{
+		ResourceName: details.ResourceName,
+		FirstName:    details.FirstName,
+		LastName:     details.LastName,
+		DisplayName:  details.DisplayName,
+		Company:      details.Company,
+		Position:     details.Position,
+		Notes:        details.Notes,
+		Birthday:     details.Birthday,
+		UpdatedAt:    details.UpdatedAt,
+		Phones:       []PhoneOutput{},
+		Emails:       []EmailOutput{},
+		Addresses:    []AddressOutput{},
+	}
+
+	// Convert phones
+	for _, phone := range details.Phones {
+		output.Phones = append(output.Phones, PhoneOutput{
+			Value: phone.Value,
+			Type:  phone.Type,
+		})
+	}
+
+	// Convert emails
+	for _, email := range details.Emails {
+		output.Emails = append(output.Emails, EmailOutput{
+			Value: email.Value,
+			Type:  email.Type,
+		})
+	}
+
+	// Convert addresses
+	for _, addr := range details.Addresses {
+		output.Addresses = append(output.Addresses, AddressOutput{
+			Value: addr.Value,
+			Type:  addr.Type,
+		})
+	}
+
+	return output
+}
+
+// AuditInput is the input schema for contacts_audit tool.
+type AuditInput struct {
+	Rules []string `json:"rules,omitempty" jsonschema:"Rules to run (default: all): phone-not-e164 email-malformed duplicate-email address-incomplete missing-name empty-contact shared-phone suspicious-birthday"`
+}
+
+// AuditFindingItem represents a single data-quality finding for MCP output.
+type AuditFindingItem struct {
+	Rule         string `json:"rule" jsonschema:"Rule that produced the finding"`
+	ResourceName string `json:"resourceName" jsonschema:"Google Contact ID"`
+	DisplayName  string `json:"displayName" jsonschema:"Full display name"`
+	Field        string `json:"field" jsonschema:"Affected field (phones emails addresses names birthday contact)"`
+	Value        string `json:"value,omitempty" jsonschema:"Offending value"`
+	Message      string `json:"message" jsonschema:"Description of the problem"`
+	Suggestion   string `json:"suggestion" jsonschema:"Suggested fix"`
+}
+
+// AuditOutput is the output schema for contacts_audit tool.
+type AuditOutput struct {
+	Findings []AuditFindingItem `json:"findings" jsonschema:"Findings sorted by rule then contact name"`
+	ByRule   map[string]int     `json:"byRule" jsonschema:"Number of findings per rule"`
+	Count    int                `json:"count" jsonschema:"Total number of findings"`
+	Scanned  int                `json:"scanned" jsonschema:"Number of contacts audited"`
+}
+
+// NewAuditOutput converts audit findings to the contacts_audit output schema.
+func NewAuditOutput(findings []contacts.AuditFinding, scanned int) AuditOutput {
+	// Always initialize collections to avoid null in JSON
+	output := AuditOutput{
+		Findings: []AuditFindingItem{},
+		ByRule:   map[string]int{},
+		Count:    len(findings),
+		Scanned:  scanned,
+	}
+	for _, f := range findings {
+		output.Findings = append(output.Findings, AuditFindingItem{
+			Rule:         string(f.Rule),
+			ResourceName: f.ResourceName,
+			DisplayName:  f.DisplayName,
+			Field:        f.Field,
+			Value:        f.Value,
+			Message:      f.Message,
+			Suggestion:   f.Suggestion,
+		})
+		output.ByRule[string(f.Rule)]++
+	}
+	return output
 }
 
 // RegisterTools registers all contact management tools with the MCP server.
@@ -298,13 +547,13 @@ func (s *Server) RegisterTools() {
 	// Register contacts_search tool
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
 		Name:        "contacts_search",
-		Description: "Search contacts by name, phone, email, or company",
+		Description: "Search contacts by name, phone, email, or company, with optional fuzzy mode and structured filter",
 	}, s.handleSearchContacts)
 
 	// Register contacts_show tool
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
 		Name:        "contacts_show",
-		Description: "Get full details of a contact by ID",
+		Description: "Get full details of a contact by ID, name, email or phone (fails with the candidates when several contacts match)",
 	}, s.handleShowContact)
 
 	// Register contacts_update tool
@@ -316,8 +565,20 @@ func (s *Server) RegisterTools() {
 	// Register contacts_delete tool
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
 		Name:        "contacts_delete",
-		Description: "Delete a contact by ID",
+		Description: "Delete a contact by ID, name, email or phone (fails with the candidates when several contacts match)",
 	}, s.handleDeleteContact)
+
+	// Register contacts_restore tool
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "contacts_restore",
+		Description: "Restore a deleted contact from the trash, with all its fields, groups and photo (it gets a new ID)",
+	}, s.handleRestoreContact)
+
+	// Register contacts_audit tool
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "contacts_audit",
+		Description: "Audit all contacts for data-quality problems (bad phones/emails/addresses, missing names, duplicates, suspicious birthdays) with suggested fixes",
+	}, s.handleAuditContacts)
 }
 
 // handleCreateContact implements the contacts_create MCP tool.
@@ -342,8 +603,18 @@ func (s *Server) handleCreateContact(ctx context.Context, req *mcp.CallToolReque
 		return nil, CreateOutput{}, err
 	}
 
-	// Convert last name to uppercase
-	input.LastName = strings.ToUpper(input.LastName)
+	// Validate email addresses
+	if err := validateEmails(input.Emails); err != nil {
+		return nil, CreateOutput{}, err
+	}
+
+	// Apply the configured last name casing
+	input.LastName = contacts.FormatLastName(input.LastName, s.lastNameCase())
+
+	// Writes need a token granted contacts:write
+	if err := requireWriteScope(ctx); err != nil {
+		return nil, CreateOutput{}, err
+	}
 
 	// Get the contacts service
 	srv, err := contacts.GetPeopleService(ctx)
@@ -365,7 +636,7 @@ func (s *Server) handleCreateContact(ctx context.Context, req *mcp.CallToolReque
 	for _, phone := range input.Phones {
 		phoneType := phone.Type
 		if phoneType == "" {
-			phoneType = "mobile"
+			phoneType = contacts.DefaultPhoneType
 		}
 		contactInput.Phones = append(contactInput.Phones, contacts.PhoneEntry{
 			Value: phone.Value,
@@ -377,7 +648,7 @@ func (s *Server) handleCreateContact(ctx context.Context, req *mcp.CallToolReque
 	for _, email := range input.Emails {
 		emailType := email.Type
 		if emailType == "" {
-			emailType = "work"
+			emailType = contacts.DefaultEmailType
 		}
 		contactInput.Emails = append(contactInput.Emails, contacts.EmailEntry{
 			Value: email.Value,
@@ -389,7 +660,7 @@ func (s *Server) handleCreateContact(ctx context.Context, req *mcp.CallToolReque
 	for _, addr := range input.Addresses {
 		addrType := addr.Type
 		if addrType == "" {
-			addrType = "home"
+			addrType = contacts.DefaultAddressType
 		}
 		contactInput.Addresses = append(contactInput.Addresses, contacts.AddressEntry{
 			Value: addr.Value,
@@ -402,12 +673,9 @@ func (s *Server) handleCreateContact(ctx context.Context, req *mcp.CallToolReque
 	if err != nil {
 		return nil, CreateOutput{}, fmt.Errorf("failed to create contact: %w", err)
 	}
+	s.recordOperation(ctx, srv, "contacts_create", journal.OpCreate, nil, &contacts.ContactDetails{ResourceName: created.ResourceName, DisplayName: created.DisplayName})
 
-	return nil, CreateOutput{
-		ResourceName: created.ResourceName,
-		DisplayName:  created.DisplayName,
-		Message:      fmt.Sprintf("Contact '%s' created successfully", created.DisplayName),
-	}, nil
+	return nil, NewCreateOutput(created), nil
 }
 
 // handleSearchContacts implements the contacts_search MCP tool.
@@ -417,8 +685,21 @@ func (s *Server) handleSearchContacts(ctx context.Context, req *mcp.CallToolRequ
 	error,
 ) {
 	// Validate required fields
-	if input.Query == "" {
-		return nil, SearchOutput{}, fmt.Errorf("query is required")
+	if input.Query == "" && input.Filter == "" {
+		return nil, SearchOutput{}, fmt.Errorf("query or filter is required")
+	}
+	if input.Mode != "" && input.Mode != "prefix" && input.Mode != "fuzzy" {
+		return nil, SearchOutput{}, fmt.Errorf("invalid mode '%s', valid modes: prefix, fuzzy", input.Mode)
+	}
+
+	// Validate the filter before contacting the API
+	var filter *contacts.Query
+	if input.Filter != "" {
+		var err error
+		filter, err = contacts.ParseQuery(input.Filter)
+		if err != nil {
+			return nil, SearchOutput{}, err
+		}
 	}
 
 	// Get the contacts service
@@ -427,29 +708,63 @@ func (s *Server) handleSearchContacts(ctx context.Context, req *mcp.CallToolRequ
 		return nil, SearchOutput{}, fmt.Errorf("failed to get contacts service: %w", err)
 	}
 
+	if input.Mode == "fuzzy" || filter != nil {
+		output, err := localSearchContacts(ctx, srv, input, filter)
+		return nil, output, err
+	}
+
 	// Search contacts
 	results, err := srv.SearchContacts(ctx, input.Query)
 	if err != nil {
 		return nil, SearchOutput{}, fmt.Errorf("failed to search contacts: %w", err)
 	}
 
-	// Convert results - always initialize Results to empty slice to avoid null in JSON
-	output := SearchOutput{
-		Count:   len(results),
-		Results: []SearchResultItem{},
+	return nil, NewSearchOutput(results), nil
+}
+
+// localSearchContacts filters and/or fuzzy-searches all contacts of the caller.
+// Contacts are fetched on each call: the server is multi-user and keeps no cache.
+func localSearchContacts(ctx context.Context, srv *contacts.Service, input SearchInput, filter *contacts.Query) (SearchOutput, error) {
+	list, err := srv.ListContacts(ctx)
+	if err != nil {
+		return SearchOutput{}, fmt.Errorf("failed to list contacts: %w", err)
 	}
-	for _, r := range results {
-		output.Results = append(output.Results, SearchResultItem{
-			ResourceName: r.ResourceName,
-			DisplayName:  r.DisplayName,
-			Phone:        r.Phone,
-			Email:        r.Email,
-			Company:      r.Company,
-			Position:     r.Position,
-		})
+
+	if filter != nil {
+		list = contacts.FilterContacts(list, filter)
 	}
 
-	return nil, output, nil
+	var matches []contacts.FuzzyResult
+	switch {
+	case input.Query == "":
+		for _, c := range list {
+			matches = append(matches, contacts.FuzzyResult{Contact: c})
+		}
+	case input.Mode == "fuzzy":
+		matches = contacts.FuzzySearch(list, input.Query, 0)
+	default:
+		for _, c := range contacts.FilterContacts(list, contacts.NewTextQuery(input.Query)) {
+			matches = append(matches, contacts.FuzzyResult{Contact: c})
+		}
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	results := make([]contacts.SearchResult, 0, len(matches))
+	for _, match := range matches {
+		results = append(results, contacts.SearchResultFromDetails(match.Contact))
+	}
+	output := NewSearchOutput(results)
+	for i, match := range matches {
+		output.Results[i].Score = math.Round(match.Score*100) / 100
+	}
+	return output, nil
 }
 
 // handleShowContact implements the contacts_show MCP tool.
@@ -469,53 +784,19 @@ func (s *Server) handleShowContact(ctx context.Context, req *mcp.CallToolRequest
 		return nil, ShowOutput{}, fmt.Errorf("failed to get contacts service: %w", err)
 	}
 
-	// Get contact details
-	details, err := srv.GetContactDetails(ctx, input.ContactID)
+	// Resolve names, emails and phones to a resource name
+	contactID, err := srv.ResolveContact(ctx, input.ContactID)
 	if err != nil {
-		return nil, ShowOutput{}, fmt.Errorf("failed to get contact: %w", err)
+		return nil, ShowOutput{}, err
 	}
 
-	// Convert to output - always initialize slices to empty to avoid null in JSON
-	output := ShowOutput{
-		ResourceName: details.ResourceName,
-		FirstName:    details.FirstName,
-		LastName:     details.LastName,
-		DisplayName:  details.DisplayName,
-		Company:      details.Company,
-		Position:     details.Position,
-		Notes:        details.Notes,
-		Birthday:     details.Birthday,
-		UpdatedAt:    details.UpdatedAt,
-		Phones:       []PhoneOutput{},
-		Emails:       []EmailOutput{},
-		Addresses:    []AddressOutput{},
-	}
-
-	// Convert phones
-	for _, phone := range details.Phones {
-		output.Phones = append(output.Phones, PhoneOutput{
-			Value: phone.Value,
-			Type:  phone.Type,
-		})
-	}
-
-	// Convert emails
-	for _, email := range details.Emails {
-		output.Emails = append(output.Emails, EmailOutput{
-			Value: email.Value,
-			Type:  email.Type,
-		})
-	}
-
-	// Convert addresses
-	for _, addr := range details.Addresses {
-		output.Addresses = append(output.Addresses, AddressOutput{
-			Value: addr.Value,
-			Type:  addr.Type,
-		})
+	// Get contact details
+	details, err := srv.GetContactDetails(ctx, contactID)
+	if err != nil {
+		return nil, ShowOutput{}, fmt.Errorf("failed to get contact: %w", err)
 	}
 
-	return nil, output, nil
+	return nil, NewShowOutput(details), nil
 }
 
 // handleUpdateContact implements the contacts_update MCP tool.
@@ -537,9 +818,20 @@ func (s *Server) handleUpdateContact(ctx context.Context, req *mcp.CallToolReque
 		return nil, UpdateOutput{}, err
 	}
 
-	// Convert last name to uppercase if provided
+	// Validate added email addresses; UpdateContact checks the replacement
+	// list against the emails the contact already has
+	if err := validateEmails(input.AddEmails); err != nil {
+		return nil, UpdateOutput{}, err
+	}
+
+	// Apply the configured last name casing if provided
 	if input.LastName != "" {
-		input.LastName = strings.ToUpper(input.LastName)
+		input.LastName = contacts.FormatLastName(input.LastName, s.lastNameCase())
+	}
+
+	// Writes need a token granted contacts:write
+	if err := requireWriteScope(ctx); err != nil {
+		return nil, UpdateOutput{}, err
 	}
 
 	// Get the contacts service
@@ -548,6 +840,12 @@ func (s *Server) handleUpdateContact(ctx context.Context, req *mcp.CallToolReque
 		return nil, UpdateOutput{}, fmt.Errorf("failed to get contacts service: %w", err)
 	}
 
+	// Resolve names, emails and phones to a resource name
+	contactID, err := srv.ResolveContact(ctx, input.ContactID)
+	if err != nil {
+		return nil, UpdateOutput{}, err
+	}
+
 	// Build UpdateInput with pointers for optional fields
 	updateInput := contacts.UpdateInput{
 		ClearBirthday: input.ClearBirthday,
@@ -577,7 +875,7 @@ func (s *Server) handleUpdateContact(ctx context.Context, req *mcp.CallToolReque
 	for _, phone := range input.Phones {
 		phoneType := phone.Type
 		if phoneType == "" {
-			phoneType = "mobile"
+			phoneType = contacts.DefaultPhoneType
 		}
 		updateInput.Phones = append(updateInput.Phones, contacts.PhoneEntry{
 			Value: phone.Value,
@@ -588,7 +886,7 @@ func (s *Server) handleUpdateContact(ctx context.Context, req *mcp.CallToolReque
 	for _, phone := range input.AddPhones {
 		phoneType := phone.Type
 		if phoneType == "" {
-			phoneType = "mobile"
+			phoneType = contacts.DefaultPhoneType
 		}
 		updateInput.AddPhones = append(updateInput.AddPhones, contacts.PhoneEntry{
 			Value: phone.Value,
@@ -602,7 +900,7 @@ func (s *Server) handleUpdateContact(ctx context.Context, req *mcp.CallToolReque
 	for _, email := range input.Emails {
 		emailType := email.Type
 		if emailType == "" {
-			emailType = "work"
+			emailType = contacts.DefaultEmailType
 		}
 		updateInput.Emails = append(updateInput.Emails, contacts.EmailEntry{
 			Value: email.Value,
@@ -613,7 +911,7 @@ func (s *Server) handleUpdateContact(ctx context.Context, req *mcp.CallToolReque
 	for _, email := range input.AddEmails {
 		emailType := email.Type
 		if emailType == "" {
-			emailType = "work"
+			emailType = contacts.DefaultEmailType
 		}
 		updateInput.AddEmails = append(updateInput.AddEmails, contacts.EmailEntry{
 			Value: email.Value,
@@ -627,7 +925,7 @@ func (s *Server) handleUpdateContact(ctx context.Context, req *mcp.CallToolReque
 	for _, addr := range input.Addresses {
 		addrType := addr.Type
 		if addrType == "" {
-			addrType = "home"
+			addrType = contacts.DefaultAddressType
 		}
 		updateInput.Addresses = append(updateInput.Addresses, contacts.AddressEntry{
 			Value: addr.Value,
@@ -638,7 +936,7 @@ func (s *Server) handleUpdateContact(ctx context.Context, req *mcp.CallToolReque
 	for _, addr := range input.AddAddresses {
 		addrType := addr.Type
 		if addrType == "" {
-			addrType = "home"
+			addrType = contacts.DefaultAddressType
 		}
 		updateInput.AddAddresses = append(updateInput.AddAddresses, contacts.AddressEntry{
 			Value: addr.Value,
@@ -648,54 +946,23 @@ func (s *Server) handleUpdateContact(ctx context.Context, req *mcp.CallToolReque
 
 	updateInput.RemoveAddresses = input.RemoveAddresses
 
+	// Keep the previous state for the operation journal
+	var before *contacts.ContactDetails
+	if s.config.Journal != nil {
+		before, err = srv.GetContactDetails(ctx, contactID)
+		if err != nil {
+			return nil, UpdateOutput{}, fmt.Errorf("failed to get contact: %w", err)
+		}
+	}
+
 	// Update the contact
-	details, err := srv.UpdateContact(ctx, input.ContactID, updateInput)
+	details, err := srv.UpdateContact(ctx, contactID, updateInput)
 	if err != nil {
 		return nil, UpdateOutput{}, fmt.Errorf("failed to update contact: %w", err)
 	}
+	s.recordOperation(ctx, srv, "contacts_update", journal.OpUpdate, before, details)
 
-	// Convert to output - always initialize slices to empty to avoid null in JSON
-	output := UpdateOutput{
-		Message: fmt.Sprintf("Contact '%s' updated successfully", details.DisplayName),
-	}
-	output.ResourceName = details.ResourceName
-	output.FirstName = details.FirstName
-	output.LastName = details.LastName
-	output.DisplayName = details.DisplayName
-	output.Company = details.Company
-	output.Position = details.Position
-	output.Notes = details.Notes
-	output.Birthday = details.Birthday
-	output.UpdatedAt = details.UpdatedAt
-	output.Phones = []PhoneOutput{}
-	output.Emails = []EmailOutput{}
-	output.Addresses = []AddressOutput{}
-
-	// Convert phones
-	for _, phone := range details.Phones {
-		output.Phones = append(output.Phones, PhoneOutput{
-			Value: phone.Value,
-			Type:  phone.Type,
-		})
-	}
-
-	// Convert emails
-	for _, email := range details.Emails {
-		output.Emails = append(output.Emails, EmailOutput{
-			Value: email.Value,
-			Type:  email.Type,
-		})
-	}
-
-	// Convert addresses
-	for _, addr := range details.Addresses {
-		output.Addresses = append(output.Addresses, AddressOutput{
-			Value: addr.Value,
-			Type:  addr.Type,
-		})
-	}
-
-	return nil, output, nil
+	return nil, NewUpdateOutput(details), nil
 }
 
 // handleDeleteContact implements the contacts_delete MCP tool.
@@ -709,31 +976,172 @@ func (s *Server) handleDeleteContact(ctx context.Context, req *mcp.CallToolReque
 		return nil, DeleteOutput{}, fmt.Errorf("contactId is required")
 	}
 
+	// Writes need a token granted contacts:write
+	if err := requireWriteScope(ctx); err != nil {
+		return nil, DeleteOutput{}, err
+	}
+
 	// Get the contacts service
 	srv, err := contacts.GetPeopleService(ctx)
 	if err != nil {
 		return nil, DeleteOutput{}, fmt.Errorf("failed to get contacts service: %w", err)
 	}
 
+	// Resolve names, emails and phones to a resource name
+	contactID, err := srv.ResolveContact(ctx, input.ContactID)
+	if err != nil {
+		return nil, DeleteOutput{}, err
+	}
+
 	// Get contact details before deletion for confirmation
-	details, err := srv.GetContactDetails(ctx, input.ContactID)
+	details, err := srv.GetContactDetails(ctx, contactID)
 	if err != nil {
 		return nil, DeleteOutput{}, fmt.Errorf("failed to get contact: %w", err)
 	}
 
-	displayName := details.DisplayName
+	// Without trash, the deletion is permanent
+	if s.config.Trash == nil {
+		if err := srv.DeleteContact(ctx, contactID); err != nil {
+			return nil, DeleteOutput{}, fmt.Errorf("failed to delete contact: %w", err)
+		}
+		s.recordOperation(ctx, srv, "contacts_delete", journal.OpDelete, details, nil)
+		return nil, NewDeleteOutput(details), nil
+	}
 
-	// Delete the contact
-	err = srv.DeleteContact(ctx, input.ContactID)
+	// Archive the complete contact, then delete it
+	item, err := s.config.Trash.MoveToTrash(ctx, srv, contactID, journal.SourceMCP)
 	if err != nil {
 		return nil, DeleteOutput{}, fmt.Errorf("failed to delete contact: %w", err)
 	}
+	s.recordOperation(ctx, srv, "contacts_delete", journal.OpDelete, details, nil)
 
-	return nil, DeleteOutput{
-		Message:     fmt.Sprintf("Contact '%s' deleted successfully", displayName),
-		DeletedID:   details.ResourceName,
-		DisplayName: displayName,
-	}, nil
+	output := NewDeleteOutput(details)
+	output.TrashID = item.ID
+	output.Message += fmt.Sprintf(" (restorable with contacts_restore, trash ID %s)", item.ID)
+	return nil, output, nil
+}
+
+// handleRestoreContact implements the contacts_restore MCP tool.
+func (s *Server) handleRestoreContact(ctx context.Context, req *mcp.CallToolRequest, input RestoreInput) (
+	*mcp.CallToolResult,
+	RestoreOutput,
+	error,
+) {
+	// Validate required fields
+	if input.ID == "" {
+		return nil, RestoreOutput{}, fmt.Errorf("id is required")
+	}
+	if s.config.Trash == nil {
+		return nil, RestoreOutput{}, fmt.Errorf("the trash is disabled on this server")
+	}
+
+	// Writes need a token granted contacts:write
+	if err := requireWriteScope(ctx); err != nil {
+		return nil, RestoreOutput{}, err
+	}
+
+	// Get the contacts service
+	srv, err := contacts.GetPeopleService(ctx)
+	if err != nil {
+		return nil, RestoreOutput{}, fmt.Errorf("failed to get contacts service: %w", err)
+	}
+
+	// Only the account a contact was deleted from can restore it
+	items, err := s.config.Trash.List()
+	if err != nil {
+		return nil, RestoreOutput{}, err
+	}
+	account, err := srv.AccountEmail(ctx)
+	if err != nil {
+		return nil, RestoreOutput{}, err
+	}
+	var owned []trash.Item
+	for _, item := range items {
+		if item.Owner != "" && strings.EqualFold(item.Owner, account) {
+			owned = append(owned, item)
+		}
+	}
+	item, err := trash.Find(owned, input.ID)
+	if err != nil {
+		return nil, RestoreOutput{}, err
+	}
+
+	// Recreate the contact, then drop it from the trash
+	restored, err := srv.RestoreContact(ctx, &item.Archive)
+	if err != nil {
+		return nil, RestoreOutput{}, fmt.Errorf("failed to restore contact: %w", err)
+	}
+	if err := s.config.Trash.Remove(item.ID); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	s.recordOperation(ctx, srv, "contacts_restore", journal.OpCreate, nil, &contacts.ContactDetails{
+		ResourceName: restored.ResourceName,
+		DisplayName:  restored.DisplayName,
+	})
+
+	output := RestoreOutput{
+		ResourceName: restored.ResourceName,
+		DisplayName:  restored.DisplayName,
+		Message:      fmt.Sprintf("Contact '%s' restored with a new ID", restored.DisplayName),
+		Warnings:     []string{},
+	}
+	output.Warnings = append(output.Warnings, restored.Warnings...)
+	return nil, output, nil
+}
+
+// lastNameCase returns the configured last name casing.
+func (s *Server) lastNameCase() string {
+	if s.config.LastNameCase == "" {
+		return contacts.LastNameUpper
+	}
+	return s.config.LastNameCase
+}
+
+// recordOperation records a modification made by an MCP tool in the journal,
+// with the account of the caller so that history and undo only show each
+// account its own operations. A journal failure does not revert the
+// modification, so it is only logged.
+func (s *Server) recordOperation(ctx context.Context, srv *contacts.Service, tool string, op journal.Operation, before, after *contacts.ContactDetails) {
+	if s.config.Journal == nil {
+		return
+	}
+	e := journal.NewEntry(op, journal.SourceMCP, tool, before, after)
+	e.Account, _ = srv.AccountEmail(ctx)
+	if _, err := s.config.Journal.Append(e); err != nil {
+		log.Printf("Warning: failed to record operation: %v", err)
+	}
+}
+
+// handleAuditContacts implements the contacts_audit MCP tool.
+func (s *Server) handleAuditContacts(ctx context.Context, req *mcp.CallToolRequest, input AuditInput) (
+	*mcp.CallToolResult,
+	AuditOutput,
+	error,
+) {
+	// Validate rule names
+	var rules []contacts.AuditRule
+	for _, name := range input.Rules {
+		rule, err := contacts.ParseAuditRule(name)
+		if err != nil {
+			return nil, AuditOutput{}, err
+		}
+		rules = append(rules, rule)
+	}
+
+	// Get the contacts service
+	srv, err := contacts.GetPeopleService(ctx)
+	if err != nil {
+		return nil, AuditOutput{}, fmt.Errorf("failed to get contacts service: %w", err)
+	}
+
+	// Audit the whole address book
+	list, err := srv.ListContacts(ctx)
+	if err != nil {
+		return nil, AuditOutput{}, fmt.Errorf("failed to list contacts: %w", err)
+	}
+
+	findings := contacts.AuditContacts(list, contacts.AuditOptions{Rules: rules})
+	return nil, NewAuditOutput(findings, len(list)), nil
 }
 
 // Run starts the HTTP server and blocks until shutdown.
@@ -757,12 +1165,46 @@ func (s *Server) Run(ctx context.Context) error {
 		credFile = LocalCredentialsPath()
 	}
 
+	// Service-account mode: the contacts scopes are granted to the service
+	// account, callers only authorize their identity
+	if s.config.ServiceAccountFile != "" {
+		sa, err := auth.LoadServiceAccount(s.config.ServiceAccountFile)
+		if err != nil {
+			return err
+		}
+		sa.ScopeSet = s.config.ScopeSet
+		if sa.ScopeSet == "" {
+			sa.ScopeSet = auth.ScopeSetContacts
+		}
+		s.serviceAccount = sa
+	}
+
+	// OAuth state survives restarts unless the store is in memory
+	storeKind := s.config.OAuthStore
+	if storeKind == "" {
+		storeKind = StoreMemory
+	}
+	store, err := OpenOAuthStore(ctx, storeKind, s.config.OAuthStorePath, s.config.FirestoreProject, s.config.FirestoreDatabase)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	tokenCipher, err := OpenTokenCipher(storeKind, s.config.OAuthKeyFile)
+	if err != nil {
+		return err
+	}
+	log.Printf("OAuth store: %s", storeKind)
+
 	// Initialize OAuth2 server
 	s.oauth2Server = NewOAuth2Server(&OAuth2ServerConfig{
 		BaseURL:        s.config.BaseURL,
 		SecretProject:  s.config.SecretProject,
 		SecretName:     s.config.SecretName,
 		CredentialFile: credFile,
+		ScopeSet:       s.config.ScopeSet,
+		IdentityOnly:   s.serviceAccount != nil,
+		Store:          store,
+		TokenCipher:    tokenCipher,
 	})
 
 	// Register OAuth2 routes (not protected by auth)
@@ -774,6 +1216,8 @@ func (s *Server) Run(ctx context.Context) error {
 	log.Println("  - /oauth/authorize")
 	log.Println("  - /oauth/callback")
 	log.Println("  - /oauth/token")
+	log.Println("  - /oauth/introspect")
+	log.Println("  - /oauth/revoke")
 
 	// Health check endpoint (not protected by auth)
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -781,13 +1225,31 @@ func (s *Server) Run(ctx context.Context) error {
 		w.Write([]byte("OK"))
 	})
 
+	// Calendar subscription feed (protected by the token in its URL)
+	if s.config.CalendarFeed {
+		token := s.config.CalendarToken
+		if token == "" {
+			return errors.New("calendar feed needs a calendar token")
+		}
+		log.Printf("Calendar feed enabled: %s/calendar/%s.ics", s.config.BaseURL, truncateToken(token))
+		mux.Handle("/calendar/", NewCalendarFeed(token, s.config.CalendarAlarmDays))
+	}
+
 	// Wrap MCP handler with authentication middleware
 	authedMCPHandler := s.authMiddleware(mcpHandler)
 
 	// MCP endpoint (protected by OAuth2 Bearer token auth)
 	mux.Handle("/", authedMCPHandler)
 
-	log.Println("Authentication mode: OAuth2 Bearer tokens")
+	if s.serviceAccount != nil {
+		domain := s.config.ImpersonateDomain
+		if domain == "" {
+			domain = "any domain"
+		}
+		log.Printf("Authentication mode: OAuth2 Bearer tokens, service account %s acting as each caller (%s)", s.serviceAccount.Email, domain)
+	} else {
+		log.Println("Authentication mode: OAuth2 Bearer tokens")
+	}
 
 	// Create HTTP server
 	addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
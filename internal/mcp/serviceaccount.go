@@ -0,0 +1,44 @@
+package mcp
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"google-contacts/pkg/auth"
+)
+
+// errCallerDomain rejects callers outside the impersonated domain.
+var errCallerDomain = errors.New("caller is not a member of the allowed domain")
+
+// callerSubject returns the user the service account acts as for a
+// validated token: the verified email of the Google account that authorized
+// it, within the impersonated domain when one is set.
+func (s *Server) callerSubject(token *ValidatedToken) (string, error) {
+	if token.Subject == "" || !token.EmailVerified {
+		return "", fmt.Errorf("token has no verified email")
+	}
+	if err := auth.ValidateSubject(token.Subject); err != nil {
+		return "", err
+	}
+	if domain := s.config.ImpersonateDomain; domain != "" {
+		_, emailDomain, _ := strings.Cut(token.Subject, "@")
+		if !strings.EqualFold(emailDomain, domain) {
+			return "", fmt.Errorf("%w: %s", errCallerDomain, token.Subject)
+		}
+	}
+	return token.Subject, nil
+}
+
+// callerServiceAccount returns the service account to act as a caller with:
+// grants without contacts:write get the readonly scope set, so that the
+// delegated token cannot write either.
+func (s *Server) callerServiceAccount(scopes []string) *auth.ServiceAccount {
+	if slices.Contains(scopes, ScopeContactsWrite) {
+		return s.serviceAccount
+	}
+	readonly := *s.serviceAccount
+	readonly.ScopeSet = auth.ScopeSetContactsReadonly
+	return &readonly
+}
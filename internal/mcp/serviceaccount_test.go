@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google-contacts/pkg/auth"
+)
+
+func TestCallerSubject(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   ValidatedToken
+		domain  string
+		want    string
+		wantErr bool
+		domErr  bool
+	}{
+		{name: "verified caller", token: ValidatedToken{Subject: "jane@example.com", EmailVerified: true}, want: "jane@example.com"},
+		{name: "allowed domain", token: ValidatedToken{Subject: "jane@example.com", EmailVerified: true}, domain: "EXAMPLE.com", want: "jane@example.com"},
+		{name: "other domain", token: ValidatedToken{Subject: "jane@example.com", EmailVerified: true}, domain: "corp.com", wantErr: true, domErr: true},
+		{name: "unverified email", token: ValidatedToken{Subject: "jane@example.com"}, wantErr: true},
+		{name: "no email", token: ValidatedToken{EmailVerified: true}, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := NewServer(&Config{ImpersonateDomain: tc.domain})
+			got, err := server.callerSubject(&tc.token)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("callerSubject() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if errors.Is(err, errCallerDomain) != tc.domErr {
+				t.Errorf("callerSubject() error = %v, domain error %v", err, tc.domErr)
+			}
+			if got != tc.want {
+				t.Errorf("callerSubject() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestOAuth2ServerIdentityOnly(t *testing.T) {
+	s := NewOAuth2Server(&OAuth2ServerConfig{IdentityOnly: true})
+	if got := s.googleScopes([]string{ScopeContactsRead, ScopeContactsWrite}); len(got) != 2 || got[0] != "openid" || got[1] != "email" {
+		t.Errorf("googleScopes() = %v, want openid email", got)
+	}
+	requested := []string{ScopeContactsRead}
+	if got := s.grantedScopes(nil, requested); len(got) != 1 || got[0] != ScopeContactsRead {
+		t.Errorf("grantedScopes() = %v, want %v", got, requested)
+	}
+}
+
+func TestServiceAccountMode_ReadOnlyToken(t *testing.T) {
+	const baseURL = "https://mcp.example.com"
+	sa, err := auth.ParseServiceAccount([]byte(`{"type":"service_account","client_email":"sa@project.iam.gserviceaccount.com","client_id":"1"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sa.ScopeSet = auth.ScopeSetContacts
+	server := NewServer(&Config{BaseURL: baseURL})
+	server.serviceAccount = sa
+	server.oauth2Server = NewOAuth2Server(&OAuth2ServerConfig{BaseURL: baseURL, IdentityOnly: true})
+
+	// callerContext returns the request context of a token with scopes
+	callerContext := func(scopes ...string) context.Context {
+		tokens, err := server.oauth2Server.vault.issue(context.Background(), &vaultGrant{
+			ClientID: "client", Subject: "jane@example.com", EmailVerified: true, Audience: baseURL, Scopes: scopes,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ctx context.Context
+		handler := server.authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { ctx = r.Context() }))
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if ctx == nil {
+			t.Fatalf("request rejected: %d %s", rec.Code, rec.Body)
+		}
+		return ctx
+	}
+
+	ctx := callerContext(ScopeContactsRead)
+	if _, _, err := server.handleDeleteContact(ctx, nil, DeleteInput{ContactID: "people/c1"}); !errors.Is(err, auth.ErrInsufficientScope) {
+		t.Errorf("contacts_delete with a read-only token error = %v, want ErrInsufficientScope", err)
+	}
+	if got, subject, _ := auth.GetServiceAccountFromContext(ctx); got.ScopeSet != auth.ScopeSetContactsReadonly || subject != "jane@example.com" {
+		t.Errorf("read-only caller acts with scope set %q as %q", got.ScopeSet, subject)
+	}
+
+	ctx = callerContext(ScopeContactsRead, ScopeContactsWrite)
+	if err := requireWriteScope(ctx); err != nil {
+		t.Errorf("requireWriteScope() with write scope error = %v", err)
+	}
+	if got, _, _ := auth.GetServiceAccountFromContext(ctx); got.ScopeSet != auth.ScopeSetContacts {
+		t.Errorf("write caller acts with scope set %q, want %q", got.ScopeSet, auth.ScopeSetContacts)
+	}
+}
@@ -0,0 +1,314 @@
+package mcp
+
+import (
+	"context"
+	"crypto/cipher"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"google-contacts/pkg/auth"
+)
+
+// OAuth store backends, selected by the mcp.oauthStore setting.
+const (
+	StoreMemory    = "memory"
+	StoreFile      = "file"
+	StoreFirestore = "firestore"
+)
+
+// OAuthStores lists the OAuth store backends.
+var OAuthStores = []string{StoreMemory, StoreFile, StoreFirestore}
+
+// ErrNotFound is returned for missing and expired store entries.
+var ErrNotFound = errors.New("not found")
+
+// Kinds of entries kept by the OAuth2 server.
+const (
+	kindClients       = "clients"
+	kindStates        = "states"
+	kindCodes         = "codes"
+	kindGrants        = "grants"
+	kindAccessTokens  = "access_tokens"
+	kindRefreshTokens = "refresh_tokens"
+)
+
+// OAuthStore persists the state of the OAuth2 server: registered clients,
+// pending authorizations, authorization codes and the token vault. Values
+// are stored as JSON. Each entry has an expiry after which it is reported
+// missing (TTL), so no cleanup is needed.
+type OAuthStore interface {
+	// Put stores value under kind/key until expiry (zero never expires),
+	// replacing any previous value.
+	Put(ctx context.Context, kind, key string, value any, expiry time.Time) error
+	// Update replaces an entry atomically if it exists, or returns
+	// ErrNotFound: an entry deleted meanwhile (revoked grant) stays deleted.
+	Update(ctx context.Context, kind, key string, value any, expiry time.Time) error
+	// Get decodes the entry into value, or returns ErrNotFound.
+	Get(ctx context.Context, kind, key string, value any) error
+	// Take gets then deletes an entry atomically: a single-use entry
+	// (authorization code, refresh token) is only taken once.
+	Take(ctx context.Context, kind, key string, value any) error
+	// Delete removes an entry; a missing entry is not an error.
+	Delete(ctx context.Context, kind, key string) error
+	// Close releases the store resources.
+	Close() error
+}
+
+// storeEntry is a stored value with its expiry.
+type storeEntry struct {
+	Value  json.RawMessage `json:"value"`
+	Expiry time.Time       `json:"expiry,omitzero"`
+}
+
+func (e storeEntry) expired(now time.Time) bool {
+	return !e.Expiry.IsZero() && now.After(e.Expiry)
+}
+
+// MemoryStore keeps the OAuth state in memory: it is lost on restart.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]map[string]storeEntry
+
+	// persist is called with the entries after each change (file store)
+	persist func(map[string]map[string]storeEntry) error
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]map[string]storeEntry)}
+}
+
+func (s *MemoryStore) Put(ctx context.Context, kind, key string, value any, expiry time.Time) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s entry: %w", kind, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := s.entries[kind]
+	if entries == nil {
+		entries = make(map[string]storeEntry)
+		s.entries[kind] = entries
+	}
+	// Expired entries of the kind are dropped on write
+	now := time.Now()
+	for k, e := range entries {
+		if e.expired(now) {
+			delete(entries, k)
+		}
+	}
+	entries[key] = storeEntry{Value: data, Expiry: expiry}
+	return s.save()
+}
+
+func (s *MemoryStore) Update(ctx context.Context, kind, key string, value any, expiry time.Time) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s entry: %w", kind, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.entries[kind][key]; !ok || entry.expired(time.Now()) {
+		return ErrNotFound
+	}
+	s.entries[kind][key] = storeEntry{Value: data, Expiry: expiry}
+	return s.save()
+}
+
+func (s *MemoryStore) Get(ctx context.Context, kind, key string, value any) error {
+	s.mu.Lock()
+	entry, ok := s.entries[kind][key]
+	s.mu.Unlock()
+	return decodeEntry(kind, entry, ok, value)
+}
+
+func (s *MemoryStore) Take(ctx context.Context, kind, key string, value any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[kind][key]
+	if !ok {
+		return ErrNotFound
+	}
+	delete(s.entries[kind], key)
+	if err := s.save(); err != nil {
+		return err
+	}
+	return decodeEntry(kind, entry, ok, value)
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, kind, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[kind][key]; !ok {
+		return nil
+	}
+	delete(s.entries[kind], key)
+	return s.save()
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// save persists the entries, if the store is persistent. The caller holds
+// s.mu.
+func (s *MemoryStore) save() error {
+	if s.persist == nil {
+		return nil
+	}
+	return s.persist(s.entries)
+}
+
+// decodeEntry decodes a stored entry, reporting missing and expired ones as
+// ErrNotFound.
+func decodeEntry(kind string, entry storeEntry, ok bool, value any) error {
+	if !ok || entry.expired(time.Now()) {
+		return ErrNotFound
+	}
+	if err := json.Unmarshal(entry.Value, value); err != nil {
+		return fmt.Errorf("failed to decode %s entry: %w", kind, err)
+	}
+	return nil
+}
+
+// DefaultFileStorePath returns the file store location in the user config
+// directory.
+func DefaultFileStorePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "google-contacts", "mcp-oauth.json"), nil
+}
+
+// DefaultKeyFilePath returns the location of the key encrypting the Google
+// tokens of the file store, in the user config directory.
+func DefaultKeyFilePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "google-contacts", "mcp-oauth.key"), nil
+}
+
+// NewFileStore returns a store kept in a JSON file (0600), for single
+// instance deployments. The file is read once and rewritten atomically after
+// each change; expired entries are dropped when it is written.
+func NewFileStore(path string) (*MemoryStore, error) {
+	s := NewMemoryStore()
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("failed to read OAuth store: %w", err)
+	default:
+		if err := json.Unmarshal(data, &s.entries); err != nil {
+			return nil, fmt.Errorf("failed to read OAuth store %s: %w", path, err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create OAuth store directory: %w", err)
+	}
+	s.persist = func(entries map[string]map[string]storeEntry) error {
+		return writeStoreFile(path, entries)
+	}
+	return s, nil
+}
+
+// writeStoreFile atomically replaces the store file with the live entries.
+func writeStoreFile(path string, entries map[string]map[string]storeEntry) error {
+	now := time.Now()
+	live := make(map[string]map[string]storeEntry, len(entries))
+	for kind, byKey := range entries {
+		for key, entry := range byKey {
+			if entry.expired(now) {
+				continue
+			}
+			if live[kind] == nil {
+				live[kind] = make(map[string]storeEntry)
+			}
+			live[kind][key] = entry
+		}
+	}
+	data, err := json.Marshal(live)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to save OAuth store: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save OAuth store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save OAuth store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save OAuth store: %w", err)
+	}
+	return nil
+}
+
+// OpenTokenCipher returns the cipher of the Google tokens kept in the OAuth
+// store of a backend, from keyFile (see auth.GenerateKeyFile). The memory
+// backend needs no key (nil: the vault uses a random one). The file backend
+// creates a missing key file (DefaultKeyFilePath if empty); Firestore needs
+// an existing one, shared by all the instances.
+func OpenTokenCipher(kind, keyFile string) (cipher.AEAD, error) {
+	switch kind {
+	case StoreMemory, "":
+		return nil, nil
+	case StoreFile:
+		if keyFile == "" {
+			var err error
+			if keyFile, err = DefaultKeyFilePath(); err != nil {
+				return nil, err
+			}
+		}
+		if err := auth.GenerateKeyFile(keyFile); err != nil && !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+	case StoreFirestore:
+		if keyFile == "" {
+			return nil, fmt.Errorf("the firestore OAuth store needs a key file to encrypt the Google tokens")
+		}
+	default:
+		return nil, fmt.Errorf("invalid OAuth store '%s'", kind)
+	}
+	key, err := auth.ReadKeyFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return NewTokenCipher(key)
+}
+
+// OpenOAuthStore opens the OAuth store of a backend. The file backend uses
+// path (DefaultFileStorePath if empty), Firestore uses project and database.
+func OpenOAuthStore(ctx context.Context, kind, path, project, database string) (OAuthStore, error) {
+	switch kind {
+	case StoreMemory, "":
+		return NewMemoryStore(), nil
+	case StoreFile:
+		if path == "" {
+			var err error
+			if path, err = DefaultFileStorePath(); err != nil {
+				return nil, err
+			}
+		}
+		return NewFileStore(path)
+	case StoreFirestore:
+		return NewFirestoreStore(ctx, project, database)
+	default:
+		return nil, fmt.Errorf("invalid OAuth store '%s'", kind)
+	}
+}
@@ -0,0 +1,145 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// firestoreCollectionPrefix prefixes the collection of each kind of entry
+// (oauth_clients, oauth_codes...), matching the Terraform TTL policies.
+const firestoreCollectionPrefix = "oauth_"
+
+// FirestoreStore keeps the OAuth state in Firestore, shared by all the
+// instances of a deployment. Each kind of entry is a collection whose
+// documents hold the JSON value and an expires_at field: the collections
+// have a TTL policy on it, and as Firestore deletes expired documents with
+// a delay, expiry is also checked on read.
+type FirestoreStore struct {
+	client *firestore.Client
+}
+
+// NewFirestoreStore connects to a Firestore database of project (the
+// "(default)" database if empty).
+func NewFirestoreStore(ctx context.Context, project, database string) (*FirestoreStore, error) {
+	if project == "" {
+		return nil, fmt.Errorf("the Firestore OAuth store requires a project")
+	}
+	if database == "" {
+		database = firestore.DefaultDatabaseID
+	}
+	client, err := firestore.NewClientWithDatabase(ctx, project, database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Firestore: %w", err)
+	}
+	return &FirestoreStore{client: client}, nil
+}
+
+// doc returns the document of an entry. Keys are hashed: client IDs may
+// contain characters not allowed in document IDs.
+func (s *FirestoreStore) doc(kind, key string) *firestore.DocumentRef {
+	return s.client.Collection(firestoreCollectionPrefix + kind).Doc(tokenHash(key))
+}
+
+func (s *FirestoreStore) Put(ctx context.Context, kind, key string, value any, expiry time.Time) error {
+	fields, err := documentFields(kind, value, expiry)
+	if err != nil {
+		return err
+	}
+	if _, err := s.doc(kind, key).Set(ctx, fields); err != nil {
+		return fmt.Errorf("failed to store %s entry: %w", kind, err)
+	}
+	return nil
+}
+
+func (s *FirestoreStore) Update(ctx context.Context, kind, key string, value any, expiry time.Time) error {
+	fields, err := documentFields(kind, value, expiry)
+	if err != nil {
+		return err
+	}
+	doc := s.doc(kind, key)
+	return s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snap, err := tx.Get(doc)
+		if err != nil {
+			return firestoreError(kind, err)
+		}
+		// Expired documents not deleted yet are missing too
+		var current json.RawMessage
+		if err := decodeSnapshot(kind, snap, &current); err != nil {
+			return err
+		}
+		return tx.Set(doc, fields)
+	})
+}
+
+func (s *FirestoreStore) Get(ctx context.Context, kind, key string, value any) error {
+	snap, err := s.doc(kind, key).Get(ctx)
+	if err != nil {
+		return firestoreError(kind, err)
+	}
+	return decodeSnapshot(kind, snap, value)
+}
+
+func (s *FirestoreStore) Take(ctx context.Context, kind, key string, value any) error {
+	doc := s.doc(kind, key)
+	return s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snap, err := tx.Get(doc)
+		if err != nil {
+			return firestoreError(kind, err)
+		}
+		if err := tx.Delete(doc); err != nil {
+			return err
+		}
+		return decodeSnapshot(kind, snap, value)
+	})
+}
+
+func (s *FirestoreStore) Delete(ctx context.Context, kind, key string) error {
+	if _, err := s.doc(kind, key).Delete(ctx); err != nil {
+		return fmt.Errorf("failed to delete %s entry: %w", kind, err)
+	}
+	return nil
+}
+
+func (s *FirestoreStore) Close() error {
+	return s.client.Close()
+}
+
+// documentFields returns the fields of the document of an entry.
+func documentFields(kind string, value any, expiry time.Time) (map[string]any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s entry: %w", kind, err)
+	}
+	fields := map[string]any{"value": string(data)}
+	if !expiry.IsZero() {
+		fields["expires_at"] = expiry
+	}
+	return fields, nil
+}
+
+// firestoreError maps a missing document to ErrNotFound.
+func firestoreError(kind string, err error) error {
+	if status.Code(err) == codes.NotFound {
+		return ErrNotFound
+	}
+	return fmt.Errorf("failed to read %s entry: %w", kind, err)
+}
+
+// decodeSnapshot decodes the value of a document, reporting expired ones as
+// ErrNotFound.
+func decodeSnapshot(kind string, snap *firestore.DocumentSnapshot, value any) error {
+	entry := storeEntry{}
+	if data, ok := snap.Data()["value"].(string); ok {
+		entry.Value = json.RawMessage(data)
+	}
+	if expiry, ok := snap.Data()["expires_at"].(time.Time); ok {
+		entry.Expiry = expiry
+	}
+	return decodeEntry(kind, entry, true, value)
+}
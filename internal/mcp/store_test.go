@@ -0,0 +1,180 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testOAuthStore checks the OAuthStore contract on store.
+func testOAuthStore(t *testing.T, store OAuthStore) {
+	ctx := context.Background()
+	key := generateSecureToken(8) // Unique in a shared Firestore emulator
+	type value struct{ Name string }
+	var got value
+
+	if err := store.Get(ctx, kindClients, key, &got); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(missing) error = %v, want ErrNotFound", err)
+	}
+
+	if err := store.Put(ctx, kindClients, key, value{"first"}, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(ctx, kindClients, key, value{"second"}, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Get(ctx, kindClients, key, &got); err != nil || got.Name != "second" {
+		t.Errorf("Get() = %+v, %v, want replaced value", got, err)
+	}
+	if err := store.Get(ctx, kindCodes, key, &got); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(other kind) error = %v, want ErrNotFound", err)
+	}
+
+	// Update only replaces existing entries
+	if err := store.Update(ctx, kindClients, key, value{"updated"}, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Get(ctx, kindClients, key, &got); err != nil || got.Name != "updated" {
+		t.Errorf("Get() = %+v, %v, want updated value", got, err)
+	}
+	if err := store.Update(ctx, kindGrants, key, value{"revoked"}, time.Time{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update(missing) error = %v, want ErrNotFound", err)
+	}
+	if err := store.Get(ctx, kindGrants, key, &got); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Update(missing) error = %v, want ErrNotFound", err)
+	}
+
+	// Expired entries are missing even before they are deleted
+	if err := store.Put(ctx, kindStates, key, value{"expired"}, time.Now().Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Get(ctx, kindStates, key, &got); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(expired) error = %v, want ErrNotFound", err)
+	}
+	if err := store.Update(ctx, kindStates, key, value{"revived"}, time.Time{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update(expired) error = %v, want ErrNotFound", err)
+	}
+
+	// Take is single use
+	if err := store.Put(ctx, kindCodes, key, value{"code"}, time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	got = value{}
+	if err := store.Take(ctx, kindCodes, key, &got); err != nil || got.Name != "code" {
+		t.Errorf("Take() = %+v, %v", got, err)
+	}
+	if err := store.Take(ctx, kindCodes, key, &got); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Take() error = %v, want ErrNotFound", err)
+	}
+
+	if err := store.Delete(ctx, kindClients, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Get(ctx, kindClients, key, &got); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(deleted) error = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, kindClients, key); err != nil {
+		t.Errorf("Delete(missing) error = %v", err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testOAuthStore(t, NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "google-contacts", "mcp-oauth.json")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testOAuthStore(t, store)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("store file mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestFirestoreStore(t *testing.T) {
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+		t.Skip("FIRESTORE_EMULATOR_HOST is not set")
+	}
+	store, err := NewFirestoreStore(context.Background(), "google-contacts-test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	testOAuthStore(t, store)
+}
+
+func TestFileStore_SurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp-oauth.json")
+	body := `{"access_token":"google-fresh","token_type":"Bearer","expires_in":3600}`
+
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, code := tokenTestServerWithStore(t, http.StatusOK, body, store)
+	_, resp := postToken(t, s, url.Values{"grant_type": {"authorization_code"}, "code": {code}, "client_id": {"client"}})
+	if resp.AccessToken == "" {
+		t.Fatal("no token issued")
+	}
+
+	// A new server instance accepts the tokens issued before the restart
+	restarted, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ = tokenTestServerWithStore(t, http.StatusOK, body, restarted)
+	validated, err := s.ValidateAccessToken(context.Background(), resp.AccessToken)
+	if err != nil || validated.Subject != "jane@example.com" {
+		t.Fatalf("ValidateAccessToken() after restart = %+v, %v", validated, err)
+	}
+	if status, _ := postToken(t, s, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {resp.RefreshToken}, "client_id": {"client"}}); status != http.StatusOK {
+		t.Errorf("refresh after restart status = %d", status)
+	}
+}
+
+func TestOpenTokenCipher(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "mcp-oauth.key")
+
+	if aead, err := OpenTokenCipher(StoreMemory, ""); err != nil || aead != nil {
+		t.Errorf("OpenTokenCipher(memory) = %v, %v; want the vault random key", aead, err)
+	}
+	if _, err := OpenTokenCipher(StoreFirestore, keyFile); err == nil {
+		t.Error("OpenTokenCipher(firestore) with a missing key file succeeded")
+	}
+	if _, err := OpenTokenCipher(StoreFirestore, ""); err == nil {
+		t.Error("OpenTokenCipher(firestore) without key file succeeded")
+	}
+
+	// The file store creates its key once, then reuses it
+	first, err := OpenTokenCipher(StoreFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(keyFile)
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("key file = %v, %v; want 0600", info, err)
+	}
+	sealed := first.Seal(nil, make([]byte, first.NonceSize()), []byte("token"), nil)
+	for _, kind := range []string{StoreFile, StoreFirestore} {
+		aead, err := OpenTokenCipher(kind, keyFile)
+		if err != nil {
+			t.Fatalf("OpenTokenCipher(%s) error = %v", kind, err)
+		}
+		if _, err := aead.Open(nil, make([]byte, aead.NonceSize()), sealed, nil); err != nil {
+			t.Errorf("OpenTokenCipher(%s) uses another key", kind)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package mcp
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// Lifetimes of the tokens issued to MCP clients.
+const (
+	accessTokenTTL  = time.Hour
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// errInvalidToken is returned for unknown, expired or revoked tokens.
+var errInvalidToken = errors.New("invalid or expired token")
+
+// errClientMismatch is returned for a refresh token presented by another
+// client than the one it was issued to.
+var errClientMismatch = errors.New("refresh token issued to another client")
+
+// sealedTokenAAD binds the encrypted Google tokens to their use. The key of
+// the entry is appended, so that a token cannot be moved to another entry.
+const sealedTokenAAD = "google-contacts mcp google token v1:"
+
+// vaultGrant is one authorization of a user for an MCP client. The Google
+// token never leaves the server: clients get opaque tokens referring to the
+// grant. It is only stored encrypted (SealedToken).
+type vaultGrant struct {
+	ID            string        `json:"id"`
+	ClientID      string        `json:"client_id"`
+	Subject       string        `json:"subject"` // Email of the Google account
+	EmailVerified bool          `json:"email_verified"`
+	Audience      string        `json:"audience"` // Resource the tokens are valid for (server base URL)
+	Scopes        []string      `json:"scopes"`
+	Refreshable   bool          `json:"refreshable"` // Refresh tokens are issued
+	CreatedAt     time.Time     `json:"created_at"`
+	Expiry        time.Time     `json:"expiry"` // Expiry of the last token issued
+	GoogleToken   *oauth2.Token `json:"-"`
+	SealedToken   []byte        `json:"sealed_google_token"`
+}
+
+// issuedToken is an access or refresh token of a grant, stored by hash.
+type issuedToken struct {
+	GrantID string    `json:"grant_id"`
+	Expiry  time.Time `json:"expiry"`
+}
+
+// issuedTokens are the tokens returned to a client.
+type issuedTokens struct {
+	AccessToken  string
+	RefreshToken string // Empty when the grant is not refreshable
+	Expiry       time.Time
+}
+
+// tokenVault keeps the grants and the tokens issued for them in the OAuth
+// store. Tokens are only kept as hashes and Google tokens are encrypted with
+// AES-256-GCM, so the store content cannot be used as bearer tokens. Entries
+// expire with their tokens: a grant lives as long as the last token issued
+// for it.
+type tokenVault struct {
+	store  OAuthStore
+	cipher cipher.AEAD // Encrypts the Google tokens
+	mu     sync.Mutex  // Serializes Google token refreshes
+}
+
+// newTokenVault returns a vault encrypting the Google tokens with aead, or
+// with a random key when nil (only for stores that do not outlive the
+// process).
+func newTokenVault(store OAuthStore, aead cipher.AEAD) *tokenVault {
+	if aead == nil {
+		key := make([]byte, 32)
+		rand.Read(key)
+		aead, _ = NewTokenCipher(key)
+	}
+	return &tokenVault{store: store, cipher: aead}
+}
+
+// NewTokenCipher returns the AES-256-GCM cipher encrypting the Google tokens
+// in the OAuth store, from a 32-byte key (see auth.GenerateKeyFile).
+func NewTokenCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OAuth store key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealToken encrypts a Google token kept in the store entry key. The nonce
+// is prepended to the ciphertext.
+func (v *tokenVault) sealToken(token *oauth2.Token, key string) ([]byte, error) {
+	plaintext, err := json.Marshal(token)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, v.cipher.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return v.cipher.Seal(nonce, nonce, plaintext, []byte(sealedTokenAAD+key)), nil
+}
+
+// openToken decrypts a Google token sealed for the store entry key. A token
+// that cannot be decrypted (e.g. the key changed) makes the entry invalid.
+func (v *tokenVault) openToken(sealed []byte, key string) (*oauth2.Token, error) {
+	size := v.cipher.NonceSize()
+	if len(sealed) < size {
+		return nil, fmt.Errorf("%w: no encrypted Google token", errInvalidToken)
+	}
+	plaintext, err := v.cipher.Open(nil, sealed[:size], sealed[size:], []byte(sealedTokenAAD+key))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to decrypt the Google token (wrong OAuth store key?)", errInvalidToken)
+	}
+	token := &oauth2.Token{}
+	if err := json.Unmarshal(plaintext, token); err != nil {
+		return nil, fmt.Errorf("unable to read Google token: %w", err)
+	}
+	return token, nil
+}
+
+// putGrant stores a new grant with its Google token encrypted.
+func (v *tokenVault) putGrant(ctx context.Context, g *vaultGrant) error {
+	stored, err := v.sealGrant(g)
+	if err != nil {
+		return err
+	}
+	return v.store.Put(ctx, kindGrants, g.ID, stored, g.Expiry)
+}
+
+// updateGrant saves an existing grant, or returns errInvalidToken when it
+// was revoked since it was loaded: a revocation is never undone.
+func (v *tokenVault) updateGrant(ctx context.Context, g *vaultGrant) error {
+	stored, err := v.sealGrant(g)
+	if err != nil {
+		return err
+	}
+	return vaultError(v.store.Update(ctx, kindGrants, g.ID, stored, g.Expiry))
+}
+
+// sealGrant returns the stored form of a grant, its Google token encrypted.
+func (v *tokenVault) sealGrant(g *vaultGrant) (*vaultGrant, error) {
+	sealed, err := v.sealToken(g.GoogleToken, g.ID)
+	if err != nil {
+		return nil, err
+	}
+	stored := *g
+	stored.SealedToken = sealed
+	return &stored, nil
+}
+
+// tokenHash returns the key of a token in the vault.
+func tokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// issue stores a new grant with its Google token and returns its first
+// tokens.
+func (v *tokenVault) issue(ctx context.Context, g *vaultGrant) (issuedTokens, error) {
+	g.ID = generateSecureToken(16)
+	return v.mint(ctx, g, v.putGrant)
+}
+
+// mint issues new tokens for a grant, extending the grant to their expiry
+// and saving it with save.
+func (v *tokenVault) mint(ctx context.Context, g *vaultGrant, save func(context.Context, *vaultGrant) error) (issuedTokens, error) {
+	now := time.Now()
+	tokens := issuedTokens{AccessToken: generateSecureToken(32), Expiry: now.Add(accessTokenTTL)}
+	refreshExpiry := now.Add(refreshTokenTTL)
+	if g.Refreshable {
+		tokens.RefreshToken = generateSecureToken(32)
+	}
+
+	expiry := tokens.Expiry
+	if g.Refreshable {
+		expiry = refreshExpiry
+	}
+	if expiry.After(g.Expiry) {
+		g.Expiry = expiry
+	}
+	if err := save(ctx, g); err != nil {
+		return issuedTokens{}, err
+	}
+	entry := issuedToken{GrantID: g.ID, Expiry: tokens.Expiry}
+	if err := v.store.Put(ctx, kindAccessTokens, tokenHash(tokens.AccessToken), entry, entry.Expiry); err != nil {
+		return issuedTokens{}, err
+	}
+	if g.Refreshable {
+		entry := issuedToken{GrantID: g.ID, Expiry: refreshExpiry}
+		if err := v.store.Put(ctx, kindRefreshTokens, tokenHash(tokens.RefreshToken), entry, entry.Expiry); err != nil {
+			return issuedTokens{}, err
+		}
+	}
+	return tokens, nil
+}
+
+// lookup returns the grant of a token of kind, or errInvalidToken.
+func (v *tokenVault) lookup(ctx context.Context, kind, token string) (*vaultGrant, error) {
+	var entry issuedToken
+	if err := v.store.Get(ctx, kind, tokenHash(token), &entry); err != nil {
+		return nil, vaultError(err)
+	}
+	return v.grant(ctx, entry.GrantID)
+}
+
+// grant loads a grant, or returns errInvalidToken when it was revoked or
+// has expired.
+func (v *tokenVault) grant(ctx context.Context, id string) (*vaultGrant, error) {
+	var g vaultGrant
+	if err := v.store.Get(ctx, kindGrants, id, &g); err != nil {
+		return nil, vaultError(err)
+	}
+	token, err := v.openToken(g.SealedToken, g.ID)
+	if err != nil {
+		return nil, err
+	}
+	g.GoogleToken, g.SealedToken = token, nil
+	return &g, nil
+}
+
+// access returns the grant of a valid access token.
+func (v *tokenVault) access(ctx context.Context, token string) (*vaultGrant, error) {
+	return v.lookup(ctx, kindAccessTokens, token)
+}
+
+// refresh exchanges a refresh token of clientID for new tokens. Refresh
+// tokens are single use: the presented one is invalidated (rotation).
+func (v *tokenVault) refresh(ctx context.Context, token, clientID string) (*vaultGrant, issuedTokens, error) {
+	g, err := v.lookup(ctx, kindRefreshTokens, token)
+	if err != nil {
+		return nil, issuedTokens{}, err
+	}
+	if g.ClientID != clientID {
+		return nil, issuedTokens{}, errClientMismatch
+	}
+	// Only one of concurrent refreshes gets the token
+	var entry issuedToken
+	if err := v.store.Take(ctx, kindRefreshTokens, tokenHash(token), &entry); err != nil {
+		return nil, issuedTokens{}, vaultError(err)
+	}
+	tokens, err := v.mint(ctx, g, v.updateGrant)
+	if err != nil {
+		return nil, issuedTokens{}, err
+	}
+	return g, tokens, nil
+}
+
+// find returns the grant of an access or refresh token with the token entry
+// and kind, trying the kind of hint first ("access_token" or
+// "refresh_token", RFC 7009 token_type_hint).
+func (v *tokenVault) find(ctx context.Context, token, hint string) (*vaultGrant, issuedToken, string, error) {
+	kinds := []string{kindAccessTokens, kindRefreshTokens}
+	if hint == "refresh_token" {
+		kinds[0], kinds[1] = kinds[1], kinds[0]
+	}
+	for _, kind := range kinds {
+		var entry issuedToken
+		err := v.store.Get(ctx, kind, tokenHash(token), &entry)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, issuedToken{}, "", err
+		}
+		g, err := v.grant(ctx, entry.GrantID)
+		if err != nil {
+			return nil, issuedToken{}, "", err
+		}
+		return g, entry, kind, nil
+	}
+	return nil, issuedToken{}, "", errInvalidToken
+}
+
+// revokeGrant removes a grant. The tokens issued for it are rejected from
+// then on and expire from the store.
+func (v *tokenVault) revokeGrant(ctx context.Context, id string) error {
+	return v.store.Delete(ctx, kindGrants, id)
+}
+
+// googleToken returns a valid Google token for a grant, refreshing it with
+// config when it has expired and saving the refreshed token.
+func (v *tokenVault) googleToken(ctx context.Context, g *vaultGrant, config *oauth2.Config) (*oauth2.Token, error) {
+	if g.GoogleToken.Valid() {
+		return g.GoogleToken, nil
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	// Another request may have refreshed it meanwhile
+	current, err := v.grant(ctx, g.ID)
+	if err != nil {
+		return nil, err
+	}
+	if current.GoogleToken.Valid() {
+		return current.GoogleToken, nil
+	}
+	token, err := config.TokenSource(ctx, current.GoogleToken).Token()
+	if err != nil {
+		return nil, err
+	}
+	current.GoogleToken = token
+	if err := v.updateGrant(ctx, current); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// vaultError maps a missing store entry to errInvalidToken.
+func vaultError(err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return errInvalidToken
+	}
+	return err
+}
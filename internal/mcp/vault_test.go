@@ -0,0 +1,348 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestTokenVault(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	v := newTokenVault(store, nil)
+	grant := &vaultGrant{ClientID: "client", Subject: "jane@example.com", Refreshable: true,
+		GoogleToken: &oauth2.Token{AccessToken: "google-access", RefreshToken: "google-refresh"}}
+	tokens, err := v.issue(ctx, grant)
+	if err != nil || tokens.AccessToken == "" || tokens.RefreshToken == "" || strings.Contains(tokens.AccessToken, "google") {
+		t.Fatalf("issue() = %+v, %v", tokens, err)
+	}
+
+	if g, err := v.access(ctx, tokens.AccessToken); err != nil || g.ID != grant.ID || g.GoogleToken.AccessToken != "google-access" {
+		t.Errorf("access() = %+v, %v", g, err)
+	}
+	if _, err := v.access(ctx, "forged"); !errors.Is(err, errInvalidToken) {
+		t.Errorf("access(forged) error = %v, want errInvalidToken", err)
+	}
+
+	// Refresh tokens are bound to the client and single use
+	if _, _, err := v.refresh(ctx, tokens.RefreshToken, "other-client"); !errors.Is(err, errClientMismatch) {
+		t.Errorf("refresh() by another client error = %v, want errClientMismatch", err)
+	}
+	_, rotated, err := v.refresh(ctx, tokens.RefreshToken, "client")
+	if err != nil || rotated.RefreshToken == tokens.RefreshToken {
+		t.Fatalf("refresh() = %+v, %v", rotated, err)
+	}
+	if _, _, err := v.refresh(ctx, tokens.RefreshToken, "client"); !errors.Is(err, errInvalidToken) {
+		t.Errorf("second refresh() error = %v, want errInvalidToken", err)
+	}
+
+	// Expired access tokens are rejected, the grant lives with its refresh token
+	expired := issuedToken{GrantID: grant.ID, Expiry: time.Now().Add(-time.Minute)}
+	store.Put(ctx, kindAccessTokens, tokenHash(rotated.AccessToken), expired, expired.Expiry)
+	if _, err := v.access(ctx, rotated.AccessToken); !errors.Is(err, errInvalidToken) {
+		t.Errorf("access() after expiry error = %v, want errInvalidToken", err)
+	}
+	_, latest, err := v.refresh(ctx, rotated.RefreshToken, "client")
+	if err != nil {
+		t.Fatalf("refresh() after access expiry error = %v", err)
+	}
+
+	// Revoking the grant invalidates its tokens
+	if err := v.revokeGrant(ctx, grant.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.access(ctx, latest.AccessToken); !errors.Is(err, errInvalidToken) {
+		t.Errorf("access() after revokeGrant error = %v, want errInvalidToken", err)
+	}
+	if _, _, err := v.refresh(ctx, latest.RefreshToken, "client"); !errors.Is(err, errInvalidToken) {
+		t.Errorf("refresh() after revokeGrant error = %v, want errInvalidToken", err)
+	}
+}
+
+func TestTokenVault_EncryptedGoogleToken(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "mcp-oauth.json")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := NewTokenCipher(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := newTokenVault(store, aead)
+	grant := &vaultGrant{ClientID: "client", GoogleToken: &oauth2.Token{AccessToken: "google-access", RefreshToken: "google-refresh"}}
+	tokens, err := v.issue(ctx, grant)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "google-access") || strings.Contains(string(data), "google-refresh") {
+		t.Errorf("Google token stored in plaintext: %s", data)
+	}
+	if g, err := v.access(ctx, tokens.AccessToken); err != nil || g.GoogleToken.RefreshToken != "google-refresh" {
+		t.Errorf("access() = %+v, %v", g, err)
+	}
+
+	// Another key cannot read the grants
+	other, err := NewTokenCipher(bytes.Repeat([]byte{2}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newTokenVault(store, other).access(ctx, tokens.AccessToken); !errors.Is(err, errInvalidToken) {
+		t.Errorf("access() with another key error = %v, want errInvalidToken", err)
+	}
+}
+
+// revokingStore runs revoke before the next Update, as a revocation racing
+// with a refresh would.
+type revokingStore struct {
+	OAuthStore
+	revoke func()
+}
+
+func (s *revokingStore) Update(ctx context.Context, kind, key string, value any, expiry time.Time) error {
+	if revoke := s.revoke; revoke != nil {
+		s.revoke = nil
+		revoke()
+	}
+	return s.OAuthStore.Update(ctx, kind, key, value, expiry)
+}
+
+func TestTokenVault_RevokeDuringRefresh(t *testing.T) {
+	ctx := context.Background()
+	store := &revokingStore{OAuthStore: NewMemoryStore()}
+	v := newTokenVault(store, nil)
+	expired := &oauth2.Token{AccessToken: "google-access", RefreshToken: "google-refresh", Expiry: time.Now().Add(-time.Minute)}
+	grant := &vaultGrant{ClientID: "client", Refreshable: true, GoogleToken: expired}
+	tokens, err := v.issue(ctx, grant)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A grant revoked between the load and the save of a refresh stays revoked
+	store.revoke = func() { v.revokeGrant(ctx, grant.ID) }
+	if _, _, err := v.refresh(ctx, tokens.RefreshToken, "client"); !errors.Is(err, errInvalidToken) {
+		t.Errorf("refresh() during revocation error = %v, want errInvalidToken", err)
+	}
+	if _, err := v.access(ctx, tokens.AccessToken); !errors.Is(err, errInvalidToken) {
+		t.Errorf("access() after revocation error = %v, want errInvalidToken", err)
+	}
+
+	// Same for the save of a refreshed Google token
+	tokens, err = v.issue(ctx, &vaultGrant{ClientID: "client", GoogleToken: expired})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := v.access(ctx, tokens.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	google := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"google-fresh","token_type":"Bearer","expires_in":3600}`)
+	}))
+	defer google.Close()
+	config := &oauth2.Config{Endpoint: oauth2.Endpoint{TokenURL: google.URL}}
+	store.revoke = func() { v.revokeGrant(ctx, g.ID) }
+	if _, err := v.googleToken(ctx, g, config); !errors.Is(err, errInvalidToken) {
+		t.Errorf("googleToken() during revocation error = %v, want errInvalidToken", err)
+	}
+	if _, err := v.access(ctx, tokens.AccessToken); !errors.Is(err, errInvalidToken) {
+		t.Errorf("access() after revocation error = %v, want errInvalidToken", err)
+	}
+}
+
+// testIDToken returns an unsigned ID token with claims.
+func testIDToken(claims map[string]any) string {
+	payload, _ := json.Marshal(claims)
+	return "e30." + base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+}
+
+func TestParseIDToken(t *testing.T) {
+	valid := map[string]any{"iss": "https://accounts.google.com", "aud": "google-client", "sub": "1", "email": "jane@example.com", "email_verified": true}
+
+	tests := []struct {
+		name    string
+		idToken string
+		wantErr bool
+	}{
+		{"valid", testIDToken(valid), false},
+		{"missing", "", true},
+		{"other audience", testIDToken(map[string]any{"iss": "https://accounts.google.com", "aud": "other", "email": "jane@example.com"}), true},
+		{"other issuer", testIDToken(map[string]any{"iss": "https://evil.example.com", "aud": "google-client", "email": "jane@example.com"}), true},
+		{"no email", testIDToken(map[string]any{"iss": "accounts.google.com", "aud": "google-client"}), true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			token := (&oauth2.Token{AccessToken: "at"}).WithExtra(map[string]any{"id_token": tc.idToken})
+			claims, err := parseIDToken(token, "google-client")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseIDToken() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if err == nil && (claims.Email != "jane@example.com" || !claims.EmailVerified) {
+				t.Errorf("parseIDToken() = %+v", claims)
+			}
+		})
+	}
+}
+
+// tokenTestServer returns an OAuth2 server whose Google token endpoint
+// answers with status and body, and an authorization code for client.
+func tokenTestServer(t *testing.T, status int, body string) (*OAuth2Server, string) {
+	return tokenTestServerWithStore(t, status, body, nil)
+}
+
+// tokenTestServerWithStore is tokenTestServer keeping its state in store.
+func tokenTestServerWithStore(t *testing.T, status int, body string, store OAuthStore) (*OAuth2Server, string) {
+	t.Helper()
+	google := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(google.Close)
+
+	// A fixed key, so that the state survives a server restart
+	aead, err := NewTokenCipher(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewOAuth2Server(&OAuth2ServerConfig{BaseURL: "https://mcp.example.com", Store: store, TokenCipher: aead})
+	s.oauthConfig = &oauth2.Config{ClientID: "google-client", Endpoint: oauth2.Endpoint{TokenURL: google.URL}}
+	googleToken := &oauth2.Token{AccessToken: "google-access", RefreshToken: "google-refresh", Expiry: time.Now().Add(-time.Minute)}
+	sealed, err := s.vault.sealToken(googleToken, tokenHash("code"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := &authorizationCode{
+		ClientID:      "client",
+		Scopes:        []string{ScopeContactsRead},
+		SealedToken:   sealed,
+		Subject:       "jane@example.com",
+		EmailVerified: true,
+		CreatedAt:     time.Now(),
+	}
+	if err := s.store.Put(context.Background(), kindCodes, tokenHash("code"), code, time.Now().Add(authorizationTTL)); err != nil {
+		t.Fatal(err)
+	}
+	registerClients(t, s, "client")
+	return s, "code"
+}
+
+// postToken calls the token endpoint and decodes the response.
+func postToken(t *testing.T, s *OAuth2Server, form url.Values) (int, TokenResponse) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	s.HandleToken(rec, req)
+	var resp TokenResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	return rec.Code, resp
+}
+
+func TestHandleToken_OpaqueTokens(t *testing.T) {
+	s, code := tokenTestServer(t, http.StatusOK, `{"access_token":"google-fresh","token_type":"Bearer","expires_in":3600}`)
+
+	status, resp := postToken(t, s, url.Values{"grant_type": {"authorization_code"}, "code": {code}, "client_id": {"client"}})
+	if status != http.StatusOK {
+		t.Fatalf("token status = %d", status)
+	}
+	if resp.AccessToken == "" || strings.HasPrefix(resp.AccessToken, "google") || strings.HasPrefix(resp.RefreshToken, "google") {
+		t.Fatalf("token response leaks or lacks tokens: %+v", resp)
+	}
+	if resp.Scope != ScopeContactsRead || resp.ExpiresIn <= 0 {
+		t.Errorf("token response = %+v", resp)
+	}
+
+	// The access token maps to the refreshed Google token server-side
+	validated, err := s.ValidateAccessToken(context.Background(), resp.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if validated.ClientID != "client" || validated.Subject != "jane@example.com" || validated.GoogleToken.AccessToken != "google-fresh" {
+		t.Errorf("ValidateAccessToken() = %+v", validated)
+	}
+	if _, err := s.ValidateAccessToken(context.Background(), "google-access"); !errors.Is(err, errInvalidToken) {
+		t.Errorf("ValidateAccessToken(Google token) error = %v, want errInvalidToken", err)
+	}
+
+	// Refresh rotates the tokens
+	status, refreshed := postToken(t, s, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {resp.RefreshToken}, "client_id": {"client"}})
+	if status != http.StatusOK || refreshed.AccessToken == resp.AccessToken || refreshed.RefreshToken == resp.RefreshToken {
+		t.Errorf("refresh = %d, %+v", status, refreshed)
+	}
+	if status, _ := postToken(t, s, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {resp.RefreshToken}, "client_id": {"client"}}); status != http.StatusBadRequest {
+		t.Errorf("reused refresh token status = %d, want 400", status)
+	}
+}
+
+func TestHandleToken_ClientAuthentication(t *testing.T) {
+	s, code := tokenTestServer(t, http.StatusOK, `{"access_token":"google-fresh","token_type":"Bearer","expires_in":3600}`)
+	client := registeredClient{ClientID: "client", SecretHash: tokenHash("s3cret")}
+	if err := s.store.Put(context.Background(), kindClients, "client", client, time.Now().Add(clientTTL)); err != nil {
+		t.Fatal(err)
+	}
+
+	form := url.Values{"grant_type": {"authorization_code"}, "code": {code}}
+	if rec := postForm(s.HandleToken, form, "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("token without client_id status = %d, want 401", rec.Code)
+	}
+	if rec := postForm(s.HandleToken, form, "client", "wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("token with wrong secret status = %d, want 401", rec.Code)
+	}
+	rec := postForm(s.HandleToken, form, "client", "s3cret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("token with secret = %d: %s", rec.Code, rec.Body)
+	}
+	var tokens TokenResponse
+	json.NewDecoder(rec.Body).Decode(&tokens)
+
+	// The secret is also required to refresh
+	refresh := url.Values{"grant_type": {"refresh_token"}, "refresh_token": {tokens.RefreshToken}, "client_id": {"client"}}
+	if status, _ := postToken(t, s, refresh); status != http.StatusUnauthorized {
+		t.Errorf("refresh without secret status = %d, want 401", status)
+	}
+	if rec := postForm(s.HandleToken, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {tokens.RefreshToken}}, "client", "s3cret"); rec.Code != http.StatusOK {
+		t.Errorf("refresh with secret = %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestHandleToken_InvalidTarget(t *testing.T) {
+	s, code := tokenTestServer(t, http.StatusOK, `{}`)
+	form := url.Values{"grant_type": {"authorization_code"}, "code": {code}, "resource": {"https://other.example.com"}}
+	if status, _ := postToken(t, s, form); status != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", status)
+	}
+}
+
+func TestValidateAccessToken_RevokedGoogleGrant(t *testing.T) {
+	s, code := tokenTestServer(t, http.StatusBadRequest, `{"error":"invalid_grant","error_description":"Token has been expired or revoked."}`)
+	_, resp := postToken(t, s, url.Values{"grant_type": {"authorization_code"}, "code": {code}, "client_id": {"client"}})
+
+	if _, err := s.ValidateAccessToken(context.Background(), resp.AccessToken); !errors.Is(err, errInvalidToken) {
+		t.Fatalf("ValidateAccessToken() error = %v, want errInvalidToken", err)
+	}
+	if _, err := s.ValidateAccessToken(context.Background(), resp.AccessToken); !errors.Is(err, errInvalidToken) || strings.Contains(err.Error(), "revoked") {
+		t.Errorf("grant kept after Google revocation: %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+// Package trash keeps the complete state of deleted contacts in a local
+// store, so that deletions can be listed, restored and eventually purged.
+package trash
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google-contacts/internal/contacts"
+)
+
+// Item is a deleted contact kept in the trash. Owner is the email address of
+// the account the contact was deleted from, empty if it could not be read.
+type Item struct {
+	ID           string                  `json:"id"`
+	DeletedAt    time.Time               `json:"deletedAt"`
+	ResourceName string                  `json:"resourceName"`
+	DisplayName  string                  `json:"displayName"`
+	Owner        string                  `json:"owner,omitempty"`
+	Source       string                  `json:"source,omitempty"`
+	Archive      contacts.ContactArchive `json:"archive"`
+}
+
+// Store keeps trash items as one JSON file per item in Dir.
+// The files contain personal data and are written with 0600 permissions.
+type Store struct {
+	Dir string
+}
+
+// DefaultDir returns the trash location in the user config directory.
+func DefaultDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "google-contacts", "trash"), nil
+}
+
+// MoveToTrash archives a contact into the store, then deletes it. The item
+// is dropped again if the deletion fails. source tells which interface
+// deleted the contact (e.g. "cli", "mcp").
+func (s *Store) MoveToTrash(ctx context.Context, srv *contacts.Service, resourceName, source string) (Item, error) {
+	archive, err := srv.ArchiveContact(ctx, resourceName)
+	if err != nil {
+		return Item{}, fmt.Errorf("failed to archive contact: %w", err)
+	}
+
+	// The owner protects restores across accounts; it is not worth failing the deletion
+	owner, _ := srv.AccountEmail(ctx)
+
+	item, err := s.Add(Item{
+		ResourceName: archive.Person.ResourceName,
+		DisplayName:  archive.Details().DisplayName,
+		Owner:        owner,
+		Source:       source,
+		Archive:      *archive,
+	})
+	if err != nil {
+		return Item{}, err
+	}
+
+	if err := srv.DeleteContact(ctx, archive.Person.ResourceName); err != nil {
+		if rmErr := s.Remove(item.ID); rmErr != nil {
+			return Item{}, fmt.Errorf("%w (and %v)", err, rmErr)
+		}
+		return Item{}, err
+	}
+	return item, nil
+}
+
+// Add stores an item, assigning its ID and deletion time when unset.
+func (s *Store) Add(item Item) (Item, error) {
+	if item.ID == "" {
+		b := make([]byte, 4)
+		if _, err := rand.Read(b); err != nil {
+			return Item{}, fmt.Errorf("failed to generate trash ID: %w", err)
+		}
+		item.ID = hex.EncodeToString(b)
+	}
+	if item.DeletedAt.IsZero() {
+		item.DeletedAt = time.Now()
+	}
+
+	if err := os.MkdirAll(s.Dir, 0700); err != nil {
+		return Item{}, fmt.Errorf("failed to create trash directory: %w", err)
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return Item{}, fmt.Errorf("failed to encode trash item: %w", err)
+	}
+	path := s.path(item.ID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return Item{}, fmt.Errorf("failed to write trash item: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return Item{}, fmt.Errorf("failed to write trash item: %w", err)
+	}
+	return item, nil
+}
+
+// List returns the items in the trash, most recently deleted first.
+// A missing trash is empty.
+func (s *Store) List() ([]Item, error) {
+	files, err := filepath.Glob(filepath.Join(s.Dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+
+	var items []Item
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read trash item: %w", err)
+		}
+		var item Item
+		if err := json.Unmarshal(data, &item); err != nil {
+			return nil, fmt.Errorf("failed to read trash item %s: %w", filepath.Base(file), err)
+		}
+		items = append(items, item)
+	}
+
+	slices.SortFunc(items, func(a, b Item) int {
+		return b.DeletedAt.Compare(a.DeletedAt)
+	})
+	return items, nil
+}
+
+// Find returns the item designated by ref: a trash ID, an unambiguous
+// prefix of one, or the ID of the deleted contact (the most recent
+// deletion wins when a contact was deleted several times).
+func Find(items []Item, ref string) (Item, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return Item{}, fmt.Errorf("trash ID is required")
+	}
+
+	var matches []Item
+	for _, item := range items {
+		if item.ID == ref || item.ResourceName == ref || item.ResourceName == "people/"+ref {
+			return item, nil
+		}
+		if strings.HasPrefix(item.ID, ref) {
+			matches = append(matches, item)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Item{}, fmt.Errorf("no deleted contact %q in the trash", ref)
+	case 1:
+		return matches[0], nil
+	}
+	return Item{}, fmt.Errorf("trash ID %q is ambiguous (%d matches)", ref, len(matches))
+}
+
+// Remove deletes an item from the trash.
+func (s *Store) Remove(id string) error {
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove trash item: %w", err)
+	}
+	return nil
+}
+
+// Purge permanently removes the items deleted before the given time and
+// returns them.
+func (s *Store) Purge(before time.Time) ([]Item, error) {
+	items, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var purged []Item
+	for _, item := range items {
+		if !item.DeletedAt.Before(before) {
+			continue
+		}
+		if err := s.Remove(item.ID); err != nil {
+			return purged, err
+		}
+		purged = append(purged, item)
+	}
+	return purged, nil
+}
+
+// path returns the file of an item.
+func (s *Store) path(id string) string {
+	return filepath.Join(s.Dir, id+".json")
+}
+
+// ParseAge parses a purge age: a number of days ("30d"), weeks ("2w") or a
+// Go duration ("12h").
+func ParseAge(age string) (time.Duration, error) {
+	age = strings.TrimSpace(age)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(age, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil || count < 0 {
+				return 0, fmt.Errorf("invalid age '%s', expected e.g. 30d, 2w or 12h", age)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(age)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age '%s', expected e.g. 30d, 2w or 12h", age)
+	}
+	return d, nil
+}
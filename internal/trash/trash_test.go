@@ -0,0 +1,142 @@
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	people "google.golang.org/api/people/v1"
+
+	"google-contacts/internal/contacts"
+)
+
+func newItem(id, resourceName string, deletedAt time.Time) Item {
+	return Item{
+		ID:           id,
+		DeletedAt:    deletedAt,
+		ResourceName: resourceName,
+		Archive: contacts.ContactArchive{
+			Person: &people.Person{ResourceName: resourceName},
+			Photo:  []byte{0xff, 0xd8},
+		},
+	}
+}
+
+func TestStore(t *testing.T) {
+	s := &Store{Dir: filepath.Join(t.TempDir(), "trash")}
+
+	items, err := s.List()
+	if err != nil || len(items) != 0 {
+		t.Fatalf("List() on missing trash = %v, %v; want empty", items, err)
+	}
+
+	now := time.Now()
+	old, err := s.Add(newItem("", "people/c1", now.Add(-40*24*time.Hour)))
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if old.ID == "" {
+		t.Error("Add() did not assign an ID")
+	}
+	if _, err := s.Add(newItem("bbbb0000", "people/c2", now)); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(s.Dir, "bbbb0000.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("trash item permissions = %o, want 600", info.Mode().Perm())
+	}
+
+	items, err = s.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(items) != 2 || items[0].ID != "bbbb0000" || items[1].ID != old.ID {
+		t.Fatalf("List() = %+v, want newest first", items)
+	}
+	if string(items[0].Archive.Photo) != "\xff\xd8" {
+		t.Errorf("photo = %x, want ffd8", items[0].Archive.Photo)
+	}
+
+	purged, err := s.Purge(now.Add(-30 * 24 * time.Hour))
+	if err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if len(purged) != 1 || purged[0].ID != old.ID {
+		t.Errorf("Purge() = %+v, want the old item", purged)
+	}
+
+	if err := s.Remove("bbbb0000"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if items, _ := s.List(); len(items) != 0 {
+		t.Errorf("List() after Remove() = %+v, want empty", items)
+	}
+}
+
+func TestFind(t *testing.T) {
+	now := time.Now()
+	items := []Item{
+		newItem("ab12cd34", "people/c1", now),
+		newItem("ab99ef00", "people/c2", now),
+		newItem("ff000000", "people/c1", now.Add(-time.Hour)),
+	}
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{ref: "ab12cd34", want: "ab12cd34"},
+		{ref: "ff", want: "ff000000"},
+		{ref: "c1", want: "ab12cd34"},
+		{ref: "people/c2", want: "ab99ef00"},
+		{ref: "ab", wantErr: true},
+		{ref: "c3", wantErr: true},
+		{ref: "", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.ref, func(t *testing.T) {
+			got, err := Find(items, tc.ref)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Find(%q) error = %v, wantErr %v", tc.ref, err, tc.wantErr)
+			}
+			if got.ID != tc.want {
+				t.Errorf("Find(%q) = %q, want %q", tc.ref, got.ID, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		age     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{age: "30d", want: 30 * 24 * time.Hour},
+		{age: "2w", want: 14 * 24 * time.Hour},
+		{age: "12h", want: 12 * time.Hour},
+		{age: "0d", want: 0},
+		{age: "xd", wantErr: true},
+		{age: "-1d", wantErr: true},
+		{age: "soon", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.age, func(t *testing.T) {
+			got, err := ParseAge(tc.age)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseAge(%q) error = %v, wantErr %v", tc.age, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("ParseAge(%q) = %v, want %v", tc.age, got, tc.want)
+			}
+		})
+	}
+}
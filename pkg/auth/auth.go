@@ -1,18 +1,18 @@
 // Package auth provides OAuth2 authentication for Google APIs.
-// This package contains unified scopes for both Gmail and People APIs,
-// enabling a single OAuth consent for multiple applications.
+// The requested scopes are selected by scope set (see ScopeSets): the
+// unified set covers both Gmail and People APIs, enabling a single OAuth
+// consent for multiple applications, the contacts sets only what
+// google-contacts needs.
 package auth
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"runtime"
-	"time"
+	"regexp"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -25,9 +25,12 @@ type contextKey string
 
 // Context keys for storing authentication data.
 const (
-	refreshTokenKey contextKey = "refresh_token"
-	oauthConfigKey  contextKey = "oauth_config"
-	accessTokenKey  contextKey = "access_token"
+	refreshTokenKey   contextKey = "refresh_token"
+	oauthConfigKey    contextKey = "oauth_config"
+	accessTokenKey    contextKey = "access_token"
+	nonInteractive    contextKey = "non_interactive"
+	profileKey        contextKey = "profile"
+	serviceAccountKey contextKey = "service_account"
 )
 
 // WithRefreshToken returns a new context with the refresh token stored.
@@ -66,6 +69,18 @@ func GetAccessTokenFromContext(ctx context.Context) (*oauth2.Token, bool) {
 	return token, ok
 }
 
+// WithNonInteractive returns a new context that disables the browser OAuth flow.
+// This is used by server endpoints that must fail instead of waiting for user consent.
+func WithNonInteractive(ctx context.Context) context.Context {
+	return context.WithValue(ctx, nonInteractive, true)
+}
+
+// IsNonInteractive reports whether the browser OAuth flow is disabled for this context.
+func IsNonInteractive(ctx context.Context) bool {
+	disabled, _ := ctx.Value(nonInteractive).(bool)
+	return disabled
+}
+
 const (
 	// CredentialsFile is the name of the OAuth credentials file.
 	CredentialsFile = "google_credentials.json"
@@ -73,9 +88,9 @@ const (
 	TokenFile = "google_token.json"
 )
 
-// Scopes contains all OAuth2 scopes for Gmail and People APIs.
-// These unified scopes enable a single OAuth consent for both email-manager
-// and google-contacts applications, using the same token file.
+// Scopes contains all OAuth2 scopes for Gmail and People APIs (the unified
+// scope set). These unified scopes enable a single OAuth consent for both
+// email-manager and google-contacts applications, using the same token file.
 var Scopes = []string{
 	// Gmail API scopes (for email-manager)
 	gmail.GmailModifyScope,
@@ -86,8 +101,20 @@ var Scopes = []string{
 	people.ContactsOtherReadonlyScope,
 }
 
+// credentialsDir overrides the default credentials directory when set.
+var credentialsDir string
+
+// SetCredentialsPath sets the credentials directory, replacing ~/.credentials.
+// An empty path restores the default.
+func SetCredentialsPath(dir string) {
+	credentialsDir = dir
+}
+
 // GetCredentialsPath returns the path to the credentials directory.
 func GetCredentialsPath() string {
+	if credentialsDir != "" {
+		return credentialsDir
+	}
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return ""
@@ -95,11 +122,128 @@ func GetCredentialsPath() string {
 	return filepath.Join(home, ".credentials")
 }
 
+// DefaultProfile is the profile using the token stored directly in the
+// credentials directory.
+const DefaultProfile = "default"
+
+// profile is the profile used when the context does not select one.
+var profile = DefaultProfile
+
+// profileNamePattern restricts profile names to safe directory names.
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ValidateProfile checks that a profile name can be used as a directory name.
+func ValidateProfile(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name '%s': use letters, digits, '.', '_' or '-'", name)
+	}
+	return nil
+}
+
+// SetProfile sets the profile used when the context does not select one.
+func SetProfile(name string) error {
+	if err := ValidateProfile(name); err != nil {
+		return err
+	}
+	profile = name
+	return nil
+}
+
+// CurrentProfile returns the profile used when the context does not select one.
+func CurrentProfile() string {
+	return profile
+}
+
+// WithProfile returns a new context selecting a profile, so that one
+// process can address several accounts.
+func WithProfile(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, profileKey, name)
+}
+
+// ProfileFromContext returns the profile selected by the context, or the
+// current profile.
+func ProfileFromContext(ctx context.Context) string {
+	if name, ok := ctx.Value(profileKey).(string); ok && name != "" {
+		return name
+	}
+	return profile
+}
+
+// ProfileDir returns the directory of a profile: the credentials directory
+// for the default profile, profiles/<name> inside it otherwise.
+func ProfileDir(name string) string {
+	if name == DefaultProfile {
+		return GetCredentialsPath()
+	}
+	return filepath.Join(GetCredentialsPath(), "profiles", name)
+}
+
+// TokenPath returns the token file of a profile.
+func TokenPath(name string) string {
+	return filepath.Join(ProfileDir(name), TokenFile)
+}
+
+// CredentialsPath returns the OAuth client credentials file of a profile:
+// its own google_credentials.json if present, the shared one otherwise.
+func CredentialsPath(name string) string {
+	own := filepath.Join(ProfileDir(name), CredentialsFile)
+	if _, err := os.Stat(own); err == nil {
+		return own
+	}
+	return filepath.Join(GetCredentialsPath(), CredentialsFile)
+}
+
+// ListProfiles returns the default profile and the profiles found in the
+// credentials directory, sorted by name after the default one.
+func ListProfiles() ([]string, error) {
+	profiles := []string{DefaultProfile}
+	entries, err := os.ReadDir(filepath.Join(GetCredentialsPath(), "profiles"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() && e.Name() != DefaultProfile && ValidateProfile(e.Name()) == nil {
+			profiles = append(profiles, e.Name())
+		}
+	}
+	return profiles, nil
+}
+
+// LoadOAuthConfig reads the OAuth client credentials of a profile, requesting
+// the scopes of the current scope set.
+func LoadOAuthConfig(name string) (*oauth2.Config, error) {
+	credPath := CredentialsPath(name)
+	b, err := os.ReadFile(credPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read credentials file %s: %w", credPath, err)
+	}
+
+	scopes, err := ScopesFor(scopeSet)
+	if err != nil {
+		return nil, err
+	}
+	config, err := google.ConfigFromJSON(b, scopes...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse credentials: %w", err)
+	}
+	// The client file does not carry the device authorization endpoint
+	if config.Endpoint.DeviceAuthURL == "" {
+		config.Endpoint.DeviceAuthURL = google.Endpoint.DeviceAuthURL
+	}
+	return config, nil
+}
+
 // GetClient returns an HTTP client with OAuth2 authentication.
 // Authentication sources are checked in order:
 // 1. OAuth config from context + access token from context (MCP server mode with access token)
 // 2. OAuth config from context + refresh token from context (MCP server mode)
-// 3. Local credentials file + local token file (CLI mode)
+// 3. Service account + impersonated user from context (MCP server mode)
+// 4. Service account + impersonated user set by SetImpersonation (CLI mode)
+// 5. Local credentials file + token of the profile in the token store (CLI mode)
+//
+// Calls rejected for insufficient scope fail with a ScopeError. In
+// interactive CLI mode, the user is first asked to grant the missing scopes
+// (incremental authorization) and the call is retried.
 func GetClient(ctx context.Context) (*http.Client, error) {
 	var config *oauth2.Config
 	var err error
@@ -122,148 +266,52 @@ func GetClient(ctx context.Context) (*http.Client, error) {
 		}
 	}
 
-	// Fall back to loading from credentials file (CLI mode)
-	credPath := filepath.Join(GetCredentialsPath(), CredentialsFile)
-	b, err := os.ReadFile(credPath)
-	if err != nil {
-		return nil, fmt.Errorf("unable to read credentials file %s: %w", credPath, err)
+	// Domain-wide delegation (MCP server mode, then CLI mode)
+	if sa, user, ok := GetServiceAccountFromContext(ctx); ok {
+		return sa.Client(ctx, user)
+	}
+	if serviceAccount != nil {
+		return serviceAccount.Client(ctx, subject)
 	}
 
-	config, err = google.ConfigFromJSON(b, Scopes...)
+	// Fall back to loading from credentials file (CLI mode)
+	name := ProfileFromContext(ctx)
+	config, err = LoadOAuthConfig(name)
 	if err != nil {
-		return nil, fmt.Errorf("unable to parse credentials: %w", err)
+		return nil, err
 	}
 
-	// Fall back to token from file (CLI mode only)
-	tokenPath := filepath.Join(GetCredentialsPath(), TokenFile)
-	token, err := tokenFromFile(tokenPath)
+	// Fall back to token from the token store (CLI mode only)
+	token, err := LoadToken(name)
 	if err != nil {
-		token, err = getTokenFromWeb(config)
-		if err != nil {
+		if !errors.Is(err, ErrNotLoggedIn) {
 			return nil, err
 		}
-		if err := saveToken(tokenPath, token); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: unable to save token: %v\n", err)
+		if IsNonInteractive(ctx) {
+			return nil, fmt.Errorf("no token found at %s: run 'google-contacts auth login --profile %s' to authenticate", TokenLocation(name), name)
 		}
-	}
-
-	return config.Client(ctx, token), nil
-}
-
-func getTokenFromWeb(config *oauth2.Config) (*oauth2.Token, error) {
-	// Use localhost with configured port
-	config.RedirectURL = "http://localhost:8080/oauth2callback"
-
-	// Create channels for communication
-	codeChan := make(chan string)
-	errChan := make(chan error)
-
-	// Start local HTTP server
-	server := &http.Server{Addr: ":8080"}
-	http.HandleFunc("/oauth2callback", func(w http.ResponseWriter, r *http.Request) {
-		code := r.URL.Query().Get("code")
-		if code == "" {
-			errChan <- fmt.Errorf("no code in callback")
-			return
+		// Prompts go to stderr so that they do not mix with the command output
+		token, err = Login(ctx, config, LoginOptions{Mode: LoginBrowser, In: os.Stdin, Out: os.Stderr})
+		if err != nil {
+			return nil, err
 		}
-
-		// Send success message to browser
-		w.Header().Set("Content-Type", "text/html")
-		fmt.Fprintf(w, `
-			<html>
-			<body>
-				<h1>Authentication successful!</h1>
-				<p>You can close this window and return to the terminal.</p>
-			</body>
-			</html>
-		`)
-
-		codeChan <- code
-	})
-
-	// Start server in background
-	go func() {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			// Ignore server closed error
-			if err != http.ErrServerClosed {
-				errChan <- err
-			}
+		if err := SaveLoginToken(name, token); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: unable to save token: %v\n", err)
 		}
-	}()
-
-	// Wait a moment for server to start
-	time.Sleep(100 * time.Millisecond)
-
-	// Generate auth URL
-	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
-	fmt.Printf("Opening browser for authentication...\n")
-	fmt.Printf("If browser doesn't open, visit:\n%v\n\n", authURL)
-
-	// Try to open browser automatically
-	var cmd *exec.Cmd
-	switch runtime.GOOS {
-	case "darwin":
-		cmd = exec.Command("open", authURL)
-	case "linux":
-		cmd = exec.Command("xdg-open", authURL)
-	case "windows":
-		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", authURL)
-	}
-
-	if cmd != nil {
-		_ = cmd.Start()
-	}
-
-	// Wait for auth code or error
-	var code string
-	select {
-	case code = <-codeChan:
-		// Success
-	case err := <-errChan:
-		return nil, err
-	case <-time.After(3 * time.Minute):
-		return nil, fmt.Errorf("authentication timeout after 3 minutes")
 	}
 
-	// Shutdown server
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	_ = server.Shutdown(ctx)
-
-	// Exchange code for token
-	tok, err := config.Exchange(context.Background(), code)
-	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve token from web: %w", err)
+	// Refreshed tokens are saved back to the token store
+	client := oauth2.NewClient(ctx, newStoreTokenSource(ctx, config, name, token))
+	transport := &scopeTransport{base: client.Transport, profile: name, scopeSet: writeScopeSet(scopeSet)}
+	if !IsNonInteractive(ctx) {
+		transport.reauthorize = incrementalAuthorization(ctx, config, name, transport.scopeSet)
 	}
-
-	fmt.Println("\nAuthentication successful!")
-	return tok, nil
+	client.Transport = transport
+	return client, nil
 }
 
-func tokenFromFile(file string) (*oauth2.Token, error) {
-	f, err := os.Open(file)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-
-	token := &oauth2.Token{}
-	err = json.NewDecoder(f).Decode(token)
-	return token, err
-}
-
-func saveToken(path string, token *oauth2.Token) error {
-	fmt.Fprintf(os.Stderr, "Saving credentials to: %s\n", path)
-
-	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
-		return err
-	}
-
-	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	return json.NewEncoder(f).Encode(token)
+// withScopeCheck makes a client fail with a ScopeError on insufficient scope.
+func withScopeCheck(client *http.Client) *http.Client {
+	client.Transport = &scopeTransport{base: client.Transport}
+	return client
 }
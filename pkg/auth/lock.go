@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Token files are shared with email-manager and other invocations, so they
+// are only replaced while holding an exclusive lock on a <file>.lock file
+// next to them. The lock file is left in place: removing it would let two
+// processes lock different files.
+const (
+	lockSuffix       = ".lock"
+	lockTimeout      = 10 * time.Second
+	lockPollInterval = 50 * time.Millisecond
+)
+
+// errLocked is returned by tryLock when another process holds the lock.
+var errLocked = errors.New("file is locked")
+
+// lockTokenFile takes the exclusive lock of a token file, waiting up to
+// lockTimeout for other processes to release it.
+func lockTokenFile(path string) (unlock func(), err error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path+lockSuffix, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("unable to lock token: %w", err)
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		err = tryLock(f)
+		if err == nil {
+			return func() {
+				unlockFile(f)
+				f.Close()
+			}, nil
+		}
+		if !errors.Is(err, errLocked) || time.Now().After(deadline) {
+			f.Close()
+			if errors.Is(err, errLocked) {
+				return nil, fmt.Errorf("unable to lock token: %s is held by another process", path+lockSuffix)
+			}
+			return nil, fmt.Errorf("unable to lock token: %w", err)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// replaceFile atomically replaces path with data (0600): readers see either
+// the previous file or the new one, never a partial write.
+func replaceFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,14 @@
+//go:build !unix && !windows
+
+package auth
+
+import "os"
+
+// tryLock does not lock on platforms without file locks; token files are
+// still replaced atomically.
+func tryLock(f *os.File) error {
+	return nil
+}
+
+// unlockFile releases the lock taken by tryLock.
+func unlockFile(f *os.File) {}
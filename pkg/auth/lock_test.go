@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestLockTokenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles", "work", TokenFile)
+	unlock, err := lockTokenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A second lock waits for the first one to be released
+	locked := make(chan struct{})
+	go func() {
+		unlock, err := lockTokenFile(path)
+		if err != nil {
+			t.Error(err)
+		} else {
+			unlock()
+		}
+		close(locked)
+	}()
+	select {
+	case <-locked:
+		t.Fatal("second lock taken while the first one is held")
+	case <-time.After(200 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case <-locked:
+	case <-time.After(lockTimeout):
+		t.Fatal("second lock not taken after release")
+	}
+}
+
+func TestWriteTokenFile_Concurrent(t *testing.T) {
+	dir := t.TempDir()
+	SetCredentialsPath(dir)
+	defer SetCredentialsPath("")
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token := &oauth2.Token{AccessToken: fmt.Sprintf("access-%d", i), RefreshToken: strings.Repeat("r", 1000*i)}
+			if err := SaveToken(DefaultProfile, token); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The file holds one complete token and no temporary file is left
+	token, err := LoadToken(DefaultProfile)
+	if err != nil || !strings.HasPrefix(token.AccessToken, "access-") {
+		t.Fatalf("LoadToken() = %+v, %v", token, err)
+	}
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if e.Name() != TokenFile && e.Name() != TokenFile+lockSuffix {
+			t.Errorf("unexpected file %s", e.Name())
+		}
+	}
+}
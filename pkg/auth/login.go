@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// LoginMode selects how the user grants access.
+type LoginMode string
+
+// Login modes.
+const (
+	// LoginBrowser opens the browser on the consent page and receives the
+	// code on a loopback listener.
+	LoginBrowser LoginMode = "browser"
+	// LoginNoBrowser prints the consent URL; the code is received on the
+	// loopback listener, or pasted back from the browser address bar when
+	// the browser runs on another machine.
+	LoginNoBrowser LoginMode = "no-browser"
+	// LoginDevice uses the OAuth device authorization flow (RFC 8628): the
+	// user enters a short code on another device. The OAuth client must be
+	// of type "TVs and Limited Input devices".
+	LoginDevice LoginMode = "device"
+)
+
+// IncludeGrantedScopes asks Google to add the scopes granted earlier to the
+// new token, so that a narrower request does not drop them.
+var IncludeGrantedScopes = oauth2.SetAuthURLParam("include_granted_scopes", "true")
+
+// loginTimeout bounds the time given to the user to grant access.
+const loginTimeout = 5 * time.Minute
+
+// LoginOptions configures Login. In is read for the pasted redirect URL in
+// no-browser mode, Out receives the instructions.
+type LoginOptions struct {
+	Mode LoginMode
+	In   io.Reader
+	Out  io.Writer
+}
+
+// Login runs the OAuth flow of the given mode and returns the new token.
+func Login(ctx context.Context, config *oauth2.Config, opts LoginOptions) (*oauth2.Token, error) {
+	ctx, cancel := context.WithTimeout(ctx, loginTimeout)
+	defer cancel()
+
+	switch opts.Mode {
+	case LoginDevice:
+		return deviceLogin(ctx, config, opts.Out)
+	case LoginBrowser, LoginNoBrowser, "":
+		return loopbackLogin(ctx, config, opts)
+	}
+	return nil, fmt.Errorf("unknown login mode %q", opts.Mode)
+}
+
+// deviceLogin runs the device authorization flow.
+func deviceLogin(ctx context.Context, config *oauth2.Config, out io.Writer) (*oauth2.Token, error) {
+	resp, err := config.DeviceAuth(ctx, oauth2.AccessTypeOffline)
+	if err != nil {
+		return nil, fmt.Errorf("unable to start device authorization: %w", err)
+	}
+
+	fmt.Fprintf(out, "On any device, visit:\n\n  %s\n\nand enter the code: %s\n\n", resp.VerificationURI, resp.UserCode)
+	fmt.Fprintln(out, "Waiting for authorization...")
+
+	token, err := config.DeviceAccessToken(ctx, resp)
+	if err != nil {
+		return nil, fmt.Errorf("device authorization failed: %w", err)
+	}
+	fmt.Fprintln(out, "\nAuthentication successful!")
+	return token, nil
+}
+
+// callbackResult is the outcome of the authorization redirect.
+type callbackResult struct {
+	code string
+	err  error
+}
+
+// loopbackLogin runs the authorization code flow with PKCE, receiving the
+// code on a random loopback port (RFC 8252).
+func loopbackLogin(ctx context.Context, config *oauth2.Config, opts LoginOptions) (*oauth2.Token, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("unable to start the local callback server: %w", err)
+	}
+
+	cfg := *config
+	cfg.RedirectURL = fmt.Sprintf("http://127.0.0.1:%d/oauth2callback", listener.Addr().(*net.TCPAddr).Port)
+
+	state, err := randomState()
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+	verifier := oauth2.GenerateVerifier()
+	// Scopes granted earlier are kept (incremental authorization)
+	authURL := cfg.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(verifier), IncludeGrantedScopes)
+
+	results := make(chan callbackResult, 2)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2callback", func(w http.ResponseWriter, r *http.Request) {
+		code, err := callbackCode(r.URL.Query(), state)
+		w.Header().Set("Content-Type", "text/html")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "<html><body><h1>Authentication failed</h1><p>%s</p></body></html>", template.HTMLEscapeString(err.Error()))
+		} else {
+			fmt.Fprint(w, "<html><body><h1>Authentication successful!</h1><p>You can close this window and return to the terminal.</p></body></html>")
+		}
+		// Later requests (e.g. a reload) are answered but ignored
+		select {
+		case results <- callbackResult{code, err}:
+		default:
+		}
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			results <- callbackResult{err: err}
+		}
+	}()
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	if opts.Mode == LoginNoBrowser {
+		fmt.Fprintf(opts.Out, "Open this URL in a browser:\n\n  %s\n\n", authURL)
+		fmt.Fprintln(opts.Out, "After approving, the browser is redirected to 127.0.0.1. If it runs on another")
+		fmt.Fprintln(opts.Out, "machine, the page fails to load: copy the full URL from its address bar and paste it here.")
+		fmt.Fprint(opts.Out, "\nRedirect URL: ")
+		go func() {
+			line, err := bufio.NewReader(opts.In).ReadString('\n')
+			if err != nil && strings.TrimSpace(line) == "" {
+				// No terminal input: keep waiting for the loopback callback
+				return
+			}
+			code, err := redirectCode(line, state)
+			results <- callbackResult{code, err}
+		}()
+	} else {
+		fmt.Fprintf(opts.Out, "Opening browser for authentication...\n")
+		fmt.Fprintf(opts.Out, "If browser doesn't open, visit:\n%v\n\n", authURL)
+		openBrowser(authURL)
+	}
+
+	var code string
+	select {
+	case result := <-results:
+		if result.err != nil {
+			return nil, result.err
+		}
+		code = result.code
+	case <-ctx.Done():
+		return nil, fmt.Errorf("authentication timeout after %s", loginTimeout)
+	}
+
+	token, err := cfg.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve token from web: %w", err)
+	}
+	fmt.Fprintln(opts.Out, "\nAuthentication successful!")
+	return token, nil
+}
+
+// callbackCode returns the authorization code of a redirect, checking the
+// state against the one sent in the authorization request.
+func callbackCode(query url.Values, state string) (string, error) {
+	if errCode := query.Get("error"); errCode != "" {
+		return "", fmt.Errorf("authorization denied: %s", errCode)
+	}
+	if query.Get("state") != state {
+		return "", fmt.Errorf("invalid state in the authorization response")
+	}
+	code := query.Get("code")
+	if code == "" {
+		return "", fmt.Errorf("no code in the authorization response")
+	}
+	return code, nil
+}
+
+// redirectCode returns the authorization code of a redirect URL pasted by
+// the user.
+func redirectCode(pasted, state string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(pasted))
+	if err != nil || u.RawQuery == "" {
+		return "", fmt.Errorf("invalid redirect URL: paste the full address, starting with http://127.0.0.1")
+	}
+	return callbackCode(u.Query(), state)
+}
+
+// randomState returns an unguessable state parameter.
+func randomState() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate OAuth state: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// openBrowser tries to open a URL in the default browser.
+func openBrowser(url string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "linux":
+		cmd = exec.Command("xdg-open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	}
+	if cmd != nil {
+		_ = cmd.Start()
+	}
+}
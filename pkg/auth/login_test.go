@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestCallbackCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+		wantErr  string
+	}{
+		{"valid", "code=4/abc&state=s1", "4/abc", ""},
+		{"denied", "error=access_denied&state=s1", "", "authorization denied"},
+		{"wrong state", "code=4/abc&state=other", "", "invalid state"},
+		{"missing state", "code=4/abc", "", "invalid state"},
+		{"missing code", "state=s1", "", "no code"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			code, err := callbackCode(query, "s1")
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("callbackCode() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil || code != tc.expected {
+				t.Errorf("callbackCode() = %q, %v, want %q", code, err, tc.expected)
+			}
+		})
+	}
+}
+
+func TestRedirectCode(t *testing.T) {
+	tests := []struct {
+		pasted   string
+		expected string
+		wantErr  bool
+	}{
+		{"http://127.0.0.1:53123/oauth2callback?state=s1&code=4%2Fabc&scope=contacts\n", "4/abc", false},
+		{"  http://127.0.0.1:53123/oauth2callback?code=xyz&state=s1  ", "xyz", false},
+		{"4/abc", "", true},
+		{"http://127.0.0.1:53123/oauth2callback?code=xyz&state=forged", "", true},
+	}
+
+	for _, tc := range tests {
+		code, err := redirectCode(tc.pasted, "s1")
+		if (err != nil) != tc.wantErr {
+			t.Errorf("redirectCode(%q) error = %v, wantErr %v", tc.pasted, err, tc.wantErr)
+			continue
+		}
+		if code != tc.expected {
+			t.Errorf("redirectCode(%q) = %q, want %q", tc.pasted, code, tc.expected)
+		}
+	}
+}
+
+func TestRandomState(t *testing.T) {
+	a, err := randomState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := randomState()
+	if a == b || len(a) < 40 {
+		t.Errorf("randomState() = %q, %q, want distinct unguessable values", a, b)
+	}
+}
+
+func TestLogin_LoopbackWithPKCE(t *testing.T) {
+	// Token endpoint checking the PKCE verifier and the redirect URI
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		if r.Form.Get("code") != "4/abc" || r.Form.Get("code_verifier") == "" ||
+			!strings.HasPrefix(r.Form.Get("redirect_uri"), "http://127.0.0.1:") {
+			t.Errorf("unexpected token request: %v", r.Form)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"at","refresh_token":"rt","token_type":"Bearer","expires_in":3600}`)
+	}))
+	defer tokenServer.Close()
+
+	config := &oauth2.Config{
+		ClientID: "client",
+		Endpoint: oauth2.Endpoint{AuthURL: "https://accounts.example.com/auth", TokenURL: tokenServer.URL},
+	}
+
+	// Play the browser: follow the printed consent URL back to the redirect URI
+	outR, outW := io.Pipe()
+	inR, inW := io.Pipe()
+	defer inW.Close()
+	go func() {
+		scanner := bufio.NewScanner(outR)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if !strings.HasPrefix(line, "https://accounts.example.com/auth") {
+				continue
+			}
+			authURL, _ := url.Parse(line)
+			q := authURL.Query()
+			if q.Get("code_challenge_method") != "S256" || q.Get("state") == "" {
+				t.Errorf("consent URL without PKCE or state: %s", line)
+			}
+			resp, err := http.Get(q.Get("redirect_uri") + "?code=4/abc&state=" + url.QueryEscape(q.Get("state")))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+		}
+	}()
+
+	token, err := Login(context.Background(), config, LoginOptions{Mode: LoginNoBrowser, In: inR, Out: outW})
+	outW.Close()
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if token.RefreshToken != "rt" {
+		t.Errorf("Login() token = %+v", token)
+	}
+}
@@ -0,0 +1,215 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/oauth2"
+	people "google.golang.org/api/people/v1"
+)
+
+// Scope sets, from the narrowest to the widest.
+const (
+	// ScopeSetContactsReadonly only reads contacts.
+	ScopeSetContactsReadonly = "contacts-readonly"
+	// ScopeSetContacts reads and writes contacts.
+	ScopeSetContacts = "contacts"
+	// ScopeSetUnified adds the Gmail scopes of email-manager, so that both
+	// applications can share one token.
+	ScopeSetUnified = "unified"
+)
+
+// DefaultScopeSet keeps the token shared with email-manager usable by both
+// applications.
+const DefaultScopeSet = ScopeSetUnified
+
+// ScopeSets lists the scope sets from the narrowest to the widest.
+var ScopeSets = []string{ScopeSetContactsReadonly, ScopeSetContacts, ScopeSetUnified}
+
+// scopeSets maps each scope set to its OAuth scopes.
+var scopeSets = map[string][]string{
+	ScopeSetContactsReadonly: {people.ContactsReadonlyScope, people.ContactsOtherReadonlyScope},
+	ScopeSetContacts:         {people.ContactsScope, people.ContactsOtherReadonlyScope},
+	ScopeSetUnified:          Scopes,
+}
+
+// ScopesFor returns the OAuth scopes of a scope set.
+func ScopesFor(set string) ([]string, error) {
+	scopes, ok := scopeSets[set]
+	if !ok {
+		return nil, fmt.Errorf("invalid scope set '%s', valid values: %s", set, strings.Join(ScopeSets, ", "))
+	}
+	return slices.Clone(scopes), nil
+}
+
+// scopeSet is the scope set requested by the CLI logins.
+var scopeSet = DefaultScopeSet
+
+// SetScopeSet sets the scope set requested by the CLI logins.
+func SetScopeSet(set string) error {
+	if _, err := ScopesFor(set); err != nil {
+		return err
+	}
+	scopeSet = set
+	return nil
+}
+
+// CurrentScopeSet returns the scope set requested by the CLI logins.
+func CurrentScopeSet() string {
+	return scopeSet
+}
+
+// writeScopeSet returns the scope set to request when a call needs more
+// than the token was granted: contacts-readonly cannot write, so it is
+// widened to contacts; the other sets are requested again, the token having
+// been granted less than them (e.g. before the configuration changed).
+func writeScopeSet(set string) string {
+	if set == ScopeSetContactsReadonly {
+		return ScopeSetContacts
+	}
+	return set
+}
+
+// ErrInsufficientScope is returned when the token was not granted a scope
+// that an API call needs.
+var ErrInsufficientScope = errors.New("insufficient OAuth scope")
+
+// ScopeError describes a call rejected for insufficient scope. Profile is
+// empty for tokens that do not come from a local profile (MCP server).
+type ScopeError struct {
+	Profile  string
+	ScopeSet string // Scope set to authorize, empty if unknown
+}
+
+func (e *ScopeError) Error() string {
+	if e.Profile == "" {
+		return fmt.Sprintf("%s: the access token does not allow this operation, authorize again with write access", ErrInsufficientScope)
+	}
+	return fmt.Sprintf("%s: the token of profile '%s' does not allow this operation, run 'google-contacts auth login --profile %s --scopes %s'",
+		ErrInsufficientScope, e.Profile, e.Profile, e.ScopeSet)
+}
+
+func (e *ScopeError) Unwrap() error {
+	return ErrInsufficientScope
+}
+
+// insufficientScopeMarkers identify insufficient scope responses of Google
+// APIs when the WWW-Authenticate header is missing.
+var insufficientScopeMarkers = []string{"ACCESS_TOKEN_SCOPE_INSUFFICIENT", "insufficientPermissions"}
+
+// IsInsufficientScope reports whether a response rejects the token for
+// insufficient scope (RFC 6750 section 3.1). The body is left readable.
+func IsInsufficientScope(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden {
+		return false
+	}
+	if strings.Contains(resp.Header.Get("WWW-Authenticate"), "insufficient_scope") {
+		return true
+	}
+	if resp.Body == nil {
+		return false
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	for _, marker := range insufficientScopeMarkers {
+		if bytes.Contains(body, []byte(marker)) {
+			return true
+		}
+	}
+	return false
+}
+
+// scopeTransport turns insufficient scope responses into a ScopeError. When
+// reauthorize is set, it is called once to get a transport with a wider
+// token (incremental authorization) and the request is sent again.
+type scopeTransport struct {
+	base        http.RoundTripper
+	profile     string
+	scopeSet    string
+	reauthorize func(req *http.Request) (http.RoundTripper, error)
+
+	mu         sync.Mutex
+	reauthDone bool
+}
+
+func (t *scopeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	base := t.base
+	t.mu.Unlock()
+
+	resp, err := base.RoundTrip(req)
+	if err != nil || !IsInsufficientScope(resp) {
+		return resp, err
+	}
+	resp.Body.Close()
+	scopeErr := &ScopeError{Profile: t.profile, ScopeSet: t.scopeSet}
+
+	// A request whose body cannot be replayed is not retried
+	if t.reauthorize == nil || (req.Body != nil && req.GetBody == nil) {
+		return nil, scopeErr
+	}
+
+	// Authorize once; concurrent requests reuse the wider token
+	t.mu.Lock()
+	if t.base == base && !t.reauthDone {
+		t.reauthDone = true
+		wider, err := t.reauthorize(req)
+		if err != nil {
+			t.mu.Unlock()
+			return nil, fmt.Errorf("%w (incremental authorization failed: %v)", scopeErr, err)
+		}
+		t.base = wider
+	}
+	reauthorized := t.base != base
+	base = t.base
+	t.mu.Unlock()
+	if !reauthorized {
+		return nil, scopeErr
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	resp, err = base.RoundTrip(retry)
+	if err != nil || !IsInsufficientScope(resp) {
+		return resp, err
+	}
+	resp.Body.Close()
+	return nil, scopeErr
+}
+
+// incrementalAuthorization returns the reauthorize function of a CLI
+// profile: it asks for the scopes of set on top of those already granted,
+// saves the new token and returns a transport using it.
+func incrementalAuthorization(ctx context.Context, config *oauth2.Config, name, set string) func(*http.Request) (http.RoundTripper, error) {
+	return func(req *http.Request) (http.RoundTripper, error) {
+		scopes, err := ScopesFor(set)
+		if err != nil {
+			return nil, err
+		}
+		cfg := *config
+		cfg.Scopes = scopes
+
+		fmt.Fprintf(os.Stderr, "This operation needs more access than profile '%s' was granted: requesting the '%s' scope set.\n", name, set)
+		token, err := Login(ctx, &cfg, LoginOptions{Mode: LoginBrowser, In: os.Stdin, Out: os.Stderr})
+		if err != nil {
+			return nil, err
+		}
+		if err := SaveLoginToken(name, token); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: unable to save token: %v\n", err)
+		}
+		return oauth2.NewClient(ctx, newStoreTokenSource(ctx, &cfg, name, token)).Transport, nil
+	}
+}
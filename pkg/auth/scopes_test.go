@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestScopesFor(t *testing.T) {
+	for _, set := range ScopeSets {
+		scopes, err := ScopesFor(set)
+		if err != nil || len(scopes) == 0 {
+			t.Errorf("ScopesFor(%q) = %v, %v", set, scopes, err)
+		}
+		for _, scope := range scopes {
+			if set != ScopeSetUnified && strings.Contains(scope, "gmail") {
+				t.Errorf("ScopesFor(%q) requests %s", set, scope)
+			}
+		}
+	}
+	if _, err := ScopesFor("gmail"); err == nil {
+		t.Error("ScopesFor(gmail) should fail")
+	}
+	if err := SetScopeSet("all"); err == nil || CurrentScopeSet() != DefaultScopeSet {
+		t.Errorf("SetScopeSet(all) = %v, current %q", err, CurrentScopeSet())
+	}
+}
+
+func TestIsInsufficientScope(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		header string
+		body   string
+		want   bool
+	}{
+		{"header", http.StatusForbidden, `Bearer realm="https://accounts.google.com/", error="insufficient_scope"`, "", true},
+		{"status detail", http.StatusForbidden, "", `{"error":{"code":403,"status":"PERMISSION_DENIED","details":[{"reason":"ACCESS_TOKEN_SCOPE_INSUFFICIENT"}]}}`, true},
+		{"legacy reason", http.StatusForbidden, "", `{"error":{"errors":[{"reason":"insufficientPermissions"}]}}`, true},
+		{"other forbidden", http.StatusForbidden, "", `{"error":{"code":403,"status":"PERMISSION_DENIED"}}`, false},
+		{"ok", http.StatusOK, "", "ACCESS_TOKEN_SCOPE_INSUFFICIENT", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tc.status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(tc.body))}
+			if tc.header != "" {
+				resp.Header.Set("WWW-Authenticate", tc.header)
+			}
+			if got := IsInsufficientScope(resp); got != tc.want {
+				t.Errorf("IsInsufficientScope() = %v, want %v", got, tc.want)
+			}
+			// The body stays readable for the caller
+			if body, _ := io.ReadAll(resp.Body); string(body) != tc.body {
+				t.Errorf("body = %q, want %q", body, tc.body)
+			}
+		})
+	}
+}
+
+// tokenTransport authenticates requests with a fixed token.
+type tokenTransport string
+
+func (t tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+string(t))
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestScopeTransport(t *testing.T) {
+	// Only the "wide" token may write
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.Header.Get("Authorization") != "Bearer wide" {
+			w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope"`)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "ok %s", body)
+	}))
+	defer server.Close()
+
+	post := func(client *http.Client) (string, error) {
+		resp, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body), nil
+	}
+
+	t.Run("no reauthorization", func(t *testing.T) {
+		client := &http.Client{Transport: &scopeTransport{base: tokenTransport("narrow"), profile: "work", scopeSet: ScopeSetContacts}}
+		if resp, err := client.Get(server.URL); err != nil {
+			t.Fatalf("GET error = %v", err)
+		} else {
+			resp.Body.Close()
+		}
+		_, err := post(client)
+		var scopeErr *ScopeError
+		if !errors.Is(err, ErrInsufficientScope) || !errors.As(err, &scopeErr) || scopeErr.Profile != "work" {
+			t.Fatalf("POST error = %v, want ScopeError", err)
+		}
+		if !strings.Contains(err.Error(), "auth login --profile work --scopes contacts") {
+			t.Errorf("error = %v, want the login command", err)
+		}
+	})
+
+	t.Run("incremental authorization", func(t *testing.T) {
+		calls := 0
+		client := &http.Client{Transport: &scopeTransport{
+			base: tokenTransport("narrow"),
+			reauthorize: func(*http.Request) (http.RoundTripper, error) {
+				calls++
+				return tokenTransport("wide"), nil
+			},
+		}}
+		for range 2 {
+			body, err := post(client)
+			if err != nil || body != "ok payload" {
+				t.Fatalf("POST = %q, %v, want the replayed request", body, err)
+			}
+		}
+		if calls != 1 {
+			t.Errorf("reauthorize called %d times, want 1", calls)
+		}
+	})
+
+	t.Run("reauthorization refused", func(t *testing.T) {
+		client := &http.Client{Transport: &scopeTransport{
+			base: tokenTransport("narrow"),
+			reauthorize: func(*http.Request) (http.RoundTripper, error) {
+				return nil, errors.New("access_denied")
+			},
+		}}
+		if _, err := post(client); !errors.Is(err, ErrInsufficientScope) || !strings.Contains(err.Error(), "access_denied") {
+			t.Errorf("POST error = %v", err)
+		}
+		// Not asked again
+		if _, err := post(client); !errors.Is(err, ErrInsufficientScope) || strings.Contains(err.Error(), "access_denied") {
+			t.Errorf("second POST error = %v", err)
+		}
+	})
+}
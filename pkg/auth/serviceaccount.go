@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+
+	"golang.org/x/oauth2/google"
+)
+
+// ServiceAccount holds service-account credentials used with domain-wide
+// delegation: the service account acts as a Workspace user (the subject),
+// without interactive consent. The scopes of its scope set must be granted
+// to the service account client ID in the Workspace admin console.
+type ServiceAccount struct {
+	Email    string // client_email of the key
+	ClientID string // client_id, authorized in the admin console
+	// ScopeSet is the scope set requested for the subject. Empty uses the
+	// current scope set; the unified set is narrowed to contacts since
+	// google-contacts never needs Gmail access for another user.
+	ScopeSet string
+
+	keyJSON []byte
+}
+
+// LoadServiceAccount reads a service-account JSON key file.
+func LoadServiceAccount(path string) (*ServiceAccount, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read service account file: %w", err)
+	}
+	return ParseServiceAccount(data)
+}
+
+// ParseServiceAccount parses a service-account JSON key.
+func ParseServiceAccount(data []byte) (*ServiceAccount, error) {
+	var key struct {
+		Type        string `json:"type"`
+		ClientEmail string `json:"client_email"`
+		ClientID    string `json:"client_id"`
+	}
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("invalid service account file: %w", err)
+	}
+	if key.Type != "service_account" {
+		return nil, fmt.Errorf("invalid service account file: type is %q, want \"service_account\" (OAuth client files go in %s)", key.Type, CredentialsFile)
+	}
+	return &ServiceAccount{Email: key.ClientEmail, ClientID: key.ClientID, keyJSON: data}, nil
+}
+
+// Scopes returns the OAuth scopes requested for the subject.
+func (sa *ServiceAccount) Scopes() ([]string, error) {
+	set := sa.ScopeSet
+	if set == "" {
+		set = scopeSet
+	}
+	if set == ScopeSetUnified {
+		set = ScopeSetContacts
+	}
+	return ScopesFor(set)
+}
+
+// Client returns an HTTP client acting as subject.
+func (sa *ServiceAccount) Client(ctx context.Context, subject string) (*http.Client, error) {
+	if err := ValidateSubject(subject); err != nil {
+		return nil, err
+	}
+	scopes, err := sa.Scopes()
+	if err != nil {
+		return nil, err
+	}
+	config, err := google.JWTConfigFromJSON(sa.keyJSON, scopes...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse service account file: %w", err)
+	}
+	config.Subject = subject
+	return withScopeCheck(config.Client(ctx)), nil
+}
+
+// subjectPattern accepts email addresses usable as directory names.
+var subjectPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._%+-]*@[A-Za-z0-9.-]+\.[A-Za-z]+$`)
+
+// ValidateSubject checks the email address of an impersonated user.
+func ValidateSubject(subject string) error {
+	if subject == "" {
+		return fmt.Errorf("a service account acts as a Workspace user: set the user with --impersonate user@domain")
+	}
+	if !subjectPattern.MatchString(subject) {
+		return fmt.Errorf("invalid user to impersonate '%s': expected an email address", subject)
+	}
+	return nil
+}
+
+// Service account used by the CLI, with the impersonated user
+var (
+	serviceAccount *ServiceAccount
+	subject        string
+)
+
+// SetImpersonation makes GetClient act as subject with a service account
+// (nil restores the user tokens). A subject without service account is
+// rejected.
+func SetImpersonation(sa *ServiceAccount, user string) error {
+	if user != "" && sa == nil {
+		return fmt.Errorf("--impersonate needs service account credentials (serviceAccount)")
+	}
+	if sa != nil {
+		if err := ValidateSubject(user); err != nil {
+			return err
+		}
+	}
+	serviceAccount, subject = sa, user
+	return nil
+}
+
+// CurrentServiceAccount returns the service account set by SetImpersonation,
+// nil when the profile token is used.
+func CurrentServiceAccount() *ServiceAccount {
+	return serviceAccount
+}
+
+// CurrentSubject returns the user impersonated by the CLI, empty when the
+// profile token is used.
+func CurrentSubject() string {
+	return subject
+}
+
+// serviceAccountContext is the context value of WithServiceAccount.
+type serviceAccountContext struct {
+	sa      *ServiceAccount
+	subject string
+}
+
+// WithServiceAccount returns a new context acting as subject with a service
+// account. This is used by the MCP server to serve each caller with its own
+// address book.
+func WithServiceAccount(ctx context.Context, sa *ServiceAccount, subject string) context.Context {
+	return context.WithValue(ctx, serviceAccountKey, serviceAccountContext{sa, subject})
+}
+
+// GetServiceAccountFromContext retrieves the service account and subject
+// from context, if present.
+func GetServiceAccountFromContext(ctx context.Context) (*ServiceAccount, string, bool) {
+	value, ok := ctx.Value(serviceAccountKey).(serviceAccountContext)
+	return value.sa, value.subject, ok && value.sa != nil
+}
@@ -0,0 +1,474 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/oauth2"
+)
+
+// TokenStore keeps the OAuth token of each profile.
+type TokenStore interface {
+	// Load returns the token of a profile, or an error wrapping
+	// ErrNotLoggedIn when there is none.
+	Load(profile string) (*oauth2.Token, error)
+	// Save stores the token of a profile, replacing the previous one.
+	Save(profile string, token *oauth2.Token) error
+	// Delete removes the token of a profile, or returns an error wrapping
+	// ErrNotLoggedIn when there is none.
+	Delete(profile string) error
+	// Exists reports whether the profile has a token.
+	Exists(profile string) bool
+	// Location describes where the token of a profile is kept.
+	Location(profile string) string
+}
+
+// Token store kinds, selected by the tokenStore setting.
+const (
+	StoreFile      = "file"
+	StoreEncrypted = "encrypted"
+	StoreEnv       = "env"
+)
+
+// TokenStores lists the token store kinds.
+var TokenStores = []string{StoreFile, StoreEncrypted, StoreEnv}
+
+// ErrReadOnlyStore is returned when saving or deleting a token in a store
+// that cannot be written (environment variables).
+var ErrReadOnlyStore = errors.New("token store is read-only")
+
+// lockingStore is implemented by the stores kept in token files. Token
+// refreshes hold the lock of the file across reading, refreshing and saving
+// the token, so that concurrent processes refresh it only once.
+type lockingStore interface {
+	TokenStore
+	// lock takes the exclusive lock of the token file of a profile.
+	lock(profile string) (unlock func(), err error)
+	// saveLocked is Save for a caller holding the lock.
+	saveLocked(profile string, token *oauth2.Token) error
+}
+
+// saveWithLock saves a token under the lock of its file.
+func saveWithLock(s lockingStore, profile string, token *oauth2.Token) error {
+	unlock, err := s.lock(profile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return s.saveLocked(profile, token)
+}
+
+// tokenStore is the store of the CLI tokens.
+var tokenStore TokenStore = FileStore{}
+
+// SetTokenStore sets the store of the CLI tokens.
+func SetTokenStore(store TokenStore) {
+	tokenStore = store
+}
+
+// CurrentTokenStore returns the store of the CLI tokens.
+func CurrentTokenStore() TokenStore {
+	return tokenStore
+}
+
+// TokenLocation describes where the token of a profile is kept.
+func TokenLocation(name string) string {
+	return tokenStore.Location(name)
+}
+
+// HasToken reports whether a profile has a token.
+func HasToken(name string) bool {
+	return tokenStore.Exists(name)
+}
+
+// FileStore keeps tokens as plaintext JSON files with 0600 permissions
+// (TokenPath), the format shared with email-manager.
+type FileStore struct{}
+
+func (FileStore) Load(profile string) (*oauth2.Token, error) {
+	data, err := readTokenFile(profile, TokenPath(profile))
+	if err != nil {
+		return nil, err
+	}
+	token := &oauth2.Token{}
+	if err := json.Unmarshal(data, token); err != nil {
+		return nil, fmt.Errorf("unable to read token: %w", err)
+	}
+	return token, nil
+}
+
+func (s FileStore) Save(profile string, token *oauth2.Token) error {
+	return saveWithLock(s, profile, token)
+}
+
+func (FileStore) lock(profile string) (func(), error) {
+	return lockTokenFile(TokenPath(profile))
+}
+
+func (FileStore) saveLocked(profile string, token *oauth2.Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return writeTokenFile(TokenPath(profile), append(data, '\n'))
+}
+
+func (FileStore) Delete(profile string) error {
+	return removeTokenFile(profile, TokenPath(profile))
+}
+
+func (FileStore) Exists(profile string) bool {
+	_, err := os.Stat(TokenPath(profile))
+	return err == nil
+}
+
+func (FileStore) Location(profile string) string {
+	return TokenPath(profile)
+}
+
+// EncryptedTokenFile is the name of the encrypted token file, next to the
+// plaintext one.
+const EncryptedTokenFile = "google_token.enc"
+
+// EncryptedTokenPath returns the encrypted token file of a profile.
+func EncryptedTokenPath(name string) string {
+	return filepath.Join(ProfileDir(name), EncryptedTokenFile)
+}
+
+// Encryption parameters: AES-256-GCM with a key read from a key file or
+// derived from a passphrase with scrypt (N=2^15, r=8, p=1) and a random salt
+// per file.
+const (
+	tokenKeySize    = 32
+	scryptN         = 1 << 15
+	scryptR         = 8
+	scryptP         = 1
+	kdfScrypt       = "scrypt"
+	kdfKeyFile      = "keyfile"
+	envelopeVersion = 1
+)
+
+// tokenAAD binds the ciphertext to its use.
+var tokenAAD = []byte("google-contacts token v1")
+
+// encryptedToken is the content of an encrypted token file.
+type encryptedToken struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Salt       []byte `json:"salt,omitempty"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// EncryptedStore keeps tokens encrypted with AES-256-GCM in
+// EncryptedTokenPath.
+type EncryptedStore struct {
+	keyFile    string                 // Key file, empty with a passphrase
+	passphrase func() ([]byte, error) // Passphrase source
+
+	// The key file is read, or the passphrase asked, once on first use
+	once      sync.Once
+	secret    []byte
+	secretErr error
+}
+
+// NewPassphraseStore returns an encrypted store whose keys are derived from
+// a passphrase. The passphrase function is called once, when a token is
+// first loaded or saved.
+func NewPassphraseStore(passphrase func() ([]byte, error)) *EncryptedStore {
+	return &EncryptedStore{passphrase: passphrase}
+}
+
+// NewKeyFileStore returns an encrypted store using the key of a key file
+// (32 random bytes, base64 encoded, see GenerateKeyFile). The file is read
+// when a token is first loaded or saved.
+func NewKeyFileStore(path string) *EncryptedStore {
+	return &EncryptedStore{keyFile: path}
+}
+
+// KeyFile returns the key file of the store, empty with a passphrase.
+func (s *EncryptedStore) KeyFile() string {
+	return s.keyFile
+}
+
+// GenerateKeyFile writes a new random key file with 0600 permissions. An
+// existing file is not replaced.
+func GenerateKeyFile(path string) error {
+	key := make([]byte, tokenKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("failed to generate token key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create token key file: %w", err)
+	}
+	defer f.Close()
+	_, err = fmt.Fprintln(f, base64.StdEncoding.EncodeToString(key))
+	return err
+}
+
+func (s *EncryptedStore) Load(profile string) (*oauth2.Token, error) {
+	path := EncryptedTokenPath(profile)
+	data, err := readTokenFile(profile, path)
+	if err != nil {
+		return nil, err
+	}
+	var envelope encryptedToken
+	if err := json.Unmarshal(data, &envelope); err != nil || envelope.Version != envelopeVersion {
+		return nil, fmt.Errorf("unable to read token: %s is not an encrypted token file", path)
+	}
+	key, err := s.fileKey(envelope.KDF, envelope.Salt)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, envelope.Nonce, envelope.Ciphertext, tokenAAD)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt token %s: wrong key or passphrase, or corrupted file", path)
+	}
+	token := &oauth2.Token{}
+	if err := json.Unmarshal(plaintext, token); err != nil {
+		return nil, fmt.Errorf("unable to read token: %w", err)
+	}
+	return token, nil
+}
+
+func (s *EncryptedStore) Save(profile string, token *oauth2.Token) error {
+	return saveWithLock(s, profile, token)
+}
+
+func (s *EncryptedStore) lock(profile string) (func(), error) {
+	return lockTokenFile(EncryptedTokenPath(profile))
+}
+
+func (s *EncryptedStore) saveLocked(profile string, token *oauth2.Token) error {
+	plaintext, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	envelope := encryptedToken{Version: envelopeVersion, KDF: kdfKeyFile}
+	if s.keyFile == "" {
+		envelope.KDF = kdfScrypt
+		envelope.Salt = make([]byte, 16)
+		if _, err := rand.Read(envelope.Salt); err != nil {
+			return fmt.Errorf("failed to generate salt: %w", err)
+		}
+	}
+	key, err := s.fileKey(envelope.KDF, envelope.Salt)
+	if err != nil {
+		return err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	envelope.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(envelope.Nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	envelope.Ciphertext = aead.Seal(nil, envelope.Nonce, plaintext, tokenAAD)
+
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+	return writeTokenFile(EncryptedTokenPath(profile), append(data, '\n'))
+}
+
+func (s *EncryptedStore) Delete(profile string) error {
+	return removeTokenFile(profile, EncryptedTokenPath(profile))
+}
+
+func (s *EncryptedStore) Exists(profile string) bool {
+	_, err := os.Stat(EncryptedTokenPath(profile))
+	return err == nil
+}
+
+func (s *EncryptedStore) Location(profile string) string {
+	return EncryptedTokenPath(profile)
+}
+
+// fileKey returns the encryption key of a file.
+func (s *EncryptedStore) fileKey(kdf string, salt []byte) ([]byte, error) {
+	switch kdf {
+	case kdfKeyFile:
+		if s.keyFile == "" {
+			return nil, fmt.Errorf("token encrypted with a key file: set tokenKeyFile")
+		}
+		return s.loadSecret()
+	case kdfScrypt:
+		if s.keyFile != "" {
+			return nil, fmt.Errorf("token encrypted with a passphrase: unset tokenKeyFile")
+		}
+		passphrase, err := s.loadSecret()
+		if err != nil {
+			return nil, err
+		}
+		key, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, tokenKeySize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive token key: %w", err)
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown token encryption %q", kdf)
+}
+
+// loadSecret returns the key of the key file, or the passphrase.
+func (s *EncryptedStore) loadSecret() ([]byte, error) {
+	s.once.Do(func() {
+		if s.keyFile != "" {
+			s.secret, s.secretErr = ReadKeyFile(s.keyFile)
+			return
+		}
+		if s.passphrase == nil {
+			s.secretErr = fmt.Errorf("no token passphrase")
+			return
+		}
+		s.secret, s.secretErr = s.passphrase()
+		if s.secretErr == nil && len(s.secret) == 0 {
+			s.secretErr = fmt.Errorf("empty token passphrase")
+		}
+	})
+	return s.secret, s.secretErr
+}
+
+// ReadKeyFile reads the key of a key file written by GenerateKeyFile.
+func ReadKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read token key file: %w", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != tokenKeySize {
+		return nil, fmt.Errorf("invalid token key file %s: expected %d base64-encoded bytes", path, tokenKeySize)
+	}
+	return key, nil
+}
+
+// newAEAD returns the AES-256-GCM cipher of a key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EnvStore reads tokens from environment variables (TokenEnv), for
+// containers and CI where no file is kept. The value is a token as JSON or
+// a bare refresh token. It cannot be written: log in elsewhere and export
+// the token.
+type EnvStore struct {
+	lookup func(string) (string, bool)
+}
+
+// NewEnvStore returns a store reading the process environment.
+func NewEnvStore() *EnvStore {
+	return &EnvStore{lookup: os.LookupEnv}
+}
+
+// envNameReplacer turns profile names into environment variable suffixes.
+var envNameReplacer = regexp.MustCompile(`[^A-Z0-9]`)
+
+// TokenEnv returns the environment variable holding the token of a profile:
+// GOOGLE_CONTACTS_TOKEN for the default profile, GOOGLE_CONTACTS_TOKEN_<NAME>
+// otherwise.
+func TokenEnv(name string) string {
+	if name == DefaultProfile {
+		return "GOOGLE_CONTACTS_TOKEN"
+	}
+	return "GOOGLE_CONTACTS_TOKEN_" + envNameReplacer.ReplaceAllString(strings.ToUpper(name), "_")
+}
+
+func (s *EnvStore) Load(profile string) (*oauth2.Token, error) {
+	value, ok := s.lookup(TokenEnv(profile))
+	value = strings.TrimSpace(value)
+	if !ok || value == "" {
+		return nil, fmt.Errorf("profile %s: %w (set %s)", profile, ErrNotLoggedIn, TokenEnv(profile))
+	}
+	if !strings.HasPrefix(value, "{") {
+		return &oauth2.Token{RefreshToken: value}, nil
+	}
+	token := &oauth2.Token{}
+	if err := json.Unmarshal([]byte(value), token); err != nil {
+		return nil, fmt.Errorf("unable to read token from %s: %w", TokenEnv(profile), err)
+	}
+	return token, nil
+}
+
+func (s *EnvStore) Save(profile string, token *oauth2.Token) error {
+	return fmt.Errorf("%w: export the token in %s instead", ErrReadOnlyStore, TokenEnv(profile))
+}
+
+func (s *EnvStore) Delete(profile string) error {
+	return fmt.Errorf("%w: unset %s instead", ErrReadOnlyStore, TokenEnv(profile))
+}
+
+func (s *EnvStore) Exists(profile string) bool {
+	value, ok := s.lookup(TokenEnv(profile))
+	return ok && strings.TrimSpace(value) != ""
+}
+
+func (s *EnvStore) Location(profile string) string {
+	return "$" + TokenEnv(profile)
+}
+
+// readTokenFile reads a token file, reporting a missing one as ErrNotLoggedIn.
+func readTokenFile(profile, path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("profile %s: %w", profile, ErrNotLoggedIn)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read token: %w", err)
+	}
+	return data, nil
+}
+
+// writeTokenFile replaces a token file (0600), atomically so that concurrent
+// readers never see a partial token. The caller holds the lock of the file.
+func writeTokenFile(path string, data []byte) error {
+	if err := replaceFile(path, data); err != nil {
+		return fmt.Errorf("unable to save token: %w", err)
+	}
+	return nil
+}
+
+// removeTokenFile deletes a token file under its lock, reporting a missing
+// one as ErrNotLoggedIn.
+func removeTokenFile(profile, path string) error {
+	// Locking would create the directory of an unknown profile
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("profile %s: %w", profile, ErrNotLoggedIn)
+	}
+	unlock, err := lockTokenFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("profile %s: %w", profile, ErrNotLoggedIn)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to delete token: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// testToken is the token saved by the store tests.
+var testToken = &oauth2.Token{AccessToken: "access", RefreshToken: "refresh-secret", TokenType: "Bearer", Expiry: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+// checkStore saves, loads and deletes a token in a store.
+func checkStore(t *testing.T, store TokenStore, profile string) {
+	t.Helper()
+	if _, err := store.Load(profile); !errors.Is(err, ErrNotLoggedIn) {
+		t.Fatalf("Load() before Save error = %v, want ErrNotLoggedIn", err)
+	}
+	if err := store.Save(profile, testToken); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if !store.Exists(profile) {
+		t.Error("Exists() = false after Save")
+	}
+	got, err := store.Load(profile)
+	if err != nil || got.RefreshToken != testToken.RefreshToken || !got.Expiry.Equal(testToken.Expiry) {
+		t.Fatalf("Load() = %+v, %v", got, err)
+	}
+	if err := store.Delete(profile); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if err := store.Delete(profile); !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("second Delete() error = %v, want ErrNotLoggedIn", err)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	SetCredentialsPath(t.TempDir())
+	defer SetCredentialsPath("")
+
+	checkStore(t, FileStore{}, "work")
+	if err := (FileStore{}).Save(DefaultProfile, testToken); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(TokenPath(DefaultProfile))
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("token file = %v, %v, want mode 0600", info, err)
+	}
+}
+
+func TestEncryptedStore_Passphrase(t *testing.T) {
+	SetCredentialsPath(t.TempDir())
+	defer SetCredentialsPath("")
+
+	asked := 0
+	store := NewPassphraseStore(func() ([]byte, error) {
+		asked++
+		return []byte("correct horse"), nil
+	})
+	checkStore(t, store, "work")
+	if asked != 1 {
+		t.Errorf("passphrase asked %d times, want 1", asked)
+	}
+
+	if err := store.Save(DefaultProfile, testToken); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(EncryptedTokenPath(DefaultProfile))
+	if err != nil || strings.Contains(string(data), testToken.RefreshToken) {
+		t.Errorf("encrypted file = %s, %v, want no plaintext token", data, err)
+	}
+
+	wrong := NewPassphraseStore(func() ([]byte, error) { return []byte("wrong"), nil })
+	if _, err := wrong.Load(DefaultProfile); err == nil || !strings.Contains(err.Error(), "unable to decrypt") {
+		t.Errorf("Load() with a wrong passphrase error = %v", err)
+	}
+	if _, err := NewKeyFileStore(filepath.Join(t.TempDir(), "key")).Load(DefaultProfile); err == nil || !strings.Contains(err.Error(), "passphrase") {
+		t.Errorf("Load() with a key file error = %v, want passphrase required", err)
+	}
+}
+
+func TestEncryptedStore_KeyFile(t *testing.T) {
+	dir := t.TempDir()
+	SetCredentialsPath(dir)
+	defer SetCredentialsPath("")
+
+	keyFile := filepath.Join(dir, "token.key")
+	if _, err := NewKeyFileStore(keyFile).Load(DefaultProfile); !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("Load() without token error = %v, want ErrNotLoggedIn", err)
+	}
+	if err := NewKeyFileStore(keyFile).Save(DefaultProfile, testToken); err == nil {
+		t.Error("Save() without key file should fail")
+	}
+
+	if err := GenerateKeyFile(keyFile); err != nil {
+		t.Fatalf("GenerateKeyFile() error = %v", err)
+	}
+	if err := GenerateKeyFile(keyFile); err == nil {
+		t.Error("GenerateKeyFile() should not replace an existing key")
+	}
+	checkStore(t, NewKeyFileStore(keyFile), "work")
+
+	other := filepath.Join(dir, "other.key")
+	if err := GenerateKeyFile(other); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewKeyFileStore(keyFile).Save(DefaultProfile, testToken); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewKeyFileStore(other).Load(DefaultProfile); err == nil {
+		t.Error("Load() with another key should fail")
+	}
+}
+
+func TestEnvStore(t *testing.T) {
+	env := map[string]string{
+		"GOOGLE_CONTACTS_TOKEN":             `{"access_token":"at","refresh_token":"rt"}`,
+		"GOOGLE_CONTACTS_TOKEN_WORK_2":      "bare-refresh-token",
+		"GOOGLE_CONTACTS_TOKEN_PERSONAL":    "{not json",
+		"GOOGLE_CONTACTS_TOKEN_UNSET_BLANK": " ",
+	}
+	store := &EnvStore{lookup: func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}}
+
+	tests := []struct {
+		profile string
+		want    string // Refresh token
+		wantErr string
+	}{
+		{DefaultProfile, "rt", ""},
+		{"work-2", "bare-refresh-token", ""},
+		{"personal", "", "unable to read token from GOOGLE_CONTACTS_TOKEN_PERSONAL"},
+		{"unset.blank", "", "not logged in"},
+		{"other", "", "not logged in (set GOOGLE_CONTACTS_TOKEN_OTHER)"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.profile, func(t *testing.T) {
+			token, err := store.Load(tc.profile)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("Load() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil || token.RefreshToken != tc.want {
+				t.Errorf("Load() = %+v, %v, want refresh token %q", token, err, tc.want)
+			}
+		})
+	}
+
+	if err := store.Save(DefaultProfile, testToken); !errors.Is(err, ErrReadOnlyStore) {
+		t.Errorf("Save() error = %v, want ErrReadOnlyStore", err)
+	}
+	if got := store.Location("work-2"); got != "$GOOGLE_CONTACTS_TOKEN_WORK_2" {
+		t.Errorf("Location() = %q", got)
+	}
+}
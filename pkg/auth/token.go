@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// Google OAuth endpoints used to inspect and revoke tokens.
+const (
+	GoogleTokenInfoURL = "https://oauth2.googleapis.com/tokeninfo"
+	GoogleRevokeURL    = "https://oauth2.googleapis.com/revoke"
+)
+
+// ErrNotLoggedIn is returned when a profile has no token file.
+var ErrNotLoggedIn = errors.New("not logged in")
+
+// LoadToken reads the token of a profile from the token store.
+func LoadToken(name string) (*oauth2.Token, error) {
+	return tokenStore.Load(name)
+}
+
+// SaveToken stores the token of a profile in the token store.
+func SaveToken(name string, token *oauth2.Token) error {
+	return tokenStore.Save(name, token)
+}
+
+// SaveLoginToken stores the token obtained by a login, telling where.
+func SaveLoginToken(name string, token *oauth2.Token) error {
+	fmt.Fprintf(os.Stderr, "Saving credentials to: %s\n", TokenLocation(name))
+	return SaveToken(name, token)
+}
+
+// Logout deletes the token of a profile from the token store. The grant
+// stays valid at Google (see RevokeToken).
+func Logout(name string) error {
+	return tokenStore.Delete(name)
+}
+
+// storeTokenSource refreshes the token of a profile and saves the refreshed
+// tokens, so that later invocations reuse them instead of refreshing again.
+// Before refreshing, the stored token is read again: another process (or
+// email-manager) may already have refreshed it, or logged in again. With a
+// file store, the lock of the token file is held from that read to the save.
+type storeTokenSource struct {
+	ctx     context.Context
+	config  *oauth2.Config
+	profile string
+
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+// newStoreTokenSource returns a token source starting from token.
+func newStoreTokenSource(ctx context.Context, config *oauth2.Config, profile string, token *oauth2.Token) *storeTokenSource {
+	return &storeTokenSource{ctx: ctx, config: config, profile: profile, token: token}
+}
+
+func (s *storeTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token.Valid() {
+		return s.token, nil
+	}
+
+	store := tokenStore
+	save := store.Save
+	if locking, ok := store.(lockingStore); ok {
+		// Without the lock, the refresh still works but may race
+		if unlock, err := locking.lock(s.profile); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else {
+			defer unlock()
+			save = locking.saveLocked
+		}
+	}
+
+	if stored, err := store.Load(s.profile); err == nil && stored.RefreshToken != "" {
+		s.token = stored
+		if stored.Valid() {
+			return stored, nil
+		}
+	}
+
+	token, err := s.config.TokenSource(s.ctx, s.token).Token()
+	if err != nil {
+		return nil, err
+	}
+	if token.AccessToken != s.token.AccessToken {
+		if err := save(s.profile, token); err != nil && !errors.Is(err, ErrReadOnlyStore) {
+			fmt.Fprintf(os.Stderr, "Warning: unable to save refreshed token: %v\n", err)
+		}
+	}
+	s.token = token
+	return token, nil
+}
+
+// TokenInfo describes an access token as reported by the token info endpoint.
+type TokenInfo struct {
+	Scopes        []string
+	Expiry        time.Time
+	Email         string // Only with the email scope
+	EmailVerified bool
+	Audience      string // OAuth client the token was issued to
+}
+
+// FetchTokenInfo asks the token info endpoint (GoogleTokenInfoURL) about an
+// access token.
+func FetchTokenInfo(ctx context.Context, client *http.Client, endpoint, accessToken string) (*TokenInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+url.Values{"access_token": {accessToken}}.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token info: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token info: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get token info: %s", responseError(resp))
+	}
+
+	var body struct {
+		Scope         string `json:"scope"`
+		ExpiresIn     string `json:"expires_in"`
+		Email         string `json:"email"`
+		EmailVerified string `json:"email_verified"`
+		Audience      string `json:"aud"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode token info: %w", err)
+	}
+
+	info := &TokenInfo{Scopes: strings.Fields(body.Scope), Email: body.Email, EmailVerified: body.EmailVerified == "true", Audience: body.Audience}
+	if seconds, err := strconv.Atoi(body.ExpiresIn); err == nil {
+		info.Expiry = time.Now().Add(time.Duration(seconds) * time.Second)
+	}
+	return info, nil
+}
+
+// RevokeToken revokes a grant at the revocation endpoint (GoogleRevokeURL).
+// The refresh token is revoked when present, which also invalidates the
+// access tokens issued from it.
+func RevokeToken(ctx context.Context, client *http.Client, endpoint string, token *oauth2.Token) error {
+	value := token.RefreshToken
+	if value == "" {
+		value = token.AccessToken
+	}
+	if value == "" {
+		return fmt.Errorf("no token to revoke")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(url.Values{"token": {value}}.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to revoke token: %s", responseError(resp))
+	}
+	return nil
+}
+
+// responseError returns the OAuth error of a response, or its status.
+func responseError(resp *http.Response) string {
+	var body struct {
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if json.Unmarshal(data, &body) == nil && body.Error != "" {
+		if body.Description != "" {
+			return body.Error + ": " + body.Description
+		}
+		return body.Error
+	}
+	return resp.Status
+}
@@ -0,0 +1,223 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestRevokeToken(t *testing.T) {
+	var revoked []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		token := r.PostFormValue("token")
+		if token == "expired" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"invalid_token","error_description":"Token expired or revoked"}`)
+			return
+		}
+		revoked = append(revoked, token)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		token   *oauth2.Token
+		wantErr string
+	}{
+		{"refresh token preferred", &oauth2.Token{AccessToken: "at", RefreshToken: "rt"}, ""},
+		{"access token only", &oauth2.Token{AccessToken: "at"}, ""},
+		{"rejected", &oauth2.Token{RefreshToken: "expired"}, "invalid_token: Token expired or revoked"},
+		{"empty", &oauth2.Token{}, "no token to revoke"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := RevokeToken(context.Background(), server.Client(), server.URL, tc.token)
+			if tc.wantErr == "" && err != nil {
+				t.Errorf("RevokeToken() error = %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("RevokeToken() error = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+
+	if want := []string{"rt", "at"}; !slices.Equal(revoked, want) {
+		t.Errorf("revoked = %v, want %v", revoked, want)
+	}
+}
+
+func TestFetchTokenInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("access_token") != "at" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"invalid_token"}`)
+			return
+		}
+		fmt.Fprint(w, `{"scope":"https://www.googleapis.com/auth/contacts openid","expires_in":"3599","aud":"client-id","email":"jane@example.com","email_verified":"true"}`)
+	}))
+	defer server.Close()
+
+	info, err := FetchTokenInfo(context.Background(), server.Client(), server.URL, "at")
+	if err != nil {
+		t.Fatalf("FetchTokenInfo() error = %v", err)
+	}
+	if want := []string{"https://www.googleapis.com/auth/contacts", "openid"}; !slices.Equal(info.Scopes, want) {
+		t.Errorf("Scopes = %v, want %v", info.Scopes, want)
+	}
+	if left := time.Until(info.Expiry); left < 59*time.Minute || left > time.Hour {
+		t.Errorf("Expiry in %v, want about 1h", left)
+	}
+	if info.Email != "jane@example.com" || !info.EmailVerified || info.Audience != "client-id" {
+		t.Errorf("identity = %q verified %v audience %q", info.Email, info.EmailVerified, info.Audience)
+	}
+
+	if _, err := FetchTokenInfo(context.Background(), server.Client(), server.URL, "bad"); err == nil || !strings.Contains(err.Error(), "invalid_token") {
+		t.Errorf("FetchTokenInfo(bad) error = %v, want invalid_token", err)
+	}
+}
+
+func TestLoadTokenAndLogout(t *testing.T) {
+	SetCredentialsPath(t.TempDir())
+	defer SetCredentialsPath("")
+
+	if _, err := LoadToken("work"); !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("LoadToken() before login error = %v, want ErrNotLoggedIn", err)
+	}
+
+	if err := SaveToken("work", &oauth2.Token{AccessToken: "at", RefreshToken: "rt"}); err != nil {
+		t.Fatal(err)
+	}
+	token, err := LoadToken("work")
+	if err != nil || token.RefreshToken != "rt" {
+		t.Fatalf("LoadToken() = %+v, %v", token, err)
+	}
+	if profiles, _ := ListProfiles(); !slices.Equal(profiles, []string{DefaultProfile, "work"}) {
+		t.Errorf("ListProfiles() = %v", profiles)
+	}
+
+	if err := Logout("work"); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if err := Logout("work"); !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("second Logout() error = %v, want ErrNotLoggedIn", err)
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	for _, name := range []string{"work", "perso-2", "a.b_c"} {
+		if err := ValidateProfile(name); err != nil {
+			t.Errorf("ValidateProfile(%q) error = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "../x", "a/b", ".hidden", "with space"} {
+		if err := ValidateProfile(name); err == nil {
+			t.Errorf("ValidateProfile(%q) should fail", name)
+		}
+	}
+}
+
+func TestProfileFromContext(t *testing.T) {
+	if got := ProfileFromContext(context.Background()); got != CurrentProfile() {
+		t.Errorf("ProfileFromContext() = %q, want current profile", got)
+	}
+	if got := ProfileFromContext(WithProfile(context.Background(), "work")); got != "work" {
+		t.Errorf("ProfileFromContext() = %q, want work", got)
+	}
+}
+
+func TestStoreTokenSource(t *testing.T) {
+	SetCredentialsPath(t.TempDir())
+	defer SetCredentialsPath("")
+
+	refreshes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshes++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"fresh-%d","token_type":"Bearer","expires_in":3600}`, refreshes)
+	}))
+	defer server.Close()
+	config := &oauth2.Config{ClientID: "id", Endpoint: oauth2.Endpoint{TokenURL: server.URL}}
+
+	expired := &oauth2.Token{AccessToken: "old", RefreshToken: "rt", Expiry: time.Now().Add(-time.Hour)}
+	if err := SaveToken(DefaultProfile, expired); err != nil {
+		t.Fatal(err)
+	}
+
+	// The refreshed token is saved, keeping the refresh token
+	source := newStoreTokenSource(context.Background(), config, DefaultProfile, expired)
+	for range 2 {
+		token, err := source.Token()
+		if err != nil || token.AccessToken != "fresh-1" {
+			t.Fatalf("Token() = %+v, %v", token, err)
+		}
+	}
+	stored, err := LoadToken(DefaultProfile)
+	if err != nil || stored.AccessToken != "fresh-1" || stored.RefreshToken != "rt" {
+		t.Fatalf("stored token = %+v, %v", stored, err)
+	}
+
+	// Another invocation holding the expired token reuses the saved one
+	token, err := newStoreTokenSource(context.Background(), config, DefaultProfile, expired).Token()
+	if err != nil || token.AccessToken != "fresh-1" {
+		t.Errorf("Token() = %+v, %v", token, err)
+	}
+	if refreshes != 1 {
+		t.Errorf("refreshes = %d, want 1", refreshes)
+	}
+}
+
+func TestStoreTokenSource_HoldsLock(t *testing.T) {
+	SetCredentialsPath(t.TempDir())
+	defer SetCredentialsPath("")
+
+	// The token file is locked while the token is refreshed
+	var lockErr error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, err := os.OpenFile(TokenPath(DefaultProfile)+lockSuffix, os.O_RDWR, 0600)
+		if err != nil {
+			lockErr = err
+		} else {
+			lockErr = tryLock(f)
+			f.Close()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"fresh","token_type":"Bearer","expires_in":3600}`)
+	}))
+	defer server.Close()
+	config := &oauth2.Config{ClientID: "id", Endpoint: oauth2.Endpoint{TokenURL: server.URL}}
+
+	expired := &oauth2.Token{AccessToken: "old", RefreshToken: "rt", Expiry: time.Now().Add(-time.Hour)}
+	if err := SaveToken(DefaultProfile, expired); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newStoreTokenSource(context.Background(), config, DefaultProfile, expired).Token(); err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(lockErr, errLocked) {
+		t.Errorf("lock during refresh = %v, want errLocked", lockErr)
+	}
+
+	// And released once the refreshed token is saved
+	unlock, err := lockTokenFile(TokenPath(DefaultProfile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+	if stored, err := LoadToken(DefaultProfile); err != nil || stored.AccessToken != "fresh" {
+		t.Errorf("stored token = %+v, %v", stored, err)
+	}
+}